@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/defi-bot/backend/internal/config"
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/pkg/web3"
+)
+
+var (
+	configPath = flag.String("config", "configs/config.yaml", "配置文件路径")
+	dryRun     = flag.Bool("dry-run", false, "只列出需要修正的交易对，不写入数据库")
+)
+
+func main() {
+	flag.Parse()
+
+	fmt.Println("========================================")
+	fmt.Println("🔧 交易对代币顺序修正工具")
+	fmt.Println("========================================")
+
+	// 1. 加载配置
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("❌ 加载配置失败: %v", err)
+	}
+
+	// 2. 初始化数据库
+	if err := database.InitDB(&cfg.Database); err != nil {
+		log.Fatalf("❌ 数据库初始化失败: %v", err)
+	}
+	defer database.CloseDB()
+	db := database.GetDB()
+
+	// 3. 初始化 Web3 客户端
+	client, err := web3.NewClientWithOptions(
+		cfg.Blockchain.RPCURL,
+		cfg.Blockchain.ChainID,
+		web3.ClientOptions{
+			DialTimeout: cfg.Blockchain.GetDialTimeout(),
+			CallTimeout: cfg.Blockchain.GetCallTimeout(),
+			MaxRPS:      cfg.Blockchain.GetMaxRPS(cfg.Blockchain.RPCURL),
+		},
+	)
+	if err != nil {
+		log.Fatalf("❌ Web3 客户端初始化失败: %v", err)
+	}
+	defer client.Close()
+
+	// 4. 检查所有交易对
+	var pairs []models.TradingPair
+	if err := db.Preload("Token0").Preload("Token1").Preload("Dex").Find(&pairs).Error; err != nil {
+		log.Fatalf("❌ 查询交易对失败: %v", err)
+	}
+
+	fixed := 0
+	failed := 0
+	for i := range pairs {
+		pair := &pairs[i]
+
+		reversed, err := isReversed(client, pair)
+		if err != nil {
+			log.Printf("⚠️  %s/%s @ %s: %v", pair.Token0.Symbol, pair.Token1.Symbol, pair.Dex.Name, err)
+			failed++
+			continue
+		}
+		if !reversed {
+			continue
+		}
+
+		log.Printf("🔄 %s/%s @ %s (%s): 链上顺序为 %s/%s",
+			pair.Token0.Symbol, pair.Token1.Symbol, pair.Dex.Name, pair.PairAddress,
+			pair.Token1.Symbol, pair.Token0.Symbol)
+		if *dryRun {
+			fixed++
+			continue
+		}
+
+		err = db.Model(&models.TradingPair{}).Where("id = ?", pair.ID).Updates(map[string]interface{}{
+			"token0_id": pair.Token1ID,
+			"token1_id": pair.Token0ID,
+		}).Error
+		if err != nil {
+			log.Printf("❌ 修正交易对 %d 失败: %v", pair.ID, err)
+			failed++
+			continue
+		}
+		fixed++
+	}
+
+	if *dryRun {
+		log.Printf("✅ 检查完成（dry-run）: %d 个交易对需要修正, %d 个检查失败", fixed, failed)
+		return
+	}
+	log.Printf("✅ 修正完成: %d 个交易对已修正, %d 个失败", fixed, failed)
+	if fixed > 0 {
+		log.Println("提示：修正前写入的价格 / 储备量历史仍按旧顺序记录，分析时请以修正后的数据为准")
+	}
+}
+
+// isReversed 判断记录的代币顺序是否与链上相反
+// 有池子合约时读取 token0()，V4 池子按地址排序判断
+func isReversed(client *web3.Client, pair *models.TradingPair) (bool, error) {
+	if pair.PoolVersion == "v4" {
+		return pair.TokensReversed(), nil
+	}
+
+	chainToken0, err := client.GetTokenFromPair(pair.PairAddress, 0)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case strings.EqualFold(chainToken0, pair.Token0.Address):
+		return false, nil
+	case strings.EqualFold(chainToken0, pair.Token1.Address):
+		return true, nil
+	default:
+		return false, fmt.Errorf("链上 token0 %s 与记录的代币不匹配", chainToken0)
+	}
+}
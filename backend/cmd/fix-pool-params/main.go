@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/defi-bot/backend/internal/config"
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/pkg/dex"
+	"github.com/defi-bot/backend/pkg/web3"
+)
+
+var (
+	configPath = flag.String("config", "configs/config.yaml", "配置文件路径")
+	dryRun     = flag.Bool("dry-run", false, "只列出需要补写的交易对，不写入数据库")
+)
+
+func main() {
+	flag.Parse()
+
+	fmt.Println("========================================")
+	fmt.Println("🔧 V3 池子参数补写工具（tickSpacing / fee / pool_version）")
+	fmt.Println("========================================")
+
+	// 1. 加载配置
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("❌ 加载配置失败: %v", err)
+	}
+
+	// 2. 初始化数据库
+	if err := database.InitDB(&cfg.Database); err != nil {
+		log.Fatalf("❌ 数据库初始化失败: %v", err)
+	}
+	defer database.CloseDB()
+	db := database.GetDB()
+
+	// 3. 初始化 Web3 客户端
+	client, err := web3.NewClientWithOptions(
+		cfg.Blockchain.RPCURL,
+		cfg.Blockchain.ChainID,
+		web3.ClientOptions{
+			DialTimeout: cfg.Blockchain.GetDialTimeout(),
+			CallTimeout: cfg.Blockchain.GetCallTimeout(),
+			MaxRPS:      cfg.Blockchain.GetMaxRPS(cfg.Blockchain.RPCURL),
+		},
+	)
+	if err != nil {
+		log.Fatalf("❌ Web3 客户端初始化失败: %v", err)
+	}
+	defer client.Close()
+
+	// 4. 检查所有 V3 协议 DEX 上的交易对
+	var pairs []models.TradingPair
+	if err := db.Preload("Token0").Preload("Token1").Preload("Dex").Find(&pairs).Error; err != nil {
+		log.Fatalf("❌ 查询交易对失败: %v", err)
+	}
+
+	factory := dex.NewProtocolFactory(client)
+
+	fixed := 0
+	failed := 0
+	for i := range pairs {
+		pair := &pairs[i]
+		if factory.GetProtocolType(pair.Dex.Protocol) != "v3" {
+			continue
+		}
+
+		updates, err := poolParamUpdates(client, pair)
+		if err != nil {
+			log.Printf("⚠️  %s/%s @ %s (%s): %v", pair.Token0.Symbol, pair.Token1.Symbol, pair.Dex.Name, pair.PairAddress, err)
+			failed++
+			continue
+		}
+		if len(updates) == 0 {
+			continue
+		}
+
+		log.Printf("🔄 %s/%s @ %s (%s): %v",
+			pair.Token0.Symbol, pair.Token1.Symbol, pair.Dex.Name, pair.PairAddress, updates)
+		if *dryRun {
+			fixed++
+			continue
+		}
+
+		if err := db.Model(&models.TradingPair{}).Where("id = ?", pair.ID).Updates(updates).Error; err != nil {
+			log.Printf("❌ 更新交易对 %d 失败: %v", pair.ID, err)
+			failed++
+			continue
+		}
+		fixed++
+	}
+
+	if *dryRun {
+		log.Printf("✅ 检查完成（dry-run）: %d 个交易对需要补写, %d 个检查失败", fixed, failed)
+		return
+	}
+	log.Printf("✅ 补写完成: %d 个交易对已更新, %d 个失败", fixed, failed)
+}
+
+// poolParamUpdates 读取池子的 tickSpacing 和 fee，返回与记录不一致的字段
+// Kyber Elastic 没有标准的 fee()/tickSpacing()，只读取 tickDistance
+func poolParamUpdates(client *web3.Client, pair *models.TradingPair) (map[string]interface{}, error) {
+	updates := make(map[string]interface{})
+	if pair.PoolVersion != "v3" {
+		updates["pool_version"] = "v3"
+	}
+
+	if pair.Dex.Protocol == "kyber_elastic" {
+		tickDistance, err := client.GetKyberElasticTickDistance(pair.PairAddress)
+		if err != nil {
+			return nil, fmt.Errorf("读取 tickDistance 失败: %w", err)
+		}
+		if pair.TickSpacing != tickDistance {
+			updates["tick_spacing"] = tickDistance
+		}
+		return updates, nil
+	}
+
+	state, err := client.GetV3PoolState(pair.PairAddress)
+	if err != nil {
+		return nil, fmt.Errorf("读取池子状态失败: %w", err)
+	}
+	if pair.TickSpacing != state.TickSpacing {
+		updates["tick_spacing"] = state.TickSpacing
+	}
+	if pair.Fee != state.Fee {
+		updates["fee"] = state.Fee
+	}
+	return updates, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/defi-bot/backend/internal/config"
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/repository"
+)
+
+var configPath = flag.String("config", "configs/config.yaml", "配置文件路径")
+
+func main() {
+	flag.Parse()
+
+	fmt.Println("========================================")
+	fmt.Println("🔄 交易对最新状态重建工具")
+	fmt.Println("========================================")
+
+	// 1. 加载配置
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("❌ 加载配置失败: %v", err)
+	}
+
+	// 2. 初始化数据库
+	if err := database.InitDB(&cfg.Database); err != nil {
+		log.Fatalf("❌ 数据库初始化失败: %v", err)
+	}
+	defer database.CloseDB()
+
+	// 3. 执行数据库迁移（InitDB 不迁移，新库上还没有 pair_latest 表）
+	if err := database.AutoMigrate(); err != nil {
+		log.Fatalf("❌ 数据库迁移失败: %v", err)
+	}
+
+	// 4. 从价格历史重建
+	startTime := time.Now()
+	count, err := repository.NewPairLatestRepository(database.GetDB()).Rebuild()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	log.Printf("✅ 重建完成: %d 个交易对, 耗时 %v", count, time.Since(startTime))
+}
@@ -1,18 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/defi-bot/backend/internal/api"
 	"github.com/defi-bot/backend/internal/collector"
 	"github.com/defi-bot/backend/internal/config"
 	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/notify"
+	"github.com/defi-bot/backend/internal/repository"
 	"github.com/defi-bot/backend/internal/scheduler"
+	"github.com/defi-bot/backend/internal/trading"
 	"github.com/defi-bot/backend/pkg/cache"
+	"github.com/defi-bot/backend/pkg/dex"
+	"github.com/defi-bot/backend/pkg/honeypot"
 	"github.com/defi-bot/backend/pkg/web3"
 )
 
@@ -20,6 +29,7 @@ var (
 	configPath = flag.String("config", "configs/config.yaml", "配置文件路径")
 	migrate    = flag.Bool("migrate", false, "执行数据库迁移")
 	seed       = flag.Bool("seed", false, "初始化种子数据")
+	validate   = flag.Bool("validate", false, "只校验配置文件（不连接数据库和 RPC）后退出")
 )
 
 func main() {
@@ -35,6 +45,10 @@ func main() {
 		log.Fatalf("加载配置失败: %v", err)
 	}
 
+	if *validate {
+		os.Exit(validateConfig(cfg))
+	}
+
 	// 2. 初始化数据库
 	log.Println("初始化数据库...")
 	if err := database.InitDB(&cfg.Database); err != nil {
@@ -58,7 +72,7 @@ func main() {
 	// 4. 初始化种子数据
 	if *seed {
 		log.Println("初始化种子数据...")
-		if err := database.SeedData(cfg); err != nil {
+		if err := database.SeedData(context.Background(), cfg); err != nil {
 			log.Fatalf("种子数据初始化失败: %v", err)
 		}
 		return
@@ -66,16 +80,47 @@ func main() {
 
 	// 5. 初始化 Web3 客户端
 	log.Println("初始化 Web3 客户端...")
-	web3Client, err := web3.NewClient(
+	web3Client, err := web3.NewClientWithOptions(
 		cfg.Blockchain.RPCURL,
 		cfg.Blockchain.ChainID,
-		cfg.Blockchain.Timeout,
+		web3.ClientOptions{
+			DialTimeout: cfg.Blockchain.GetDialTimeout(),
+			CallTimeout: cfg.Blockchain.GetCallTimeout(),
+			MaxRPS:      cfg.Blockchain.GetMaxRPS(cfg.Blockchain.RPCURL),
+
+			KeepaliveInterval:      time.Duration(cfg.Blockchain.KeepaliveInterval) * time.Second,
+			ReconnectAfterFailures: cfg.Blockchain.ReconnectAfterFailures,
+			QuoteCacheTTL:          time.Duration(cfg.Blockchain.QuoteCacheTTL) * time.Second,
+		},
 	)
 	if err != nil {
 		log.Fatalf("Web3 客户端初始化失败: %v", err)
 	}
 	defer web3Client.Close()
 
+	if len(cfg.Blockchain.ArchiveRPCURLs) > 0 {
+		log.Println("初始化归档 RPC 节点池...")
+		archivePool, err := web3.NewClientPool(&web3.ClientPoolConfig{
+			RPCURLs:     cfg.Blockchain.ArchiveRPCURLs,
+			ChainID:     cfg.Blockchain.ChainID,
+			Timeout:     cfg.Blockchain.Timeout,
+			DialTimeout: cfg.Blockchain.GetDialTimeout(),
+			CallTimeout: cfg.Blockchain.GetCallTimeout(),
+			MaxRPS:      cfg.Blockchain.MaxRPS,
+			EndpointRPS: cfg.Blockchain.EndpointRPS(),
+
+			HealthCheck:   cfg.Blockchain.KeepaliveInterval > 0,
+			CheckInterval: time.Duration(cfg.Blockchain.KeepaliveInterval) * time.Second,
+			MaxBlockLag:   cfg.Blockchain.MaxBlockLag,
+		})
+		if err != nil {
+			log.Printf("⚠️  归档 RPC 节点池初始化失败（重查询将使用主节点）: %v", err)
+		} else {
+			defer archivePool.Close()
+			web3Client.SetArchivePool(archivePool)
+		}
+	}
+
 	// 6. 初始化 Redis 缓存（可选）
 	var redisCache *cache.RedisCache
 	if cfg.Redis.Enabled {
@@ -87,6 +132,9 @@ func main() {
 			Password: cfg.Redis.Password,
 			DB:       cfg.Redis.DB,
 			TTL:      time.Duration(cfg.Redis.TTL) * time.Second,
+
+			FailureThreshold: cfg.Redis.BreakerThreshold,
+			Cooldown:         time.Duration(cfg.Redis.BreakerCooldown) * time.Second,
 		})
 		if err != nil {
 			log.Printf("⚠️  Redis 初始化失败（将不使用缓存）: %v", err)
@@ -99,23 +147,106 @@ func main() {
 	// 7. 创建数据采集器
 	log.Println("创建数据采集器...")
 	dataCollector := collector.NewCollector(web3Client, redisCache)
+	dataCollector.SetProtocolPolicy(dex.ProtocolPolicy{
+		Allowed: cfg.Protocols.Allowed,
+		Denied:  cfg.Protocols.Denied,
+	})
+	successSampleRate := cfg.Log.SuccessSampleRate
+	if cfg.Log.Level == "debug" {
+		successSampleRate = 1 // debug 级别输出全部明细
+	}
+	dataCollector.SetSuccessLogSampleRate(successSampleRate)
+	dataCollector.SetVolumeOptions(collector.VolumeOptions{
+		Source:        cfg.Volume.Source,
+		Window:        time.Duration(cfg.Volume.WindowHours) * time.Hour,
+		BlockTime:     time.Duration(cfg.Volume.BlockTime) * time.Second,
+		MaxBlockRange: cfg.Volume.MaxBlockRange,
+		SubgraphURL:   cfg.Volume.SubgraphURL,
+	})
+	dataCollector.SetDepthFeeTiers(cfg.Scheduler.DepthFeeTiers)
+	dataCollector.SetDepthConcurrency(cfg.Scheduler.DepthConcurrency)
+	dataCollector.SetOneInchOracle(cfg.Contracts.OneInchOracle)
+	dataCollector.SetPinSnapshotBlock(cfg.Scheduler.PinSnapshotBlock)
+	dataCollector.SetSupplyOptions(collector.SupplyOptions{
+		CoingeckoAPIURL: cfg.Supply.CoingeckoAPIURL,
+		CoingeckoAPIKey: cfg.Supply.CoingeckoAPIKey,
+		Timeout:         time.Duration(cfg.Supply.Timeout) * time.Second,
+	})
+	if cfg.Honeypot.Enabled {
+		dataCollector.SetHoneypotChecker(honeypot.NewAPIChecker(
+			cfg.Honeypot.APIURL,
+			cfg.Blockchain.ChainID,
+			time.Duration(cfg.Honeypot.Timeout)*time.Second,
+		))
+		log.Println("已启用蜜罐代币检测")
+	}
+
+	// 校验 Router / Quoter 与 Factory 属于同一部署（只输出日志，不阻止启动）
+	dataCollector.LogDexContractChecks()
 
 	// 8. 创建定时任务调度器
 	log.Println("创建定时任务调度器...")
 	taskScheduler := scheduler.NewScheduler(dataCollector, &cfg.Scheduler)
 
+	// 交易开关（从数据库恢复上次的暂停/恢复状态）
+	tradingControl, err := trading.NewControl(repository.NewSettingRepository(database.GetDB()))
+	if err != nil {
+		log.Fatalf("加载交易开关失败: %v", err)
+	}
+	taskScheduler.SetTradingControl(tradingControl)
+	taskScheduler.SetNativeSymbol(cfg.Risk.NativeSymbol)
+	database.GetExecutionRepository().SetNativeSymbol(cfg.Risk.NativeSymbol)
+	lossGuard, err := trading.NewLossGuard(tradingControl, trading.LossGuardConfig{
+		MaxConsecutiveLosses: cfg.Risk.MaxConsecutiveLosses,
+		MaxDrawdownUSD:       cfg.Risk.MaxDrawdownUSD,
+		Window:               time.Duration(cfg.Risk.DrawdownWindow) * time.Hour,
+		NativeSymbol:         cfg.Risk.NativeSymbol,
+		AlertWebhook:         cfg.Risk.AlertWebhook,
+	})
+	if err != nil {
+		log.Fatalf("创建亏损熔断器失败: %v", err)
+	}
+	taskScheduler.SetLossGuard(lossGuard)
+
+	// 执行结果 Webhook 推送
+	var dispatcher *notify.WebhookDispatcher
+	if len(cfg.Notify.Webhooks) > 0 {
+		dispatcher = notify.NewWebhookDispatcher(notify.WebhookConfig{
+			URLs:       cfg.Notify.Webhooks,
+			Secret:     cfg.Notify.Secret,
+			QueueSize:  cfg.Notify.QueueSize,
+			MaxRetries: cfg.Notify.MaxRetries,
+			Timeout:    time.Duration(cfg.Notify.Timeout) * time.Second,
+		})
+		repository.SetExecutionNotifier(dispatcher)
+		if cfg.Notify.Opportunities {
+			repository.SetOpportunityNotifier(dispatcher)
+		}
+		log.Printf("已启用执行结果推送: %d 个 Webhook", len(cfg.Notify.Webhooks))
+	}
+
 	// 9. 启动调度器
 	if err := taskScheduler.Start(); err != nil {
 		log.Fatalf("启动调度器失败: %v", err)
 	}
 
-	// 10. 立即执行一次数据采集
+	// 10. 启动 HTTP API 服务
+	var apiServer *api.Server
+	if cfg.Server.Port > 0 {
+		apiServer = api.NewServer(&cfg.Server)
+		apiServer.SetTradingControl(tradingControl)
+		apiServer.SetWeb3Client(web3Client)
+		apiServer.SetCollector(dataCollector)
+		apiServer.Start()
+	}
+
+	// 11. 立即执行一次数据采集
 	log.Println("执行初始数据采集...")
-	if err := dataCollector.CollectAllData(); err != nil {
+	if err := dataCollector.CollectAllData(taskScheduler.Context()); err != nil {
 		log.Printf("初始数据采集失败: %v", err)
 	}
 
-	// 11. 等待退出信号
+	// 12. 等待退出信号
 	log.Println("========================================")
 	log.Println("服务已启动，按 Ctrl+C 退出")
 	log.Println("========================================")
@@ -124,8 +255,48 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	// 12. 优雅关闭
+	// 13. 优雅关闭
 	log.Println("\n正在关闭服务...")
+	if apiServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := apiServer.Shutdown(ctx); err != nil {
+			log.Printf("关闭 HTTP API 服务失败: %v", err)
+		}
+		cancel()
+	}
 	taskScheduler.Stop()
+	if dispatcher != nil {
+		// 调度器停止后不再产生新事件，先注销通知器再等待队列发送完成
+		repository.SetExecutionNotifier(nil)
+		repository.SetOpportunityNotifier(nil)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := dispatcher.Close(ctx); err != nil {
+			log.Printf("关闭 Webhook 推送器失败: %v", err)
+		}
+		cancel()
+	}
 	log.Println("服务已关闭")
 }
+
+// validateConfig 离线校验配置并输出报告（直接写标准输出，便于在 CI / 部署脚本中查看），返回进程退出码
+func validateConfig(cfg *config.Config) int {
+	fmt.Printf("配置文件: %s\n", *configPath)
+
+	err := cfg.Validate()
+	if err == nil {
+		fmt.Printf("✅ 配置校验通过: %d 个 DEX, %d 个代币\n", len(cfg.Dexes), len(cfg.Tokens))
+		return 0
+	}
+
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		fmt.Printf("❌ 配置校验失败: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("❌ 配置校验失败: %d 个问题\n", len(validationErr.Issues))
+	for _, issue := range validationErr.Issues {
+		fmt.Printf("   - %s\n", issue)
+	}
+	return 1
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/defi-bot/backend/internal/config"
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/pkg/amm"
+	"github.com/defi-bot/backend/pkg/units"
+)
+
+var (
+	configPath = flag.String("config", "configs/config.yaml", "配置文件路径")
+	fromFlag   = flag.String("from", "", "开始时间（RFC3339，默认为 -hours 小时前）")
+	toFlag     = flag.String("to", "", "结束时间（RFC3339，默认为当前时间）")
+	hours      = flag.Int("hours", 24, "未指定 -from 时回放最近多少小时的数据")
+	ratesFlag  = flag.String("rates", "0.1,0.3,0.5,1,2", "用于敏感性分析的最小利润率列表（百分比，逗号分隔）")
+)
+
+// poolSnapshot 某个区块下单个 V2 池子的状态
+type poolSnapshot struct {
+	pair     *models.TradingPair
+	token0   *models.Token // 按地址排序后的 token0（与 reserve0 对应，可能与 pair.Token0 相反）
+	token1   *models.Token
+	reserve0 float64
+	reserve1 float64
+	feeRate  float64 // 扣除手续费后的系数，如 0.997
+
+	rawReserve0 *big.Int // 链上储备量（最小单位），用于按合约整数公式复核输出
+	rawReserve1 *big.Int
+	feeBps      uint32
+}
+
+// opportunity 模拟发现的套利机会
+type opportunity struct {
+	blockNumber uint64
+	pairKey     string
+	buyDex      string  // 在此 DEX 用 token0 买入 token1
+	sellDex     string  // 在此 DEX 卖出 token1 换回 token0
+	amountIn    float64 // 最优输入（token0，已按精度换算）
+	profit      float64 // 理论利润（token0，已按精度换算，未扣除 Gas）
+	profitRate  float64 // 利润率（百分比）
+}
+
+func main() {
+	flag.Parse()
+
+	fmt.Println("========================================")
+	fmt.Println("🧪 策略回放模拟工具")
+	fmt.Println("========================================")
+
+	// 1. 加载配置
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("❌ 加载配置失败: %v", err)
+	}
+
+	from, to, err := parseTimeRange()
+	if err != nil {
+		log.Fatalf("❌ 时间范围无效: %v", err)
+	}
+
+	rates, err := parseRates(*ratesFlag)
+	if err != nil {
+		log.Fatalf("❌ 利润率列表无效: %v", err)
+	}
+
+	// 2. 初始化数据库（只读，不访问链上）
+	if err := database.InitDB(&cfg.Database); err != nil {
+		log.Fatalf("❌ 数据库初始化失败: %v", err)
+	}
+	defer database.CloseDB()
+	db := database.GetDB()
+	db.Logger = db.Logger.LogMode(1) // Silent mode
+
+	// 3. 加载 V2 交易对
+	var pairs []models.TradingPair
+	err = db.Preload("Dex").Preload("Token0").Preload("Token1").
+		Where("pool_version = ?", "v2").
+		Find(&pairs).Error
+	if err != nil {
+		log.Fatalf("❌ 查询交易对失败: %v", err)
+	}
+
+	pairByID := make(map[uint]*models.TradingPair, len(pairs))
+	for i := range pairs {
+		pairByID[pairs[i].ID] = &pairs[i]
+	}
+
+	// 4. 加载历史储备量
+	var reserves []models.PairReserve
+	err = db.Where("timestamp >= ? AND timestamp < ?", from, to).
+		Order("block_number ASC").
+		Find(&reserves).Error
+	if err != nil {
+		log.Fatalf("❌ 查询储备量历史失败: %v", err)
+	}
+
+	log.Printf("回放区间: %s ~ %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	log.Printf("V2 交易对: %d 个, 储备量快照: %d 条", len(pairs), len(reserves))
+
+	if len(reserves) == 0 {
+		log.Println("⚠️  区间内没有储备量数据")
+		return
+	}
+
+	// 5. 逐区块重建池子状态并寻找机会
+	opportunities := make([]opportunity, 0)
+	blocks := 0
+
+	for start := 0; start < len(reserves); {
+		blockNumber := reserves[start].BlockNumber
+		end := start
+		for end < len(reserves) && reserves[end].BlockNumber == blockNumber {
+			end++
+		}
+
+		snapshots := buildSnapshots(reserves[start:end], pairByID)
+		opportunities = append(opportunities, findOpportunities(blockNumber, snapshots)...)
+
+		blocks++
+		start = end
+	}
+
+	// 6. 输出报告
+	printReport(blocks, opportunities, cfg.Arbitrage.MinProfitRate, rates)
+}
+
+// parseTimeRange 解析回放时间范围
+func parseTimeRange() (time.Time, time.Time, error) {
+	to := time.Now()
+	if *toFlag != "" {
+		parsed, err := time.Parse(time.RFC3339, *toFlag)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("解析 -to 失败: %w", err)
+		}
+		to = parsed
+	}
+
+	from := to.Add(-time.Duration(*hours) * time.Hour)
+	if *fromFlag != "" {
+		parsed, err := time.Parse(time.RFC3339, *fromFlag)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("解析 -from 失败: %w", err)
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("开始时间必须早于结束时间")
+	}
+
+	return from, to, nil
+}
+
+// parseRates 解析利润率列表
+func parseRates(s string) ([]float64, error) {
+	rates := make([]float64, 0)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		rate, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, fmt.Errorf("解析 %q 失败: %w", part, err)
+		}
+		rates = append(rates, rate)
+	}
+	sort.Float64s(rates)
+	return rates, nil
+}
+
+// buildSnapshots 将同一区块的储备量记录还原为池子状态，按代币对分组
+// 交易对记录的代币顺序可能与链上相反（储备量按记录顺序保存），统一换成按地址排序的 (token0, token1)，
+// 分组键也用排序后的地址，保证不同 DEX 上的同一代币对归入同一组且方向一致
+func buildSnapshots(records []models.PairReserve, pairByID map[uint]*models.TradingPair) map[string][]poolSnapshot {
+	groups := make(map[string][]poolSnapshot)
+
+	for _, record := range records {
+		pair, ok := pairByID[record.PairID]
+		if !ok {
+			continue
+		}
+
+		raw0, ok0 := new(big.Int).SetString(record.Reserve0, 10)
+		raw1, ok1 := new(big.Int).SetString(record.Reserve1, 10)
+		if !ok0 || !ok1 || raw0.Sign() <= 0 || raw1.Sign() <= 0 {
+			continue
+		}
+		token0, token1 := &pair.Token0, &pair.Token1
+		if pair.TokensReversed() {
+			token0, token1 = token1, token0
+			raw0, raw1 = raw1, raw0
+		}
+
+		key := strings.ToLower(token0.Address) + "/" + strings.ToLower(token1.Address)
+		groups[key] = append(groups[key], poolSnapshot{
+			pair:     pair,
+			token0:   token0,
+			token1:   token1,
+			reserve0: units.ToFloat(raw0, token0.Decimals),
+			reserve1: units.ToFloat(raw1, token1.Decimals),
+			feeRate:  1 - float64(pair.Dex.Fee)/10000,
+
+			rawReserve0: raw0,
+			rawReserve1: raw1,
+			feeBps:      uint32(pair.Dex.Fee),
+		})
+	}
+
+	return groups
+}
+
+// findOpportunities 在同一区块内寻找跨 DEX 套利机会
+// 对每两个池子计算最优输入金额：在 A 用 token0 买入 token1，再到 B 卖回 token0
+func findOpportunities(blockNumber uint64, groups map[string][]poolSnapshot) []opportunity {
+	results := make([]opportunity, 0)
+
+	for _, pools := range groups {
+		for i := range pools {
+			for j := range pools {
+				if i == j || pools[i].pair.DexID == pools[j].pair.DexID {
+					continue
+				}
+
+				amountIn, profit := optimalTwoPoolArbitrage(pools[i], pools[j])
+				if profit <= 0 || amountIn <= 0 {
+					continue
+				}
+
+				results = append(results, opportunity{
+					blockNumber: blockNumber,
+					pairKey:     pools[i].token0.Symbol + "/" + pools[i].token1.Symbol,
+					buyDex:      pools[i].pair.Dex.Name,
+					sellDex:     pools[j].pair.Dex.Name,
+					amountIn:    amountIn,
+					profit:      profit,
+					profitRate:  profit / amountIn * 100,
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+// optimalTwoPoolArbitrage 计算两个 V2 池子之间的最优套利输入和理论利润
+// 两个恒定乘积池串联后等价于一个虚拟池 (X, Y)，最优输入为 (sqrt(f*X*Y) - X) / f
+func optimalTwoPoolArbitrage(buy, sell poolSnapshot) (float64, float64) {
+	// buy 池: token0 -> token1；sell 池: token1 -> token0
+	x1, y1, f1 := buy.reserve0, buy.reserve1, buy.feeRate
+	y2, x2, f2 := sell.reserve1, sell.reserve0, sell.feeRate
+
+	denominator := y2 + f2*y1
+	virtualIn := x1 * y2 / denominator
+	virtualOut := f2 * y1 * x2 / denominator
+
+	amountIn := (math.Sqrt(f1*virtualIn*virtualOut) - virtualIn) / f1
+	if amountIn <= 0 {
+		return 0, 0
+	}
+
+	// 按真实池子逐跳用合约的整数公式计算输出，避免虚拟池近似和浮点误差
+	decimals := buy.token0.Decimals
+	rawIn := units.FromFloat(amountIn, decimals)
+	amounts, err := amm.GetAmountsOut(rawIn,
+		[][2]*big.Int{
+			{buy.rawReserve0, buy.rawReserve1},
+			{sell.rawReserve1, sell.rawReserve0},
+		},
+		[]uint32{buy.feeBps, sell.feeBps},
+	)
+	if err != nil {
+		return 0, 0
+	}
+
+	profit := new(big.Int).Sub(amounts[len(amounts)-1], rawIn)
+	return amountIn, units.ToFloat(profit, decimals)
+}
+
+// printReport 输出模拟报告
+func printReport(blocks int, opportunities []opportunity, minProfitRate float64, rates []float64) {
+	log.Println("\n========================================")
+	log.Println("📈 模拟结果（理论利润，未扣除 Gas）")
+	log.Println("========================================")
+	log.Printf("回放区块数: %d", blocks)
+	log.Printf("正利润机会: %d 个", len(opportunities))
+
+	// 当前配置下的机会
+	selected := filterByRate(opportunities, minProfitRate)
+	log.Printf("当前配置 (min_profit_rate=%.2f%%) 下的机会: %d 个", minProfitRate, len(selected))
+
+	if len(selected) > 0 {
+		profitRates := make([]float64, len(selected))
+		for i, opp := range selected {
+			profitRates[i] = opp.profitRate
+		}
+		sort.Float64s(profitRates)
+
+		log.Println("\n利润率分布:")
+		log.Printf("  P50: %.4f%%", percentile(profitRates, 50))
+		log.Printf("  P90: %.4f%%", percentile(profitRates, 90))
+		log.Printf("  P99: %.4f%%", percentile(profitRates, 99))
+		log.Printf("  最大: %.4f%%", profitRates[len(profitRates)-1])
+
+		// 利润按计价代币汇总（不同代币不能直接相加）
+		log.Println("\n按交易对汇总:")
+		byPair := make(map[string][]opportunity)
+		pairKeys := make([]string, 0)
+		for _, opp := range selected {
+			if _, ok := byPair[opp.pairKey]; !ok {
+				pairKeys = append(pairKeys, opp.pairKey)
+			}
+			byPair[opp.pairKey] = append(byPair[opp.pairKey], opp)
+		}
+		sort.Strings(pairKeys)
+
+		for _, key := range pairKeys {
+			total := 0.0
+			best := byPair[key][0]
+			for _, opp := range byPair[key] {
+				total += opp.profit
+				if opp.profit > best.profit {
+					best = opp
+				}
+			}
+			token0 := strings.Split(key, "/")[0]
+			log.Printf("  %s: %d 个机会, 理论利润合计 %.6f %s, 最佳 %.6f %s (区块 %d, %s → %s)",
+				key, len(byPair[key]), total, token0, best.profit, token0, best.blockNumber, best.buyDex, best.sellDex)
+		}
+	}
+
+	// MinProfitRate 敏感性分析
+	log.Println("\n========================================")
+	log.Println("📊 min_profit_rate 敏感性分析")
+	log.Println("========================================")
+	for _, rate := range rates {
+		filtered := filterByRate(opportunities, rate)
+		log.Printf("  min_profit_rate=%.2f%%: %d 个机会", rate, len(filtered))
+	}
+}
+
+// filterByRate 筛选利润率不低于阈值的机会
+func filterByRate(opportunities []opportunity, minRate float64) []opportunity {
+	result := make([]opportunity, 0)
+	for _, opp := range opportunities {
+		if opp.profitRate >= minRate {
+			result = append(result, opp)
+		}
+	}
+	return result
+}
+
+// percentile 计算已排序数据的百分位数
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
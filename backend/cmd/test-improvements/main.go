@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"math/big"
@@ -9,6 +10,7 @@ import (
 	"github.com/defi-bot/backend/internal/config"
 	"github.com/defi-bot/backend/internal/database"
 	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/pkg/units"
 	"github.com/defi-bot/backend/pkg/web3"
 )
 
@@ -21,7 +23,8 @@ func main() {
 
 	fmt.Println("========================================")
 	fmt.Println("🧪 改进功能测试工具")
-	fmt.Println("========================================\n")
+	fmt.Println("========================================")
+	fmt.Println()
 
 	// 1. 加载配置
 	fmt.Println("📋 步骤 1/5: 加载配置...")
@@ -30,7 +33,8 @@ func main() {
 		fmt.Printf("❌ 失败: %v\n", err)
 		return
 	}
-	fmt.Println("✅ 成功\n")
+	fmt.Println("✅ 成功")
+	fmt.Println()
 
 	// 2. 初始化数据库
 	fmt.Println("📋 步骤 2/5: 初始化数据库...")
@@ -41,7 +45,8 @@ func main() {
 	defer database.CloseDB()
 	db := database.GetDB()
 	db.Logger = db.Logger.LogMode(1)
-	fmt.Println("✅ 成功\n")
+	fmt.Println("✅ 成功")
+	fmt.Println()
 
 	// 3. 初始化 Web3 客户端
 	fmt.Println("📋 步骤 3/5: 初始化 Web3 客户端...")
@@ -55,7 +60,8 @@ func main() {
 		return
 	}
 	defer client.Close()
-	fmt.Println("✅ 成功\n")
+	fmt.Println("✅ 成功")
+	fmt.Println()
 
 	// 4. 测试 V3 深度采集
 	fmt.Println("========================================")
@@ -65,7 +71,7 @@ func main() {
 	col := collector.NewCollector(client, nil)
 
 	fmt.Println("开始采集V3深度数据...")
-	if err := col.CollectV3Depths(); err != nil {
+	if err := col.CollectV3Depths(context.Background()); err != nil {
 		fmt.Printf("❌ 失败: %v\n", err)
 	} else {
 		fmt.Println("✅ 采集成功")
@@ -101,7 +107,7 @@ func main() {
 	fmt.Println("========================================")
 
 	fmt.Println("开始采集Gas价格...")
-	if err := col.CollectGasData(); err != nil {
+	if err := col.CollectGasData(context.Background()); err != nil {
 		fmt.Printf("❌ 失败: %v\n", err)
 	} else {
 		fmt.Println("✅ 采集成功")
@@ -196,23 +202,12 @@ func main() {
 	fmt.Println("========================================")
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
 func weiToGwei(weiStr string) string {
 	wei, ok := new(big.Int).SetString(weiStr, 10)
 	if !ok {
 		return "0"
 	}
-	gwei := new(big.Float).Quo(
-		new(big.Float).SetInt(wei),
-		big.NewFloat(1e9),
-	)
-	return gwei.Text('f', 2)
+	return units.FormatUnitsFixed(wei, units.GweiDecimals, 2)
 }
 
 func getStatusEmoji(success bool) string {
@@ -20,7 +20,8 @@ func main() {
 
 	fmt.Println("========================================")
 	fmt.Println("🧪 DeFi Bot 综合测试工具")
-	fmt.Println("========================================\n")
+	fmt.Println("========================================")
+	fmt.Println()
 
 	// 1. 加载配置
 	fmt.Println("📋 步骤 1/7: 加载配置...")
@@ -165,10 +166,3 @@ func main() {
 	fmt.Println("🎉 所有测试完成！")
 	fmt.Println("========================================")
 }
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
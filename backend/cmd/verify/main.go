@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/defi-bot/backend/internal/config"
@@ -18,6 +19,7 @@ import (
 var (
 	configPath = flag.String("config", "configs/config.test.yaml", "配置文件路径")
 	limit      = flag.Int("limit", 10, "验证数据条数")
+	reorgDepth = flag.Uint64("reorg-depth", 64, "链重组检测回溯的区块数")
 )
 
 func main() {
@@ -44,10 +46,14 @@ func main() {
 	db := database.GetDB()
 
 	// 3. 初始化 Web3 客户端
-	client, err := web3.NewClient(
+	client, err := web3.NewClientWithOptions(
 		cfg.Blockchain.RPCURL,
 		cfg.Blockchain.ChainID,
-		cfg.Blockchain.Timeout,
+		web3.ClientOptions{
+			DialTimeout: cfg.Blockchain.GetDialTimeout(),
+			CallTimeout: cfg.Blockchain.GetCallTimeout(),
+			MaxRPS:      cfg.Blockchain.GetMaxRPS(cfg.Blockchain.RPCURL),
+		},
 	)
 	if err != nil {
 		log.Fatalf("❌ Web3 客户端初始化失败: %v", err)
@@ -55,17 +61,25 @@ func main() {
 	defer client.Close()
 
 	// 4. 创建协议工厂
-	protocolFactory := dex.NewProtocolFactory(client)
+	protocolFactory := dex.NewProtocolFactoryWithPolicy(client, dex.ProtocolPolicy{
+		Allowed: cfg.Protocols.Allowed,
+		Denied:  cfg.Protocols.Denied,
+	})
 
 	// 5. 设置数据库日志为静默模式
 	db.Logger = db.Logger.LogMode(1) // Silent mode
 
-	// 6. 查询最新的价格记录
+	// 6. 查询最新的价格记录（跳过已停用 DEX 的交易对）
+	activePairs := db.Model(&models.TradingPair{}).Select("trading_pairs.id").
+		Joins("JOIN dexes ON dexes.id = trading_pairs.dex_id").
+		Where("dexes.is_active = ?", true)
+
 	var prices []models.PriceRecord
 	err = db.Preload("Pair").
 		Preload("Pair.Token0").
 		Preload("Pair.Token1").
 		Preload("Pair.Dex").
+		Where("pair_id IN (?)", activePairs).
 		Order("created_at DESC").
 		Limit(*limit).
 		Find(&prices).Error
@@ -132,7 +146,10 @@ func verifyPriceRecord(client *web3.Client, factory *dex.ProtocolFactory, price
 	log.Printf("数据库记录时间: %s", price.Timestamp.Format("2006-01-02 15:04:05"))
 
 	// 获取协议适配器
-	protocol, err := factory.CreateProtocol(pair.Dex.Protocol)
+	protocol, err := factory.CreateProtocolWithOptions(pair.Dex.Protocol, dex.ProtocolOptions{
+		ReserveStyle: pair.Dex.ReserveStyle,
+		PoolManager:  pair.Dex.FactoryAddress,
+	})
 	if err != nil {
 		log.Printf("❌ 获取协议适配器失败: %v", err)
 		return false
@@ -145,6 +162,13 @@ func verifyPriceRecord(client *web3.Client, factory *dex.ProtocolFactory, price
 		return false
 	}
 
+	// 数据库按记录的代币顺序保存储备量（采集时已对调），链上 reserve0 属于地址较小的代币
+	chainReserve0, chainReserve1 := priceInfo.Reserve0, priceInfo.Reserve1
+	if pair.TokensReversed() {
+		log.Println("代币顺序与链上相反，链上储备量对调后比较")
+		chainReserve0, chainReserve1 = chainReserve1, chainReserve0
+	}
+
 	// 解析数据库中的储备量
 	dbReserve0, ok := new(big.Int).SetString(price.Reserve0, 10)
 	if !ok {
@@ -160,18 +184,18 @@ func verifyPriceRecord(client *web3.Client, factory *dex.ProtocolFactory, price
 
 	// 计算误差
 	log.Println("\n📊 储备量对比：")
-	log.Printf("Reserve0:")
-	log.Printf("  链上:    %s", priceInfo.Reserve0.String())
+	log.Printf("Reserve0 (%s):", pair.Token0.Symbol)
+	log.Printf("  链上:    %s", chainReserve0.String())
 	log.Printf("  数据库:  %s", dbReserve0.String())
 
-	errorRate0 := calculateErrorRate(priceInfo.Reserve0, dbReserve0)
+	errorRate0 := calculateErrorRate(chainReserve0, dbReserve0)
 	log.Printf("  误差率:  %.4f%%", errorRate0)
 
-	log.Printf("\nReserve1:")
-	log.Printf("  链上:    %s", priceInfo.Reserve1.String())
+	log.Printf("\nReserve1 (%s):", pair.Token1.Symbol)
+	log.Printf("  链上:    %s", chainReserve1.String())
 	log.Printf("  数据库:  %s", dbReserve1.String())
 
-	errorRate1 := calculateErrorRate(priceInfo.Reserve1, dbReserve1)
+	errorRate1 := calculateErrorRate(chainReserve1, dbReserve1)
 	log.Printf("  误差率:  %.4f%%", errorRate1)
 
 	// 判断是否通过验证（误差率 < 5% 认为合理）
@@ -214,15 +238,17 @@ func checkBlockDelay(client *web3.Client, db *gorm.DB) {
 		return
 	}
 
-	// 查询链上最新区块号
-	latestBlock, err := client.GetBlockNumber()
+	// 查询链上最新区块
+	header, err := client.GetLatestHeader()
 	if err != nil {
 		log.Printf("❌ 查询链上区块失败: %v", err)
 		return
 	}
+	latestBlock := header.Number.Uint64()
 
+	// 记录时间为区块时间戳，与链上最新区块时间比较
 	blockDiff := latestBlock - latestRecord.BlockNumber
-	timeDiff := time.Since(latestRecord.Timestamp)
+	timeDiff := time.Unix(int64(header.Time), 0).Sub(latestRecord.Timestamp)
 
 	log.Printf("数据库最新区块: %d", latestRecord.BlockNumber)
 	log.Printf("链上最新区块:   %d", latestBlock)
@@ -237,6 +263,81 @@ func checkBlockDelay(client *web3.Client, db *gorm.DB) {
 	} else {
 		log.Println("\n❌ 数据延迟较大（> 50 个区块）")
 	}
+
+	// 检查链重组
+	checkReorg(client, db, &latestRecord)
+}
+
+// checkReorg 检查数据库记录的区块是否已被链重组移出规范链
+// 对比记录中保存的区块哈希与链上同一区块号的当前哈希
+func checkReorg(client *web3.Client, db *gorm.DB, latestRecord *models.PriceRecord) {
+	log.Println("\n🔗 链重组检查：")
+
+	if latestRecord.BlockHash == "" {
+		log.Println("⚠️  最新记录未保存区块哈希，跳过重组检测")
+		return
+	}
+
+	chainHash, err := client.GetBlockHash(latestRecord.BlockNumber)
+	if err != nil {
+		log.Printf("❌ 查询链上区块哈希失败: %v", err)
+		return
+	}
+
+	log.Printf("数据库区块哈希: %s", latestRecord.BlockHash)
+	log.Printf("链上区块哈希:   %s", chainHash.Hex())
+
+	if strings.EqualFold(chainHash.Hex(), latestRecord.BlockHash) {
+		log.Println("✅ 最新记录所在区块仍在规范链上")
+	} else {
+		log.Printf("❌ 检测到链重组：区块 %d 已被替换", latestRecord.BlockNumber)
+	}
+
+	// 统计最近若干区块内落在孤块上的记录
+	fromBlock := uint64(0)
+	if latestRecord.BlockNumber > *reorgDepth {
+		fromBlock = latestRecord.BlockNumber - *reorgDepth
+	}
+
+	var blocks []struct {
+		BlockNumber uint64
+		BlockHash   string
+		Count       int64
+	}
+	err = db.Model(&models.PriceRecord{}).
+		Select("block_number, block_hash, COUNT(*) AS count").
+		Where("block_number >= ? AND block_hash <> ''", fromBlock).
+		Group("block_number, block_hash").
+		Order("block_number DESC").
+		Scan(&blocks).Error
+	if err != nil {
+		log.Printf("❌ 查询最近区块记录失败: %v", err)
+		return
+	}
+
+	orphanedBlocks := 0
+	var orphanedRecords int64
+	for _, b := range blocks {
+		hash, err := client.GetBlockHash(b.BlockNumber)
+		if err != nil {
+			log.Printf("⚠️  查询区块 %d 哈希失败: %v", b.BlockNumber, err)
+			continue
+		}
+
+		if !strings.EqualFold(hash.Hex(), b.BlockHash) {
+			orphanedBlocks++
+			orphanedRecords += b.Count
+			log.Printf("  ❌ 区块 %d: 记录哈希 %s ≠ 链上哈希 %s（%d 条记录）",
+				b.BlockNumber, b.BlockHash, hash.Hex(), b.Count)
+		}
+	}
+
+	log.Printf("检查范围:       区块 %d ~ %d（%d 个区块）", fromBlock, latestRecord.BlockNumber, len(blocks))
+	if orphanedRecords == 0 {
+		log.Println("✅ 最近记录均位于规范链上")
+	} else {
+		log.Printf("❌ 孤块上的记录: %d 条（分布在 %d 个区块），建议丢弃这些记录", orphanedRecords, orphanedBlocks)
+	}
 }
 
 // abs 返回绝对值
@@ -0,0 +1,253 @@
+package analytics
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/models"
+)
+
+// PerformanceAnalyzer 策略表现分析器
+// 对比每次执行的预期利润与实际利润，评估各类策略是否真正盈利
+type PerformanceAnalyzer struct {
+	nativeSymbol string // Gas 计价代币的符号（用其 price_usd 把 Gas 成本换算为输入代币和美元）
+}
+
+// NewPerformanceAnalyzer 创建策略表现分析器
+func NewPerformanceAnalyzer() *PerformanceAnalyzer {
+	return &PerformanceAnalyzer{nativeSymbol: "WETH"}
+}
+
+// SetNativeSymbol 设置 Gas 计价代币的符号（默认 WETH）
+func (a *PerformanceAnalyzer) SetNativeSymbol(symbol string) {
+	if symbol != "" {
+		a.nativeSymbol = symbol
+	}
+}
+
+// performanceKey 统计分组键
+type performanceKey struct {
+	arbitrageType string
+	dexPath       string
+	tokenInID     uint
+}
+
+// performanceStats 分组内的累计数据
+type performanceStats struct {
+	executions     int
+	successes      int
+	wins           int
+	slippages      []float64
+	unpriced       int // 缺少价格、无法计算净利润的执行次数
+	expectedProfit *big.Int
+	actualProfit   *big.Int
+	gasCost        *big.Int
+	netProfit      *big.Int // 输入代币最小单位（Gas 已按价格换算）
+	netProfitUSD   float64
+}
+
+// Compute 统计最近 window 时间内的执行记录并写入 strategy_performance 表
+func (a *PerformanceAnalyzer) Compute(window time.Duration) ([]models.StrategyPerformance, error) {
+	db := database.GetDB()
+
+	windowEnd := time.Now()
+	windowStart := windowEnd.Add(-window)
+
+	var executions []models.ArbitrageExecution
+	err := db.Preload("Opportunity").
+		Preload("TokenIn").
+		Where("timestamp >= ? AND timestamp < ?", windowStart, windowEnd).
+		Where("status <> ?", "pending").
+		Find(&executions).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询执行记录失败: %w", err)
+	}
+
+	if len(executions) == 0 {
+		log.Println("策略表现统计: 窗口内无执行记录")
+		return nil, nil
+	}
+
+	// Gas 成本以原生币计价，利润以输入代币计价，需要按价格换算到同一单位才能相减
+	var native models.Token
+	if err := db.Where("symbol = ?", a.nativeSymbol).First(&native).Error; err != nil {
+		return nil, fmt.Errorf("查询 Gas 计价代币 %s 失败: %w", a.nativeSymbol, err)
+	}
+
+	groups := make(map[performanceKey]*performanceStats)
+	keys := make([]performanceKey, 0)
+
+	for i := range executions {
+		exec := &executions[i]
+
+		key := performanceKey{
+			arbitrageType: "manual", // 手动执行没有关联的套利机会
+			dexPath:       exec.DexPath,
+			tokenInID:     exec.TokenInID,
+		}
+		if exec.Opportunity != nil {
+			key.arbitrageType = exec.Opportunity.ArbitrageType
+		}
+
+		stats, ok := groups[key]
+		if !ok {
+			stats = &performanceStats{
+				expectedProfit: big.NewInt(0),
+				actualProfit:   big.NewInt(0),
+				gasCost:        big.NewInt(0),
+				netProfit:      big.NewInt(0),
+			}
+			groups[key] = stats
+			keys = append(keys, key)
+		}
+
+		stats.executions++
+
+		gasCost := calculateGasCost(exec.GasUsed, exec.GasPrice)
+		stats.gasCost.Add(stats.gasCost, gasCost)
+
+		// 净利润：Gas 成本按当前价格换算为输入代币后再相减（失败的执行只有 Gas 成本）
+		net, ok := exec.NetProfitAt(native.PriceUSD)
+		netUSD, usdOK := exec.NetProfitUSDAt(native.PriceUSD)
+		if ok && usdOK {
+			stats.netProfit.Add(stats.netProfit, net)
+			stats.netProfitUSD += netUSD
+		} else {
+			stats.unpriced++
+		}
+
+		if exec.Status != "success" {
+			continue
+		}
+		stats.successes++
+
+		actual := parseBigInt(exec.ActualProfit)
+		stats.actualProfit.Add(stats.actualProfit, actual)
+
+		if ok && net.Sign() > 0 {
+			stats.wins++
+		}
+
+		if exec.Opportunity == nil {
+			continue
+		}
+		expected := parseBigInt(exec.Opportunity.ExpectedProfit)
+		stats.expectedProfit.Add(stats.expectedProfit, expected)
+		if expected.Sign() > 0 {
+			stats.slippages = append(stats.slippages, profitSlippage(expected, actual))
+		}
+	}
+
+	results := make([]models.StrategyPerformance, 0, len(keys))
+	for _, key := range keys {
+		stats := groups[key]
+
+		winRate := float64(stats.wins) / float64(stats.executions) * 100
+		mean, median := meanAndMedian(stats.slippages)
+
+		results = append(results, models.StrategyPerformance{
+			ArbitrageType:       key.arbitrageType,
+			DexPath:             key.dexPath,
+			TokenInID:           key.tokenInID,
+			WindowStart:         windowStart,
+			WindowEnd:           windowEnd,
+			Executions:          stats.executions,
+			Successes:           stats.successes,
+			Wins:                stats.wins,
+			WinRate:             winRate,
+			MeanSlippage:        mean,
+			MedianSlippage:      median,
+			TotalExpectedProfit: stats.expectedProfit.String(),
+			TotalActualProfit:   stats.actualProfit.String(),
+			TotalGasCost:        stats.gasCost.String(),
+			NetProfit:           stats.netProfit.String(),
+			NetProfitUSD:        stats.netProfitUSD,
+			Unpriced:            stats.unpriced,
+		})
+	}
+
+	if err := db.CreateInBatches(results, 100).Error; err != nil {
+		return nil, fmt.Errorf("写入策略表现失败: %w", err)
+	}
+
+	log.Printf("✅ 策略表现统计完成: %d 条执行记录, %d 个策略分组", len(executions), len(results))
+	return results, nil
+}
+
+// Latest 获取最近一次统计的结果
+func (a *PerformanceAnalyzer) Latest() ([]models.StrategyPerformance, error) {
+	db := database.GetDB()
+
+	var latest models.StrategyPerformance
+	err := db.Order("window_end DESC").Limit(1).Find(&latest).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询策略表现失败: %w", err)
+	}
+	if latest.ID == 0 {
+		return []models.StrategyPerformance{}, nil
+	}
+
+	var results []models.StrategyPerformance
+	err = db.Preload("TokenIn").
+		Where("window_end = ?", latest.WindowEnd).
+		Order("net_profit_usd DESC"). // 不同分组的输入代币不同，按美元排序
+		Find(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询策略表现失败: %w", err)
+	}
+
+	return results, nil
+}
+
+// calculateGasCost 计算 Gas 成本（gasUsed * gasPrice）
+func calculateGasCost(gasUsed uint64, gasPrice string) *big.Int {
+	price := parseBigInt(gasPrice)
+	return new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), price)
+}
+
+// profitSlippage 计算预期利润与实际利润的偏差（百分比）
+// 正数表示实际利润低于预期
+func profitSlippage(expected, actual *big.Int) float64 {
+	diff := new(big.Float).SetInt(new(big.Int).Sub(expected, actual))
+	ratio := new(big.Float).Quo(diff, new(big.Float).SetInt(expected))
+	result, _ := ratio.Float64()
+	return result * 100
+}
+
+// meanAndMedian 计算平均值和中位数
+func meanAndMedian(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	mean := sum / float64(len(sorted))
+
+	mid := len(sorted) / 2
+	median := sorted[mid]
+	if len(sorted)%2 == 0 {
+		median = (sorted[mid-1] + sorted[mid]) / 2
+	}
+
+	return mean, median
+}
+
+// parseBigInt 解析十进制字符串，失败时返回 0
+func parseBigInt(s string) *big.Int {
+	value, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return big.NewInt(0)
+	}
+	return value
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/defi-bot/backend/internal/config"
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/pkg/dex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// handleReload 重新加载配置文件并同步 DEX / 代币到数据库
+// POST /admin/reload（全部同步成功返回 200，有条目失败返回 500，响应体都是 diff）
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.ReloadConfig()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	diff := database.ReconcileConfig(r.Context(), cfg)
+	if err := diff.Err(); err != nil {
+		// 部分条目同步失败：返回 500 和完整的 diff（failed 列出失败条目），便于部署脚本判断
+		log.Printf("⚠️  配置热加载部分失败: %s: %v", diff.Summary(), err)
+		writeJSON(w, http.StatusInternalServerError, diff)
+		return
+	}
+
+	log.Printf("✅ 配置热加载完成: %s", diff.Summary())
+	writeJSON(w, http.StatusOK, diff)
+}
+
+// handleTradingState 查询交易开关状态
+// GET /admin/trading
+func (s *Server) handleTradingState(w http.ResponseWriter, r *http.Request) {
+	if s.trading == nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("交易开关未启用"))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.trading.State())
+}
+
+// handlePause 暂停交易（数据采集继续运行）
+// POST /admin/pause?reason=...
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	reason := r.URL.Query().Get("reason")
+	if reason == "" {
+		reason = "手动暂停"
+	}
+	s.setTradingEnabled(w, false, reason)
+}
+
+// handleResume 恢复交易（亏损熔断后也需要通过该接口手动恢复）
+// POST /admin/resume
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	s.setTradingEnabled(w, true, "")
+}
+
+// setTradingEnabled 修改交易开关并返回当前状态
+func (s *Server) setTradingEnabled(w http.ResponseWriter, enabled bool, reason string) {
+	if s.trading == nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("交易开关未启用"))
+		return
+	}
+
+	state, err := s.trading.SetEnabled(enabled, reason)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// handleRPCStats 查询各 RPC 方法的调用次数和延迟（按调用次数降序）
+// GET /admin/rpc-stats
+func (s *Server) handleRPCStats(w http.ResponseWriter, r *http.Request) {
+	if s.web3Client == nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("Web3 客户端未设置"))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.web3Client.Stats())
+}
+
+// handleDexContracts 校验各 DEX 的 Router / Quoter 是否属于配置的 Factory
+// GET /admin/dex-contracts
+func (s *Server) handleDexContracts(w http.ResponseWriter, r *http.Request) {
+	if s.collector == nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("采集器未设置"))
+		return
+	}
+
+	checks, err := s.collector.ValidateDexContracts()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, checks)
+}
+
+// aggregatorRateResponse 聚合器现货价格
+type aggregatorRateResponse struct {
+	TokenIn      string `json:"token_in"`
+	TokenOut     string `json:"token_out"`
+	Price        string `json:"price"`         // 1 个 token_in 可换的 token_out 数量
+	InversePrice string `json:"inverse_price"` // 1 个 token_out 可换的 token_in 数量
+}
+
+// handleAggregatorRate 通过 1inch OffchainOracle 查询两个代币间的现货价格（与自有路由对比）
+// GET /admin/aggregator-rate?token_in=0x...&token_out=0x...
+func (s *Server) handleAggregatorRate(w http.ResponseWriter, r *http.Request) {
+	if s.collector == nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("采集器未设置"))
+		return
+	}
+
+	tokenIn := r.URL.Query().Get("token_in")
+	tokenOut := r.URL.Query().Get("token_out")
+	if !common.IsHexAddress(tokenIn) || !common.IsHexAddress(tokenOut) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("token_in / token_out 必须是合法地址"))
+		return
+	}
+
+	info, err := s.collector.AggregatorRate(r.Context(), tokenIn, tokenOut)
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, dex.ErrInvalidParams) {
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, aggregatorRateResponse{
+		TokenIn:      tokenIn,
+		TokenOut:     tokenOut,
+		Price:        info.Price.Text('g', 18),
+		InversePrice: info.InversePrice.Text('g', 18),
+	})
+}
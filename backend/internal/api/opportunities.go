@@ -0,0 +1,138 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultOpportunityLimit = 50
+	maxOpportunityLimit     = 500
+)
+
+// handleOpportunities 按代币对查询最近的套利机会
+// GET /opportunities?token0=WETH&token1=USDC&status=pending&limit=50
+// token0 / token1 可以是代币符号或合约地址，两个方向的机会都会返回
+func (s *Server) handleOpportunities(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := defaultOpportunityLimit
+	if v := query.Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit 参数无效: %s", v))
+			return
+		}
+		if parsed > maxOpportunityLimit {
+			parsed = maxOpportunityLimit
+		}
+		limit = parsed
+	}
+
+	db := database.GetDB().
+		Preload("TokenIn").
+		Preload("TokenOut").
+		Order("created_at DESC").
+		Limit(limit)
+
+	token0Param := query.Get("token0")
+	token1Param := query.Get("token1")
+
+	if token0Param != "" {
+		token0, err := resolveToken(token0Param)
+		if err != nil {
+			writeTokenError(w, token0Param, err)
+			return
+		}
+
+		if token1Param != "" {
+			token1, err := resolveToken(token1Param)
+			if err != nil {
+				writeTokenError(w, token1Param, err)
+				return
+			}
+			db = db.Where("(token_in_id = ? AND token_out_id = ?) OR (token_in_id = ? AND token_out_id = ?)",
+				token0.ID, token1.ID, token1.ID, token0.ID)
+		} else {
+			db = db.Where("token_in_id = ?", token0.ID)
+		}
+	} else if token1Param != "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("指定 token1 时必须同时指定 token0"))
+		return
+	}
+
+	if status := query.Get("status"); status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	var opportunities []models.ArbitrageOpportunity
+	if err := db.Find(&opportunities).Error; err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("查询套利机会失败: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, opportunities)
+}
+
+// handleBestOpportunities 每个代币对当前最佳（利润率最高）的未过期 pending 机会
+// GET /opportunities/best?type=cross_dex&min_profit_rate=0.5&limit=50
+func (s *Server) handleBestOpportunities(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := repository.BestOpportunityFilter{
+		ArbitrageType: query.Get("type"),
+		Limit:         defaultOpportunityLimit,
+	}
+
+	if v := query.Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit 参数无效: %s", v))
+			return
+		}
+		filter.Limit = min(parsed, maxOpportunityLimit)
+	}
+
+	if v := query.Get("min_profit_rate"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("min_profit_rate 参数无效: %s", v))
+			return
+		}
+		filter.MinProfitRate = parsed
+	}
+
+	opportunities, err := repository.NewOpportunityRepository(database.GetDB(), repository.OpportunityPersistPolicy{}).BestPerPair(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, opportunities)
+}
+
+// resolveToken 将代币符号或合约地址解析为代币记录
+func resolveToken(value string) (*models.Token, error) {
+	tokens := database.GetTokenRepository()
+	if strings.HasPrefix(value, "0x") {
+		return tokens.GetByAddress(value)
+	}
+	return tokens.GetBySymbol(value)
+}
+
+// writeTokenError 输出代币解析错误
+func writeTokenError(w http.ResponseWriter, value string, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("未找到代币: %s", value))
+		return
+	}
+	writeError(w, http.StatusInternalServerError, fmt.Errorf("查询代币 %s 失败: %w", value, err))
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/defi-bot/backend/internal/collector"
+	"gorm.io/gorm"
+)
+
+// handlePairRefresh 立即刷新单个交易对的价格并写入数据库
+// POST /pairs/{id}/refresh
+func (s *Server) handlePairRefresh(w http.ResponseWriter, r *http.Request) {
+	if s.collector == nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("采集器未设置"))
+		return
+	}
+
+	// 路径格式：/pairs/{id}/refresh
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 || parts[2] != "refresh" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("未知路径: %s", r.URL.Path))
+		return
+	}
+
+	pairID, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || pairID == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("交易对 ID 无效: %s", parts[1]))
+		return
+	}
+
+	data, err := s.collector.CollectSinglePair(r.Context(), uint(pairID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("交易对 %d 不存在", pairID))
+		return
+	}
+	if errors.Is(err, collector.ErrDexInactive) || errors.Is(err, collector.ErrPairInactive) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+// pairStateResponse 交易对启用状态
+type pairStateResponse struct {
+	ID          uint   `json:"id"`
+	PairAddress string `json:"pair_address"`
+	Token0      string `json:"token0"`
+	Token1      string `json:"token1"`
+	Dex         string `json:"dex"`
+	IsActive    bool   `json:"is_active"`
+}
+
+// handlePairActive 启用 / 停用单个交易对（问题池子、蜜罐代币等，不需要停用整个 DEX）
+// POST /admin/pairs/{id}/enable
+// POST /admin/pairs/{id}/disable
+func (s *Server) handlePairActive(w http.ResponseWriter, r *http.Request) {
+	if s.collector == nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("采集器未设置"))
+		return
+	}
+
+	// 路径格式：/admin/pairs/{id}/{enable|disable}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 4 || (parts[3] != "enable" && parts[3] != "disable") {
+		writeError(w, http.StatusNotFound, fmt.Errorf("未知路径: %s", r.URL.Path))
+		return
+	}
+
+	pairID, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil || pairID == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("交易对 ID 无效: %s", parts[2]))
+		return
+	}
+
+	pair, err := s.collector.SetPairActive(r.Context(), uint(pairID), parts[3] == "enable")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("交易对 %d 不存在", pairID))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pairStateResponse{
+		ID:          pair.ID,
+		PairAddress: pair.PairAddress,
+		Token0:      pair.Token0.Symbol,
+		Token1:      pair.Token1.Symbol,
+		Dex:         pair.Dex.Name,
+		IsActive:    pair.IsActive,
+	})
+}
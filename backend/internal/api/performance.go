@@ -0,0 +1,77 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/defi-bot/backend/internal/analytics"
+	"github.com/defi-bot/backend/internal/database"
+)
+
+// defaultPerformanceHours 执行统计接口的默认窗口（小时）
+const defaultPerformanceHours = 24
+
+// handlePerformance 返回最近一次策略表现统计
+// GET /performance
+func (s *Server) handlePerformance(w http.ResponseWriter, r *http.Request) {
+	results, err := analytics.NewPerformanceAnalyzer().Latest()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+// handleBlockDrift 返回最近执行的区块漂移直方图（发现区块到上链区块的差）
+// GET /performance/block-drift?hours=24
+func (s *Server) handleBlockDrift(w http.ResponseWriter, r *http.Request) {
+	hours, err := parseHours(r, defaultPerformanceHours)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	buckets, err := database.GetExecutionRepository().BlockDriftHistogram(since)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, buckets)
+}
+
+// handleVaultProfit 按金库汇总最近的执行利润
+// GET /performance/vaults?hours=24
+func (s *Server) handleVaultProfit(w http.ResponseWriter, r *http.Request) {
+	hours, err := parseHours(r, defaultPerformanceHours)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	results, err := database.GetExecutionRepository().ProfitByVault(since)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+// parseHours 解析 hours 查询参数（统计窗口，小时），未提供时返回 defaultHours
+func parseHours(r *http.Request, defaultHours int) (int, error) {
+	v := r.URL.Query().Get("hours")
+	if v == "" {
+		return defaultHours, nil
+	}
+	hours, err := strconv.Atoi(v)
+	if err != nil || hours <= 0 {
+		return 0, fmt.Errorf("hours 参数无效: %s", v)
+	}
+	return hours, nil
+}
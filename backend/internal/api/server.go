@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/defi-bot/backend/internal/collector"
+	"github.com/defi-bot/backend/internal/config"
+	"github.com/defi-bot/backend/internal/trading"
+	"github.com/defi-bot/backend/pkg/web3"
+)
+
+// Server HTTP API 服务
+type Server struct {
+	config     *config.ServerConfig
+	mux        *http.ServeMux
+	httpServer *http.Server
+	trading    *trading.Control     // 交易开关（未设置时暂停/恢复接口不可用）
+	web3Client *web3.Client         // Web3 客户端（用于查询 RPC 调用统计）
+	collector  *collector.Collector // 数据采集器（用于手动刷新交易对）
+}
+
+// NewServer 创建 API 服务
+func NewServer(cfg *config.ServerConfig) *Server {
+	s := &Server{
+		config: cfg,
+		mux:    http.NewServeMux(),
+	}
+
+	s.registerRoutes()
+
+	s.httpServer = &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s
+}
+
+// registerRoutes 注册路由
+func (s *Server) registerRoutes() {
+	s.mux.HandleFunc("/health", s.handleHealth)
+	s.mux.HandleFunc("/performance", s.methodOnly(http.MethodGet, s.handlePerformance))
+	s.mux.HandleFunc("/performance/vaults", s.methodOnly(http.MethodGet, s.handleVaultProfit))
+	s.mux.HandleFunc("/performance/block-drift", s.methodOnly(http.MethodGet, s.handleBlockDrift))
+	s.mux.HandleFunc("/opportunities", s.methodOnly(http.MethodGet, s.handleOpportunities))
+	s.mux.HandleFunc("/opportunities/best", s.methodOnly(http.MethodGet, s.handleBestOpportunities))
+
+	// === 管理接口 ===
+	// 未配置管理令牌时不注册（默认部署不能对外暴露暂停交易、刷新交易对等写操作）
+	if s.config.AdminToken == "" {
+		log.Println("⚠️  未配置 server.admin_token，管理接口未启用")
+		return
+	}
+
+	s.mux.HandleFunc("/pairs/", s.adminOnly(http.MethodPost, s.handlePairRefresh)) // 触发链上调用和写库，需要管理令牌
+	s.mux.HandleFunc("/admin/reload", s.adminOnly(http.MethodPost, s.handleReload))
+	s.mux.HandleFunc("/admin/trading", s.adminOnly(http.MethodGet, s.handleTradingState))
+	s.mux.HandleFunc("/admin/pause", s.adminOnly(http.MethodPost, s.handlePause))
+	s.mux.HandleFunc("/admin/resume", s.adminOnly(http.MethodPost, s.handleResume))
+	s.mux.HandleFunc("/admin/rpc-stats", s.adminOnly(http.MethodGet, s.handleRPCStats))
+	s.mux.HandleFunc("/admin/dex-contracts", s.adminOnly(http.MethodGet, s.handleDexContracts))
+	s.mux.HandleFunc("/admin/aggregator-rate", s.adminOnly(http.MethodGet, s.handleAggregatorRate))
+	s.mux.HandleFunc("/admin/pairs/", s.adminOnly(http.MethodPost, s.handlePairActive))
+}
+
+// SetTradingControl 设置交易开关
+func (s *Server) SetTradingControl(control *trading.Control) {
+	s.trading = control
+}
+
+// SetWeb3Client 设置 Web3 客户端
+func (s *Server) SetWeb3Client(client *web3.Client) {
+	s.web3Client = client
+}
+
+// SetCollector 设置数据采集器
+func (s *Server) SetCollector(c *collector.Collector) {
+	s.collector = c
+}
+
+// Start 启动 HTTP 服务（非阻塞）
+func (s *Server) Start() {
+	go func() {
+		log.Printf("HTTP API 服务已启动: %s", s.httpServer.Addr)
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("❌ HTTP API 服务异常退出: %v", err)
+		}
+	}()
+}
+
+// Shutdown 优雅关闭 HTTP 服务
+func (s *Server) Shutdown(ctx context.Context) error {
+	log.Println("停止 HTTP API 服务...")
+	return s.httpServer.Shutdown(ctx)
+}
+
+// handleHealth 健康检查
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// methodOnly 限制请求方法
+func (s *Server) methodOnly(method string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("仅支持 %s 请求", method))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// adminOnly 限制请求方法并校验管理令牌（常量时间比较；未配置令牌时一律拒绝）
+func (s *Server) adminOnly(method string, next http.HandlerFunc) http.HandlerFunc {
+	return s.methodOnly(method, func(w http.ResponseWriter, r *http.Request) {
+		token := s.config.AdminToken
+		if token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Token")), []byte(token)) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("管理令牌无效"))
+			return
+		}
+
+		next(w, r)
+	})
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("⚠️  写入响应失败: %v", err)
+	}
+}
+
+// writeError 输出错误响应
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/defi-bot/backend/internal/config"
+)
+
+func TestAdminRoutesAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminToken string
+		header     string
+		want       int
+	}{
+		{name: "未配置令牌时不注册管理接口", adminToken: "", header: "", want: http.StatusNotFound},
+		{name: "未配置令牌时携带任意令牌也不可用", adminToken: "", header: "anything", want: http.StatusNotFound},
+		{name: "缺少令牌", adminToken: "secret", header: "", want: http.StatusUnauthorized},
+		{name: "令牌错误", adminToken: "secret", header: "secreT", want: http.StatusUnauthorized},
+		{name: "令牌前缀不算通过", adminToken: "secret", header: "secre", want: http.StatusUnauthorized},
+		{name: "令牌正确", adminToken: "secret", header: "secret", want: http.StatusServiceUnavailable}, // 通过校验，交易开关未设置
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&config.ServerConfig{AdminToken: tt.adminToken})
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/trading", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Admin-Token", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			s.mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("状态码 = %d, 期望 %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminOnlyRejectsEmptyToken(t *testing.T) {
+	s := &Server{config: &config.ServerConfig{}}
+	called := false
+	handler := s.adminOnly(http.MethodPost, func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/admin/pause", nil))
+
+	if called || rec.Code != http.StatusUnauthorized {
+		t.Errorf("未配置令牌时应拒绝请求: code=%d called=%v", rec.Code, called)
+	}
+}
@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrDexInactive 交易对所属的 DEX 已停用
+var ErrDexInactive = errors.New("DEX 已停用")
+
+// activeDexRefreshInterval 内存中活跃 DEX 集合的刷新间隔
+const activeDexRefreshInterval = 30 * time.Second
+
+// activeDexSet 活跃 DEX 的内存集合
+// 在数据库中停用 DEX 后，最迟一个刷新间隔内生效，不需要重启服务
+type activeDexSet struct {
+	mu       sync.RWMutex
+	ids      map[uint]bool
+	loadedAt time.Time
+}
+
+// isDexActive 判断 DEX 当前是否启用（集合过期时先从数据库刷新）
+// 从未成功加载过时视为启用，避免数据库故障时停掉所有采集
+func (c *Collector) isDexActive(dexID uint) bool {
+	set := &c.activeDexes
+
+	set.mu.RLock()
+	fresh := set.ids != nil && time.Since(set.loadedAt) < activeDexRefreshInterval
+	active, known := set.ids[dexID]
+	set.mu.RUnlock()
+
+	if fresh {
+		return active
+	}
+
+	if err := c.RefreshActiveDexes(); err != nil {
+		log.Printf("⚠️  刷新活跃 DEX 列表失败: %v", err)
+		return !known || active
+	}
+
+	set.mu.RLock()
+	defer set.mu.RUnlock()
+	return set.ids[dexID]
+}
+
+// RefreshActiveDexes 从数据库重新加载活跃 DEX 集合
+func (c *Collector) RefreshActiveDexes() error {
+	var ids []uint
+	if err := database.GetDB().Model(&models.Dex{}).
+		Where("is_active = ?", true).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	active := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		active[id] = true
+	}
+
+	set := &c.activeDexes
+	set.mu.Lock()
+	set.ids = active
+	set.loadedAt = time.Now()
+	set.mu.Unlock()
+	return nil
+}
+
+// filterActiveDexPairs 去掉 DEX 已停用的交易对（查询之后被停用的 DEX 也能及时跳过）
+func (c *Collector) filterActiveDexPairs(pairs []models.TradingPair) []models.TradingPair {
+	filtered := make([]models.TradingPair, 0, len(pairs))
+	for _, pair := range pairs {
+		if c.isDexActive(pair.DexID) {
+			filtered = append(filtered, pair)
+		}
+	}
+	return filtered
+}
+
+// activeDexPairs 查询条件：只保留 DEX 处于启用状态的交易对
+func activeDexPairs(db *gorm.DB) *gorm.DB {
+	activeDexes := db.Session(&gorm.Session{NewDB: true}).
+		Model(&models.Dex{}).Select("id").Where("is_active = ?", true)
+	return db.Where("trading_pairs.dex_id IN (?)", activeDexes)
+}
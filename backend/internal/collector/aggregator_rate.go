@@ -0,0 +1,36 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/defi-bot/backend/pkg/dex"
+)
+
+// SetOneInchOracle 设置 1inch OffchainOracle 地址（为空时不查询聚合器汇率）
+func (c *Collector) SetOneInchOracle(address string) {
+	c.oneInchOracle = address
+}
+
+// AggregatorRate 通过 1inch OffchainOracle 查询 tokenIn → tokenOut 的现货价格
+// 作为聚合器报价的参考，与自有池子的价格对比；代币精度从链上读取
+func (c *Collector) AggregatorRate(ctx context.Context, tokenIn, tokenOut string) (*dex.PriceInfo, error) {
+	if c.oneInchOracle == "" {
+		return nil, fmt.Errorf("%w: 未配置 contracts.oneinch_oracle", dex.ErrInvalidParams)
+	}
+
+	client := c.web3Client.WithContext(ctx)
+
+	metaIn, err := client.GetTokenMetadata(tokenIn)
+	if err != nil {
+		return nil, fmt.Errorf("读取代币 %s 精度失败: %w", tokenIn, err)
+	}
+	metaOut, err := client.GetTokenMetadata(tokenOut)
+	if err != nil {
+		return nil, fmt.Errorf("读取代币 %s 精度失败: %w", tokenOut, err)
+	}
+
+	aggregator := dex.NewAggregatorProtocol(client, "1inch")
+	aggregator.SetOracleAddress(c.oneInchOracle)
+	return aggregator.GetRate(tokenIn, tokenOut, metaIn.Decimals, metaOut.Decimals)
+}
@@ -1,23 +1,42 @@
 package collector
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/defi-bot/backend/internal/database"
 	"github.com/defi-bot/backend/internal/models"
 	"github.com/defi-bot/backend/pkg/cache"
 	"github.com/defi-bot/backend/pkg/dex"
+	"github.com/defi-bot/backend/pkg/honeypot"
 	"github.com/defi-bot/backend/pkg/web3"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/gorm"
 )
 
 // Collector 数据采集器
 type Collector struct {
-	web3Client      *web3.Client
-	protocolFactory *dex.ProtocolFactory
-	cache           *cache.RedisCache
+	web3Client       *web3.Client
+	protocolFactory  *dex.ProtocolFactory
+	cache            *cache.RedisCache
+	gasCollector     *GasCollector
+	volumeOptions    VolumeOptions
+	supplyOptions    SupplyOptions
+	depthFeeTiers    []uint32 // 深度采集探测的费率层级（为空表示全部）
+	depthConcurrency int      // 深度采集同时探测的池子数
+	pinSnapshot      bool     // 价格读取固定在本轮区块头的区块
+	oneInchOracle    string   // 1inch OffchainOracle 地址（聚合器参考价格）
+	activeDexes      activeDexSet
+	honeypot         honeypot.Checker // 蜜罐检测器（为空时不检测）
+
+	successSampleRate uint64        // 逐交易对成功日志采样率（每 N 条输出 1 条，0 表示不输出）
+	successLogCount   atomic.Uint64 // 成功日志计数
 }
 
 // NewCollector 创建新的采集器
@@ -26,52 +45,97 @@ func NewCollector(web3Client *web3.Client, redisCache *cache.RedisCache) *Collec
 		web3Client:      web3Client,
 		protocolFactory: dex.NewProtocolFactory(web3Client),
 		cache:           redisCache,
+		gasCollector:    NewGasCollector(web3Client),
 	}
 }
 
+// SetProtocolPolicy 设置协议启用策略（白名单 / 黑名单）
+func (c *Collector) SetProtocolPolicy(policy dex.ProtocolPolicy) {
+	c.protocolFactory = dex.NewProtocolFactoryWithPolicy(c.web3Client, policy)
+}
+
+// SetHoneypotChecker 设置蜜罐检测器，采集交易对前会检测尚未检测过的代币
+func (c *Collector) SetHoneypotChecker(checker honeypot.Checker) {
+	c.honeypot = checker
+}
+
+// SetPinSnapshotBlock 设置是否把价格读取固定在本轮区块头的区块
+// 开启后同一轮采集的所有池子读取同一区块的状态；不支持按区块读取的协议仍读取最新状态
+func (c *Collector) SetPinSnapshotBlock(enabled bool) {
+	c.pinSnapshot = enabled
+}
+
+// SetSuccessLogSampleRate 设置逐交易对成功日志的采样率
+// 交易对数量很大时每个周期逐条输出会淹没日志，rate 为 N 时每 N 条输出 1 条，0 表示只输出汇总统计；错误日志不受影响
+func (c *Collector) SetSuccessLogSampleRate(rate int) {
+	if rate < 0 {
+		rate = 0
+	}
+	c.successSampleRate = uint64(rate)
+}
+
+// logSuccess 按采样率输出逐交易对的成功日志
+func (c *Collector) logSuccess(format string, args ...interface{}) {
+	if c.successSampleRate == 0 {
+		return
+	}
+	if (c.successLogCount.Add(1)-1)%c.successSampleRate != 0 {
+		return
+	}
+	log.Printf(format, args...)
+}
+
 // CollectAllData 采集所有数据（使用并发优化）
-func (c *Collector) CollectAllData() error {
+// ctx 取消后在下一个步骤 / 交易对边界停止，返回 ctx.Err()
+func (c *Collector) CollectAllData(ctx context.Context) error {
 	log.Println("开始采集链上数据...")
 
 	startTime := time.Now()
 
-	// 1. 获取当前区块号
-	blockNumber, err := c.web3Client.GetBlockNumber()
+	// 1. 获取当前区块（区块号 + 区块哈希）
+	header, err := c.web3Client.WithContext(ctx).GetLatestHeader()
 	if err != nil {
 		return fmt.Errorf("获取区块号失败: %w", err)
 	}
-	log.Printf("当前区块号: %d", blockNumber)
+	log.Printf("当前区块号: %d (%s)", header.Number.Uint64(), header.Hash().Hex())
 
 	// 2. 采集交易对数据
-	if err := c.CollectTradingPairs(); err != nil {
+	if err := c.CollectTradingPairs(ctx); err != nil {
 		log.Printf("采集交易对数据失败: %v", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	// 3. 采集价格数据（使用并发优化）
-	if err := c.CollectPricesConcurrent(blockNumber); err != nil {
+	if err := c.CollectPricesConcurrent(ctx, header); err != nil {
 		log.Printf("采集价格数据失败: %v", err)
 	}
-
-	// 4. ✅ 采集 V3 流动性深度数据（每次采集时）
-	if err := c.CollectV3Depths(); err != nil {
-		log.Printf("采集V3深度数据失败: %v", err)
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
+	// V3 流动性深度（QuoterV2 探测，每个池 8 次调用）开销较大，由调度器按 depth_interval 单独执行
+
 	duration := time.Since(startTime)
 	log.Printf("数据采集完成，耗时: %v", duration)
 
 	return nil
 }
 
-// CollectGasData 采集 Gas 价格数据（单独调用）
-func (c *Collector) CollectGasData() error {
-	gasCollector := NewGasCollector(c.web3Client)
-	return gasCollector.CollectGasPrice()
+// blockTime 区块时间戳，作为本周期所有记录的快照时间（与链上时间对齐，不受 RPC 延迟影响）
+func blockTime(header *types.Header) time.Time {
+	return time.Unix(int64(header.Time), 0)
 }
 
-// CollectTradingPairs 采集交易对数据
-func (c *Collector) CollectTradingPairs() error {
-	db := database.GetDB()
+// CollectGasData 采集 Gas 价格数据（单独调用，委托给采集器持有的 GasCollector）
+func (c *Collector) CollectGasData(ctx context.Context) error {
+	return c.gasCollector.CollectGasPrice(ctx)
+}
+
+// CollectTradingPairs 采集交易对数据（ctx 取消后在下一个 DEX / 代币边界停止）
+func (c *Collector) CollectTradingPairs(ctx context.Context) error {
+	db := database.GetDB().WithContext(ctx)
 
 	// 获取所有活跃的 DEX
 	var dexes []models.Dex
@@ -80,82 +144,302 @@ func (c *Collector) CollectTradingPairs() error {
 	}
 
 	// 获取所有活跃的代币
-	var tokens []models.Token
-	if err := db.Where("is_active = ?", true).Find(&tokens).Error; err != nil {
-		return fmt.Errorf("查询代币失败: %w", err)
+	tokens, err := database.GetTokenRepository().ListActive()
+	if err != nil {
+		return err
 	}
 
+	// 跳过蜜罐代币，不为其创建交易对
+	c.checkHoneypotTokens(ctx, tokens)
+	tokens = excludeHoneypotTokens(tokens)
+
 	log.Printf("开始采集交易对数据: %d 个 DEX, %d 个代币", len(dexes), len(tokens))
 
 	// 遍历所有 DEX 和代币组合，查找交易对
 	for _, dexInfo := range dexes {
 		// 获取协议适配器
-		protocol, err := c.protocolFactory.CreateProtocol(dexInfo.Protocol)
+		protocol, err := c.createProtocol(&dexInfo)
 		if err != nil {
 			log.Printf("不支持的协议 %s: %v", dexInfo.Protocol, err)
 			continue
 		}
 
+		protocolType := c.protocolFactory.GetProtocolType(dexInfo.Protocol)
+
 		for i := 0; i < len(tokens); i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
 			for j := i + 1; j < len(tokens); j++ {
 				token0 := tokens[i]
 				token1 := tokens[j]
 
-				// 根据协议类型获取交易对地址
-				var pairAddress string
-				protocolType := c.protocolFactory.GetProtocolType(dexInfo.Protocol)
 				if protocolType == "v3" {
-					// V3 需要提供 fee tier
-					pairAddress, err = protocol.GetPairAddress(
-						dexInfo.FactoryAddress,
-						token0.Address,
-						token1.Address,
-						dexInfo.FeeTier,
-					)
-				} else {
-					// V2 不需要额外参数
-					pairAddress, err = protocol.GetPairAddress(
-						dexInfo.FactoryAddress,
-						token0.Address,
-						token1.Address,
-					)
+					// V3 每个费率层级是独立的池子
+					for _, feeTier := range v3FeeTiers(&dexInfo) {
+						pairAddress, err := protocol.GetPairAddress(
+							dexInfo.FactoryAddress,
+							token0.Address,
+							token1.Address,
+							feeTier,
+						)
+						if err != nil || pairAddress == "" {
+							continue
+						}
+
+						tickSpacing, poolFee, initialized := c.inspectV3Pool(&dexInfo, pairAddress, feeTier)
+						if !initialized {
+							continue
+						}
+
+						c.saveTradingPair(&dexInfo, &token0, &token1, models.TradingPair{
+							PairAddress: pairAddress,
+							Fee:         poolFee,
+							TickSpacing: tickSpacing,
+							PoolVersion: "v3",
+						})
+					}
+					continue
 				}
 
-				if err != nil {
+				if protocolType == "v4" {
+					// V4 池子由 PoolKey 确定，只发现标准 tick 间距、无 hooks 的池子
+					for _, feeTier := range v3FeeTiers(&dexInfo) {
+						tickSpacing := web3.DefaultTickSpacing(feeTier)
+						poolId, err := protocol.GetPairAddress(
+							dexInfo.FactoryAddress,
+							token0.Address,
+							token1.Address,
+							feeTier,
+							tickSpacing,
+						)
+						if err != nil || poolId == "" {
+							continue
+						}
+
+						c.saveTradingPair(&dexInfo, &token0, &token1, models.TradingPair{
+							PairAddress: poolId,
+							Fee:         feeTier,
+							TickSpacing: tickSpacing,
+							PoolVersion: "v4",
+						})
+					}
 					continue
 				}
 
-				if pairAddress == "" {
+				// V2 不需要额外参数
+				pairAddress, err := protocol.GetPairAddress(
+					dexInfo.FactoryAddress,
+					token0.Address,
+					token1.Address,
+				)
+				if err != nil || pairAddress == "" {
 					continue
 				}
 
-				// 检查交易对是否已存在
-				var existingPair models.TradingPair
-				result := db.Where("pair_address = ?", pairAddress).First(&existingPair)
-
-				if result.Error != nil {
-					// 创建新的交易对记录
-					pair := models.TradingPair{
-						DexID:       dexInfo.ID,
-						Token0ID:    token0.ID,
-						Token1ID:    token1.ID,
-						PairAddress: pairAddress,
-						IsActive:    true,
-					}
+				c.saveTradingPair(&dexInfo, &token0, &token1, models.TradingPair{
+					PairAddress: pairAddress,
+					PoolVersion: "v2",
+				})
+			}
+		}
+	}
 
-					if err := db.Create(&pair).Error; err != nil {
-						log.Printf("创建交易对失败: %v", err)
-						continue
-					}
+	return nil
+}
 
-					log.Printf("发现新交易对: %s/%s on %s (%s)",
-						token0.Symbol, token1.Symbol, dexInfo.Name, pairAddress)
-				}
+// classifyError 按哨兵错误对采集失败分类（用于统计日志）
+func classifyError(err error) string {
+	switch {
+	case errors.Is(err, dex.ErrNoLiquidity):
+		return "no_liquidity"
+	case errors.Is(err, dex.ErrInvalidPrice):
+		return "invalid_price"
+	case errors.Is(err, dex.ErrInvalidParams):
+		return "invalid_params"
+	case errors.Is(err, dex.ErrNotImplemented), errors.Is(err, dex.ErrProtocolDisabled):
+		return "unsupported"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, web3.ErrNoAvailableClient):
+		return "no_rpc"
+	default:
+		return "other"
+	}
+}
+
+// checkHoneypotTokens 检测尚未检测过的代币是否为蜜罐并写入标记
+// 检测失败只记录日志，下一轮采集会重试
+func (c *Collector) checkHoneypotTokens(ctx context.Context, tokens []models.Token) {
+	if c.honeypot == nil {
+		return
+	}
+
+	repo := database.GetTokenRepository()
+	for i := range tokens {
+		token := &tokens[i]
+		if token.HoneypotCheckedAt != nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		result, err := c.honeypot.Check(ctx, token.Address)
+		if err != nil {
+			log.Printf("⚠️  蜜罐检测失败 %s (%s): %v", token.Symbol, token.Address, err)
+			continue
+		}
+
+		now := time.Now()
+		token.HoneypotCheckedAt = &now
+		token.IsHoneypot = result.IsHoneypot
+		token.HoneypotReason = result.Reason
+		if result.IsHoneypot {
+			log.Printf("⚠️  检测到蜜罐代币 %s (%s): %s", token.Symbol, token.Address, result.Reason)
+		}
+
+		if err := repo.UpdateHoneypot(ctx, token); err != nil {
+			log.Printf("⚠️  保存蜜罐检测结果失败 %s (%s): %v", token.Symbol, token.Address, err)
+		}
+	}
+}
+
+// excludeHoneypotTokens 过滤掉被标记为蜜罐的代币
+func excludeHoneypotTokens(tokens []models.Token) []models.Token {
+	filtered := tokens[:0]
+	for _, token := range tokens {
+		if token.IsHoneypot {
+			continue
+		}
+		filtered = append(filtered, token)
+	}
+	return filtered
+}
+
+// backfillPairFee 老数据未记录池子费率时补写链上读取的 fee（之前报价回退到 DEX 配置的费率层级）
+func (c *Collector) backfillPairFee(existing *models.TradingPair, fee uint32) {
+	if existing.Fee != 0 || fee == 0 {
+		return
+	}
+	err := database.GetDB().Model(existing).Update("fee", fee).Error
+	if err != nil {
+		log.Printf("⚠️  补写交易对 %s 的费率失败: %v", existing.PairAddress, err)
+		return
+	}
+	log.Printf("已补写交易对 %s 的池子费率: %d", existing.PairAddress, fee)
+}
+
+// excludeHoneypotPairs 查询条件：排除包含蜜罐代币的交易对
+func excludeHoneypotPairs(db *gorm.DB) *gorm.DB {
+	honeypots := db.Session(&gorm.Session{NewDB: true}).
+		Model(&models.Token{}).Select("id").Where("is_honeypot = ?", true)
+	return db.Where("token0_id NOT IN (?) AND token1_id NOT IN (?)", honeypots, honeypots)
+}
+
+// saveTradingPair 交易对不存在时创建记录
+func (c *Collector) saveTradingPair(dexInfo *models.Dex, token0, token1 *models.Token, pair models.TradingPair) {
+	db := database.GetDB()
+
+	// 检查交易对是否已存在
+	var existingPair models.TradingPair
+	if err := db.Where("pair_address = ?", pair.PairAddress).First(&existingPair).Error; err == nil {
+		c.backfillPairFee(&existingPair, pair.Fee)
+		return
+	}
+
+	// 按链上 token0/token1 顺序保存，避免储备量和价格被反向标注
+	token0, token1 = c.orderPairTokens(&pair, token0, token1)
+
+	// 创建新的交易对记录
+	pair.DexID = dexInfo.ID
+	pair.Token0ID = token0.ID
+	pair.Token1ID = token1.ID
+	pair.IsActive = true
+
+	if err := db.Create(&pair).Error; err != nil {
+		log.Printf("创建交易对失败: %v", err)
+		return
+	}
+
+	if pair.PoolVersion != "v2" {
+		log.Printf("发现新交易对: %s/%s on %s (fee: %d, tickSpacing: %d, %s)",
+			token0.Symbol, token1.Symbol, dexInfo.Name, pair.Fee, pair.TickSpacing, pair.PairAddress)
+		return
+	}
+	log.Printf("发现新交易对: %s/%s on %s (%s)",
+		token0.Symbol, token1.Symbol, dexInfo.Name, pair.PairAddress)
+}
+
+// orderPairTokens 按链上 token0/token1 顺序排列代币
+// 优先读取池子合约的 token0()；V4 没有独立的池子合约，读取失败时按地址排序（与链上规则一致）
+func (c *Collector) orderPairTokens(pair *models.TradingPair, tokenA, tokenB *models.Token) (*models.Token, *models.Token) {
+	if pair.PoolVersion != "v4" {
+		chainToken0, err := c.web3Client.GetTokenFromPair(pair.PairAddress, 0)
+		if err == nil {
+			if strings.EqualFold(chainToken0, tokenB.Address) {
+				return tokenB, tokenA
 			}
+			return tokenA, tokenB
 		}
+		log.Printf("⚠️  读取 token0 失败 %s: %v（按地址排序）", pair.PairAddress, err)
 	}
 
-	return nil
+	if strings.ToLower(tokenB.Address) < strings.ToLower(tokenA.Address) {
+		return tokenB, tokenA
+	}
+	return tokenA, tokenB
+}
+
+// v3FeeTiers 获取 V3 DEX 需要遍历的费率层级
+func v3FeeTiers(dexInfo *models.Dex) []uint32 {
+	if len(dexInfo.FeeTiers) > 0 {
+		return dexInfo.FeeTiers
+	}
+	return []uint32{dexInfo.FeeTier}
+}
+
+// inspectV3Pool 批量读取池子状态（token0/token1/fee/tickSpacing/slot0/liquidity 合并为一次 HTTP 请求）
+// 返回池子的 tick 间距和池子自身的 fee()；池子已创建但未初始化价格时返回 false。
+// 报价和定价使用池子自身的费率，不依赖 DEX 配置的费率层级；读取失败时回退到发现时使用的费率层级和默认 tick 间距
+func (c *Collector) inspectV3Pool(dexInfo *models.Dex, poolAddress string, feeTier uint32) (int32, uint32, bool) {
+	// Kyber Elastic 没有 slot0，初始化已在 GetPairAddress 中检查，只需读取 tick 间距
+	if dexInfo.Protocol == "kyber_elastic" {
+		tickDistance, err := c.web3Client.GetKyberElasticTickDistance(poolAddress)
+		if err != nil {
+			log.Printf("⚠️  读取 tickDistance 失败 %s: %v", poolAddress, err)
+		}
+		return tickDistance, feeTier, true
+	}
+
+	state, err := c.web3Client.GetV3PoolState(poolAddress)
+	if err != nil {
+		log.Printf("⚠️  读取池子状态失败 %s: %v（使用默认 tickSpacing）", poolAddress, err)
+		return web3.DefaultTickSpacing(feeTier), feeTier, true
+	}
+
+	if state.SqrtPriceX96.Sign() == 0 {
+		return 0, 0, false
+	}
+
+	if state.Fee != feeTier {
+		log.Printf("⚠️  池子 %s 的链上费率 %d 与发现时的费率层级 %d 不一致，使用链上费率", poolAddress, state.Fee, feeTier)
+	}
+	return state.TickSpacing, state.Fee, true
+}
+
+// cacheAvailable 缓存是否可用（未配置或 Redis 熔断时返回 false）
+func (c *Collector) cacheAvailable() bool {
+	return c.cache != nil && c.cache.Available()
+}
+
+// createProtocol 根据 DEX 配置创建协议适配器
+func (c *Collector) createProtocol(dexInfo *models.Dex) (dex.Protocol, error) {
+	return c.protocolFactory.CreateProtocolWithOptions(dexInfo.Protocol, dex.ProtocolOptions{
+		ReserveStyle: dexInfo.ReserveStyle,
+		PoolManager:  dexInfo.FactoryAddress, // V4 的 factory_address 配置为 PoolManager
+	})
 }
 
 // GetPairAddress 获取交易对地址
@@ -202,34 +486,61 @@ func (c *Collector) CalculatePrice(reserve0, reserve1 *big.Int, decimals0, decim
 	return price, inversePrice
 }
 
-// CleanupOldData 清理过期数据
-func (c *Collector) CleanupOldData(keepDays int) error {
-	db := database.GetDB()
+// CleanupOldData 清理过期数据（分批删除，ctx 取消后在批次之间停止）
+func (c *Collector) CleanupOldData(ctx context.Context, keepDays int) error {
+	db := database.GetDB().WithContext(ctx)
 
 	cutoffTime := time.Now().AddDate(0, 0, -keepDays)
 
 	log.Printf("清理 %d 天前的历史数据...", keepDays)
 
 	// 清理过期的储备量记录
-	result := db.Where("timestamp < ?", cutoffTime).Delete(&models.PairReserve{})
-	if result.Error != nil {
-		return fmt.Errorf("清理储备量记录失败: %w", result.Error)
+	deleted, err := deleteInBatches(ctx, db, &models.PairReserve{}, "timestamp < ?", cutoffTime)
+	if err != nil {
+		return fmt.Errorf("清理储备量记录失败: %w", err)
 	}
-	log.Printf("清理了 %d 条储备量记录", result.RowsAffected)
+	log.Printf("清理了 %d 条储备量记录", deleted)
 
 	// 清理过期的价格记录
-	result = db.Where("timestamp < ?", cutoffTime).Delete(&models.PriceRecord{})
-	if result.Error != nil {
-		return fmt.Errorf("清理价格记录失败: %w", result.Error)
+	deleted, err = deleteInBatches(ctx, db, &models.PriceRecord{}, "timestamp < ?", cutoffTime)
+	if err != nil {
+		return fmt.Errorf("清理价格记录失败: %w", err)
 	}
-	log.Printf("清理了 %d 条价格记录", result.RowsAffected)
+	log.Printf("清理了 %d 条价格记录", deleted)
 
 	// 清理过期的套利机会
-	result = db.Where("expires_at < ?", time.Now()).Delete(&models.ArbitrageOpportunity{})
-	if result.Error != nil {
-		return fmt.Errorf("清理套利机会失败: %w", result.Error)
+	deleted, err = deleteInBatches(ctx, db, &models.ArbitrageOpportunity{}, "expires_at < ?", time.Now())
+	if err != nil {
+		return fmt.Errorf("清理套利机会失败: %w", err)
 	}
-	log.Printf("清理了 %d 条过期的套利机会", result.RowsAffected)
+	log.Printf("清理了 %d 条过期的套利机会", deleted)
 
 	return nil
 }
+
+// cleanupBatchSize 清理历史数据时单条 DELETE 删除的最大行数
+const cleanupBatchSize = 10000
+
+// deleteInBatches 分批删除满足条件的记录
+// 大表一次性 DELETE 会长时间持有锁，且停机时无法中断；分批删除并在批次之间检查 ctx
+func deleteInBatches(ctx context.Context, db *gorm.DB, model interface{}, query string, args ...interface{}) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		batch := db.Session(&gorm.Session{NewDB: true}).Model(model).
+			Select("id").Where(query, args...).Limit(cleanupBatchSize)
+		result := db.Session(&gorm.Session{NewDB: true}).
+			Where("id IN (?)", batch).Delete(model)
+		if result.Error != nil {
+			return total, result.Error
+		}
+
+		total += result.RowsAffected
+		if result.RowsAffected < cleanupBatchSize {
+			return total, nil
+		}
+	}
+}
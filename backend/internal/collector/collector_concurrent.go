@@ -1,13 +1,20 @@
 package collector
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math/big"
 	"sync"
 	"time"
 
 	"github.com/defi-bot/backend/internal/database"
 	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/internal/repository"
+	"github.com/defi-bot/backend/pkg/cache"
+	"github.com/defi-bot/backend/pkg/dex"
+	"github.com/ethereum/go-ethereum/core/types"
 	"gorm.io/gorm"
 )
 
@@ -29,20 +36,23 @@ type PriceData struct {
 	SqrtPriceX96 string
 	Tick         int32
 	Liquidity    string
+	Fee          uint32 // 采集时的池子费率
 
 	// === 元数据 ===
 	BlockNumber uint64
+	BlockHash   string
 	Timestamp   time.Time
 }
 
 // CollectPricesConcurrent 并发采集价格数据
-func (c *Collector) CollectPricesConcurrent(blockNumber uint64) error {
-	db := database.GetDB()
+// 区块哈希会随价格记录一起保存，用于事后检测链重组；记录时间使用区块时间戳
+func (c *Collector) CollectPricesConcurrent(ctx context.Context, header *types.Header) error {
+	db := database.GetDB().WithContext(ctx)
 
 	// 获取所有活跃的交易对
 	var pairs []models.TradingPair
 	if err := db.Preload("Token0").Preload("Token1").Preload("Dex").
-		Where("is_active = ?", true).Find(&pairs).Error; err != nil {
+		Where("is_active = ?", true).Scopes(excludeHoneypotPairs, activeDexPairs).Find(&pairs).Error; err != nil {
 		return fmt.Errorf("查询交易对失败: %w", err)
 	}
 
@@ -51,6 +61,81 @@ func (c *Collector) CollectPricesConcurrent(blockNumber uint64) error {
 		return nil
 	}
 
+	return c.collectPrices(ctx, pairs, header, true)
+}
+
+// CollectV3Prices 快速采集 V3/V4 池的价格（每个池只读取 slot0 + liquidity 两次调用）
+// 价格、SqrtPriceX96、Tick、Liquidity 写入 PriceRecord；不读缓存，保证每次都是最新链上状态。
+// 昂贵的 QuoterV2 深度探测（CollectV3Depths）由调度器按更慢的频率单独执行
+func (c *Collector) CollectV3Prices(ctx context.Context) error {
+	header, err := c.web3Client.WithContext(ctx).GetLatestHeader()
+	if err != nil {
+		return fmt.Errorf("获取区块号失败: %w", err)
+	}
+
+	db := database.GetDB().WithContext(ctx)
+
+	var pairs []models.TradingPair
+	if err := db.Preload("Token0").Preload("Token1").Preload("Dex").
+		Where("is_active = ? AND pool_version IN ?", true, []string{"v3", "v4"}).
+		Scopes(excludeHoneypotPairs, activeDexPairs).Find(&pairs).Error; err != nil {
+		return fmt.Errorf("查询V3交易对失败: %w", err)
+	}
+
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	return c.collectPrices(ctx, pairs, header, false)
+}
+
+// CollectSinglePair 立即刷新单个交易对（用于 API 手动刷新或检测到 Swap 后的事件驱动刷新）
+// 不读缓存，直接查询链上数据，结果写入缓存和数据库；交易对不存在时返回包装了 gorm.ErrRecordNotFound 的错误
+func (c *Collector) CollectSinglePair(ctx context.Context, pairID uint) (*PriceData, error) {
+	var pair models.TradingPair
+	err := database.GetDB().WithContext(ctx).
+		Preload("Token0").Preload("Token1").Preload("Dex").
+		First(&pair, pairID).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询交易对 %d 失败: %w", pairID, err)
+	}
+
+	if !pair.IsActive {
+		return nil, fmt.Errorf("%w: %d", ErrPairInactive, pairID)
+	}
+	if !c.isDexActive(pair.DexID) {
+		return nil, fmt.Errorf("交易对 %d 的 %w: %s", pairID, ErrDexInactive, pair.Dex.Name)
+	}
+
+	header, err := c.web3Client.WithContext(ctx).GetLatestHeader()
+	if err != nil {
+		return nil, fmt.Errorf("获取区块号失败: %w", err)
+	}
+
+	data, err := c.fetchPairDataWithRetry(ctx, pair, header.Number.Uint64(), header.Hash().Hex(), blockTime(header), false)
+	if err != nil {
+		return nil, fmt.Errorf("采集 %s/%s 失败: %w", pair.Token0.Symbol, pair.Token1.Symbol, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := c.saveResults([]*PriceData{data}); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ 手动刷新: %s/%s @ %s - Price: %s (区块 %d)",
+		data.Token0Symbol, data.Token1Symbol, data.DexName,
+		data.Price[:min(15, len(data.Price))], data.BlockNumber)
+	return data, nil
+}
+
+// collectPrices 并发采集指定交易对的价格并批量写入
+// useCache 为 false 时跳过缓存读取，直接查询链上数据；
+// ctx 取消后尚未开始的交易对直接跳过，已采集的结果仍会写入
+func (c *Collector) collectPrices(ctx context.Context, pairs []models.TradingPair, header *types.Header, useCache bool) error {
+	pairs = c.filterActiveDexPairs(pairs)
 	log.Printf("开始并发采集 %d 个交易对的价格数据...", len(pairs))
 	startTime := time.Now()
 
@@ -62,7 +147,9 @@ func (c *Collector) CollectPricesConcurrent(blockNumber uint64) error {
 	resultsChan := make(chan *PriceData, len(pairs))
 	errorsChan := make(chan error, len(pairs))
 
-	timestamp := time.Now()
+	blockNumber := header.Number.Uint64()
+	blockHash := header.Hash().Hex()
+	timestamp := blockTime(header)
 
 	// 并发采集
 	for _, pair := range pairs {
@@ -74,8 +161,12 @@ func (c *Collector) CollectPricesConcurrent(blockNumber uint64) error {
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
+			if ctx.Err() != nil {
+				return
+			}
+
 			// 采集数据（带重试）
-			data, err := c.fetchPairDataWithRetry(p, blockNumber, timestamp)
+			data, err := c.fetchPairDataWithRetry(ctx, p, blockNumber, blockHash, timestamp, useCache)
 			if err != nil {
 				errorsChan <- fmt.Errorf("采集 %s/%s 失败: %w", p.Token0.Symbol, p.Token1.Symbol, err)
 				return
@@ -98,23 +189,23 @@ func (c *Collector) CollectPricesConcurrent(blockNumber uint64) error {
 	duration := time.Since(startTime)
 	log.Printf("并发采集完成，耗时: %v", duration)
 
+	if err == nil {
+		err = ctx.Err()
+	}
 	return err
 }
 
-// fetchPairDataWithRetry 带重试的数据采集
-func (c *Collector) fetchPairDataWithRetry(pair models.TradingPair, blockNumber uint64, timestamp time.Time) (*PriceData, error) {
-	// 尝试从缓存获取
-	if c.cache != nil {
+// fetchPairDataWithRetry 带重试的数据采集（ctx 取消后不再重试）
+func (c *Collector) fetchPairDataWithRetry(ctx context.Context, pair models.TradingPair, blockNumber uint64, blockHash string, timestamp time.Time, useCache bool) (*PriceData, error) {
+	// 尝试从缓存获取（Redis 熔断期间直接跳过）
+	// rebase 代币的余额不经转账变化，缓存的储备量不可靠，始终读取链上最新状态
+	if useCache && !pair.HasRebasingToken() && c.cacheAvailable() {
 		cacheKey := fmt.Sprintf("price:%s", pair.PairAddress)
 		var cachedData PriceData
-		if err := c.cache.Get(cacheKey, &cachedData); err == nil {
-			// 检查缓存是否过期（60秒内有效）
-			if time.Since(cachedData.Timestamp) < 60*time.Second {
-				log.Printf("🔥 从缓存获取: %s/%s @ %s", pair.Token0.Symbol, pair.Token1.Symbol, pair.Dex.Name)
-				cachedData.BlockNumber = blockNumber // 更新区块号
-				cachedData.Timestamp = timestamp     // 更新时间戳
-				return &cachedData, nil
-			}
+		// 只复用同一区块的缓存：其他区块的储备量不能标记为本区块的数据（否则重组检测会误判）
+		if err := c.cache.Get(cacheKey, &cachedData); err == nil && blockHash != "" && cachedData.BlockHash == blockHash {
+			c.logSuccess("🔥 从缓存获取: %s/%s @ %s", pair.Token0.Symbol, pair.Token1.Symbol, pair.Dex.Name)
+			return &cachedData, nil
 		}
 	}
 
@@ -122,31 +213,70 @@ func (c *Collector) fetchPairDataWithRetry(pair models.TradingPair, blockNumber
 	var lastErr error
 
 	// 获取协议适配器
-	protocol, err := c.protocolFactory.CreateProtocol(pair.Dex.Protocol)
+	protocol, err := c.createProtocol(&pair.Dex)
 	if err != nil {
 		return nil, fmt.Errorf("获取协议适配器失败: %w", err)
 	}
 
 	for i := 0; i < maxRetries; i++ {
 		// 使用协议适配器获取价格信息
-		priceInfo, err := protocol.GetPrice(pair.PairAddress)
+		var priceInfo *dex.PriceInfo
+		if c.pinSnapshot && blockNumber > 0 {
+			priceInfo, err = dex.GetPriceAtBlock(protocol, pair.PairAddress, blockNumber)
+		} else {
+			priceInfo, err = protocol.GetPrice(pair.PairAddress)
+		}
 		if err != nil {
+			// 池子状态导致的错误重试也不会成功
+			if errors.Is(err, dex.ErrNoLiquidity) || errors.Is(err, dex.ErrInvalidPrice) {
+				return nil, err
+			}
 			lastErr = err
-			time.Sleep(time.Millisecond * 100 * time.Duration(i+1)) // 指数退避
+			select {
+			case <-time.After(time.Millisecond * 100 * time.Duration(i+1)): // 指数退避
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
 			continue
 		}
 
 		// 检查流动性
 		if priceInfo.Reserve0.Sign() == 0 || priceInfo.Reserve1.Sign() == 0 {
-			return nil, fmt.Errorf("无流动性")
+			return nil, dex.ErrNoLiquidity
+		}
+
+		// 记录的代币顺序与链上相反（未修正的老数据）：储备量换算到记录的顺序
+		reversed := pair.TokensReversed()
+		if reversed {
+			priceInfo.Reserve0, priceInfo.Reserve1 = priceInfo.Reserve1, priceInfo.Reserve0
 		}
 
 		// 计算价格（考虑精度调整）
+		// 放大池（Kyber Classic）按虚拟储备量定价，实际储备量只用于记录
+		priceReserve0, priceReserve1 := priceInfo.Reserve0, priceInfo.Reserve1
+		if priceInfo.VirtualReserve0 != nil && priceInfo.VirtualReserve1 != nil {
+			priceReserve0, priceReserve1 = priceInfo.VirtualReserve0, priceInfo.VirtualReserve1
+			if reversed {
+				priceReserve0, priceReserve1 = priceReserve1, priceReserve0
+			}
+		}
 		price, inversePrice := c.CalculatePrice(
-			priceInfo.Reserve0, priceInfo.Reserve1,
+			priceReserve0, priceReserve1,
 			pair.Token0.Decimals, pair.Token1.Decimals,
 		)
 
+		// V3 池的虚拟储备量是近似值，直接用 sqrtPriceX96 计算精确价格，保证与 V2 价格可比
+		// sqrtPriceX96 始终是链上 token1/token0，顺序相反时取倒数
+		if priceInfo.SqrtPriceX96 != nil && priceInfo.SqrtPriceX96.Sign() > 0 {
+			if reversed {
+				inversePrice = dex.SqrtPriceX96ToPriceAdjusted(priceInfo.SqrtPriceX96, pair.Token1.Decimals, pair.Token0.Decimals)
+				price = new(big.Float).Quo(big.NewFloat(1), inversePrice)
+			} else {
+				price = dex.SqrtPriceX96ToPriceAdjusted(priceInfo.SqrtPriceX96, pair.Token0.Decimals, pair.Token1.Decimals)
+				inversePrice = new(big.Float).Quo(big.NewFloat(1), price)
+			}
+		}
+
 		// 构造价格数据
 		priceData := &PriceData{
 			PairID:       pair.ID,
@@ -159,20 +289,32 @@ func (c *Collector) fetchPairDataWithRetry(pair models.TradingPair, blockNumber
 			Price:        price.String(),
 			InversePrice: inversePrice.String(),
 			BlockNumber:  blockNumber,
+			BlockHash:    blockHash,
 			Timestamp:    timestamp,
 		}
 
 		// === ✅ V3 数据（如果是V3池）===
+		// 与储备量和价格一致按记录的代币顺序保存：顺序相反时 sqrtPriceX96 取倒数、tick 取反
 		if pair.Dex.SupportV3Ticks && priceInfo.SqrtPriceX96 != nil {
-			priceData.SqrtPriceX96 = priceInfo.SqrtPriceX96.String()
-			priceData.Tick = priceInfo.Tick
+			sqrtPriceX96, tick := priceInfo.SqrtPriceX96, priceInfo.Tick
+			if reversed {
+				sqrtPriceX96, tick = dex.InvertSqrtPriceX96(sqrtPriceX96), -tick
+			}
+			priceData.SqrtPriceX96 = sqrtPriceX96.String()
+			priceData.Tick = tick
 			priceData.Liquidity = priceInfo.Liquidity.String()
+
+			// 固定费率池链上费率等于费率层级，动态费率池使用读取到的当前费率
+			priceData.Fee = priceInfo.Fee
+			if priceData.Fee == 0 {
+				priceData.Fee = pair.FeeTier()
+			}
 		}
 
 		// 缓存数据（5分钟过期）
-		if c.cache != nil {
+		if c.cacheAvailable() {
 			cacheKey := fmt.Sprintf("price:%s", pair.PairAddress)
-			if err := c.cache.Set(cacheKey, priceData, 5*time.Minute); err != nil {
+			if err := c.cache.Set(cacheKey, priceData, 5*time.Minute); err != nil && !errors.Is(err, cache.ErrCacheUnavailable) {
 				log.Printf("⚠️  缓存写入失败: %v", err)
 			}
 		}
@@ -185,80 +327,66 @@ func (c *Collector) fetchPairDataWithRetry(pair models.TradingPair, blockNumber
 
 // batchInsertResults 批量插入结果
 func (c *Collector) batchInsertResults(resultsChan chan *PriceData, errorsChan chan error) error {
-	db := database.GetDB()
-
-	reserves := make([]models.PairReserve, 0, 100)
-	prices := make([]models.PriceRecord, 0, 100)
+	results := make([]*PriceData, 0, 100)
 
 	successCount := 0
 	errorCount := 0
 
 	// 收集结果
 	for data := range resultsChan {
-		// === 储备量记录 ===
-		reserveRecord := models.PairReserve{
-			PairID:      data.PairID,
-			Reserve0:    data.Reserve0,
-			Reserve1:    data.Reserve1,
-			BlockNumber: data.BlockNumber,
-			Timestamp:   data.Timestamp,
-		}
+		results = append(results, data)
 
-		// V3 储备量附加数据
-		if data.SqrtPriceX96 != "" {
-			reserveRecord.SqrtPriceX96 = data.SqrtPriceX96
-			reserveRecord.Tick = data.Tick
-			reserveRecord.Liquidity = data.Liquidity
-		}
-
-		reserves = append(reserves, reserveRecord)
-
-		// === 价格记录 ===
-		priceRecord := models.PriceRecord{
-			PairID:       data.PairID,
-			Price:        data.Price,
-			InversePrice: data.InversePrice,
-			Reserve0:     data.Reserve0,
-			Reserve1:     data.Reserve1,
-			BlockNumber:  data.BlockNumber,
-			Timestamp:    data.Timestamp,
-		}
-
-		// ✅ V3 价格附加数据
-		if data.SqrtPriceX96 != "" {
-			priceRecord.SqrtPriceX96 = data.SqrtPriceX96
-			priceRecord.Tick = data.Tick
-			priceRecord.Liquidity = data.Liquidity
-			// fee_growth 字段保持为空（NULL），不赋值
-			// depth 字段保持为空（NULL），不赋值
-		}
-
-		prices = append(prices, priceRecord)
-
-		log.Printf("✅ 采集成功: %s/%s @ %s - Price: %s",
+		c.logSuccess("✅ 采集成功: %s/%s @ %s - Price: %s",
 			data.Token0Symbol, data.Token1Symbol, data.DexName,
 			data.Price[:min(15, len(data.Price))])
 
 		successCount++
 	}
 
-	// 收集错误
+	// 收集错误（按错误类型分类统计）
+	errorsByKind := make(map[string]int)
 	for err := range errorsChan {
 		log.Printf("⚠️  %v", err)
+		errorsByKind[classifyError(err)]++
 		errorCount++
 	}
 
-	log.Printf("采集统计: 成功=%d, 失败=%d", successCount, errorCount)
+	if errorCount > 0 {
+		log.Printf("采集统计: 成功=%d, 失败=%d, 失败分类=%v", successCount, errorCount, errorsByKind)
+	} else {
+		log.Printf("采集统计: 成功=%d, 失败=%d", successCount, errorCount)
+	}
 
 	// 批量插入（使用事务）
-	if len(reserves) == 0 {
+	if len(results) == 0 {
 		log.Println("没有数据需要写入")
 		return nil
 	}
 
-	log.Printf("开始批量写入 %d 条记录...", len(reserves))
+	log.Printf("开始批量写入 %d 条记录...", len(results))
+
+	if err := c.saveResults(results); err != nil {
+		return err
+	}
+
+	log.Printf("✅ 批量写入完成: %d 条储备量, %d 条价格记录", len(results), len(results))
+	return nil
+}
+
+// saveResults 在一个事务中写入储备量、价格记录并更新交易对最新状态
+func (c *Collector) saveResults(results []*PriceData) error {
+	reserves := make([]models.PairReserve, 0, len(results))
+	prices := make([]models.PriceRecord, 0, len(results))
+	latest := make([]models.PairLatest, 0, len(results))
+
+	for _, data := range results {
+		reserve, price, current := buildRecords(data)
+		reserves = append(reserves, reserve)
+		prices = append(prices, price)
+		latest = append(latest, current)
+	}
 
-	err := db.Transaction(func(tx *gorm.DB) error {
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
 		// 批量插入储备量（每次1000条）
 		batchSize := 1000
 		for i := 0; i < len(reserves); i += batchSize {
@@ -282,21 +410,67 @@ func (c *Collector) batchInsertResults(resultsChan chan *PriceData, errorsChan c
 			}
 		}
 
-		return nil
+		// 同步交易对最新状态
+		return repository.NewPairLatestRepository(tx).Upsert(latest)
 	})
 
 	if err != nil {
 		return fmt.Errorf("数据库写入失败: %w", err)
 	}
-
-	log.Printf("✅ 批量写入完成: %d 条储备量, %d 条价格记录", len(reserves), len(prices))
 	return nil
 }
 
-// min 返回两个整数中的最小值
-func min(a, b int) int {
-	if a < b {
-		return a
+// buildRecords 将采集结果转换为储备量、价格和最新状态记录
+func buildRecords(data *PriceData) (models.PairReserve, models.PriceRecord, models.PairLatest) {
+	// === 储备量记录 ===
+	reserveRecord := models.PairReserve{
+		PairID:      data.PairID,
+		Reserve0:    data.Reserve0,
+		Reserve1:    data.Reserve1,
+		BlockNumber: data.BlockNumber,
+		Timestamp:   data.Timestamp,
 	}
-	return b
+
+	// === 价格记录 ===
+	priceRecord := models.PriceRecord{
+		PairID:       data.PairID,
+		Price:        data.Price,
+		InversePrice: data.InversePrice,
+		Reserve0:     data.Reserve0,
+		Reserve1:     data.Reserve1,
+		BlockNumber:  data.BlockNumber,
+		BlockHash:    data.BlockHash,
+		Timestamp:    data.Timestamp,
+	}
+
+	// V3 附加数据
+	if data.SqrtPriceX96 != "" {
+		reserveRecord.SqrtPriceX96 = data.SqrtPriceX96
+		reserveRecord.Tick = data.Tick
+		reserveRecord.Liquidity = data.Liquidity
+
+		priceRecord.SqrtPriceX96 = data.SqrtPriceX96
+		priceRecord.Tick = data.Tick
+		priceRecord.Liquidity = data.Liquidity
+		priceRecord.Fee = data.Fee
+		// fee_growth 字段保持为空（NULL），不赋值
+		// depth 字段保持为空（NULL），不赋值
+	}
+
+	// === 最新状态 ===
+	latest := models.PairLatest{
+		PairID:       data.PairID,
+		Reserve0:     data.Reserve0,
+		Reserve1:     data.Reserve1,
+		Price:        data.Price,
+		InversePrice: data.InversePrice,
+		SqrtPriceX96: data.SqrtPriceX96,
+		Tick:         data.Tick,
+		Liquidity:    data.Liquidity,
+		BlockNumber:  data.BlockNumber,
+		BlockHash:    data.BlockHash,
+		Timestamp:    data.Timestamp,
+	}
+
+	return reserveRecord, priceRecord, latest
 }
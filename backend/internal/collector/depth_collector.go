@@ -1,19 +1,46 @@
 package collector
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math/big"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/defi-bot/backend/internal/database"
 	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/pkg/dex"
+	"github.com/defi-bot/backend/pkg/units"
+	"github.com/defi-bot/backend/pkg/web3"
+	"gorm.io/gorm"
 )
 
+// defaultDepthConcurrency 默认同时探测的池子数
+// 每个池子 8 次 Quoter 调用（4 个金额 × 2 个方向），并发过高容易触发 RPC 限流
+const defaultDepthConcurrency = 4
+
+// SetDepthConcurrency 设置深度采集同时探测的池子数（<= 0 时使用默认值）
+func (c *Collector) SetDepthConcurrency(n int) {
+	if n <= 0 {
+		n = defaultDepthConcurrency
+	}
+	c.depthConcurrency = n
+}
+
+// SetDepthFeeTiers 设置深度采集探测的费率层级（为空时探测所有已发现的费率层级池子）
+// 同一代币对的每个费率层级是独立的交易对，深度按池子分别采集并标记费率层级
+func (c *Collector) SetDepthFeeTiers(feeTiers []uint32) {
+	c.depthFeeTiers = feeTiers
+}
+
 // CollectV3Depths 采集 V3 流动性深度数据
-// 这是业界标准的深度采集方法：使用 QuoterV2 模拟不同金额的交换
-func (c *Collector) CollectV3Depths() error {
-	db := database.GetDB()
+// 这是业界标准的深度采集方法：使用 QuoterV2 模拟不同金额的交换。
+// 池子之间由固定数量的 worker 并发探测，每个池子的结果单独批量写入；
+// ctx 取消后不再开始新的池子，进行中的 Quoter 调用随 ctx 一起取消
+func (c *Collector) CollectV3Depths(ctx context.Context) error {
+	db := database.GetDB().WithContext(ctx)
 
 	// 获取所有 V3 交易对
 	var pairs []models.TradingPair
@@ -21,14 +48,16 @@ func (c *Collector) CollectV3Depths() error {
 		Preload("Token1").
 		Preload("Dex").
 		Joins("JOIN dexes ON dexes.id = trading_pairs.dex_id").
-		Where("dexes.support_v3_ticks = ? AND dexes.quoter_address != ? AND trading_pairs.is_active = ?",
-			true, "", true).
+		Where("dexes.support_v3_ticks = ? AND dexes.quoter_address != ? AND dexes.is_active = ? AND trading_pairs.is_active = ?",
+			true, "", true, true).
 		Find(&pairs).Error
 
 	if err != nil {
 		return fmt.Errorf("查询V3交易对失败: %w", err)
 	}
 
+	pairs = c.filterDepthFeeTiers(pairs)
+
 	if len(pairs) == 0 {
 		log.Println("没有V3交易对需要采集深度")
 		return nil
@@ -38,126 +67,245 @@ func (c *Collector) CollectV3Depths() error {
 
 	// 定义测试金额（业界标准）
 	testAmounts := []*big.Int{
-		parseEther("0.1"), // 0.1 ETH - 小额交易
-		parseEther("1"),   // 1 ETH - 中等交易
-		parseEther("10"),  // 10 ETH - 大额交易
-		parseEther("100"), // 100 ETH - 巨额交易
+		units.MustParseUnits("0.1", units.EtherDecimals), // 0.1 ETH - 小额交易
+		units.MustParseUnits("1", units.EtherDecimals),   // 1 ETH - 中等交易
+		units.MustParseUnits("10", units.EtherDecimals),  // 10 ETH - 大额交易
+		units.MustParseUnits("100", units.EtherDecimals), // 100 ETH - 巨额交易
 	}
 
-	blockNumber, _ := c.web3Client.GetBlockNumber()
-	timestamp := time.Now()
-
-	totalDepths := 0
+	header, err := c.web3Client.WithContext(ctx).GetLatestHeader()
+	if err != nil {
+		return fmt.Errorf("获取区块号失败: %w", err)
+	}
+	blockNumber := header.Number.Uint64()
+	timestamp := blockTime(header)
 
-	// 逐个采集
-	for _, pair := range pairs {
+	startTime := time.Now()
+	totalDepths := runDepthWorkers(ctx, pairs, c.depthConcurrency, func(pair models.TradingPair) int {
 		if pair.Dex.QuoterAddress == "" {
-			continue
+			return 0
 		}
+		return c.collectAndSaveDepth(ctx, db, pair, testAmounts, blockNumber, timestamp)
+	})
 
-		depths, err := c.collectPairDepth(pair, testAmounts, blockNumber, timestamp)
-		if err != nil {
-			log.Printf("⚠️  采集深度失败 %s/%s @ %s: %v",
-				pair.Token0.Symbol, pair.Token1.Symbol, pair.Dex.Name, err)
-			continue
-		}
+	if err := ctx.Err(); err != nil {
+		log.Printf("⚠️  深度采集已取消: 已写入 %d 条记录", totalDepths)
+		return err
+	}
 
-		// 批量插入
-		if len(depths) > 0 {
-			if err := db.CreateInBatches(depths, 100).Error; err != nil {
-				log.Printf("⚠️  写入深度数据失败: %v", err)
-			} else {
-				totalDepths += len(depths)
-				log.Printf("✅ 采集深度: %s/%s @ %s - %d 个测试点",
-					pair.Token0.Symbol, pair.Token1.Symbol, pair.Dex.Name, len(depths))
+	log.Printf("✅ 深度采集完成: 共 %d 条记录，耗时 %v", totalDepths, time.Since(startTime))
+	return nil
+}
+
+// runDepthWorkers 由固定数量的 worker 并发对每个池子执行 probe，返回各次 probe 返回值之和
+// ctx 取消后不再分发新的池子；返回前等待所有 worker 退出，返回后不会再有 probe 在执行
+func runDepthWorkers(ctx context.Context, pairs []models.TradingPair, concurrency int, probe func(models.TradingPair) int) int64 {
+	if concurrency <= 0 {
+		concurrency = defaultDepthConcurrency
+	}
+	concurrency = min(concurrency, len(pairs))
+
+	var total atomic.Int64
+	var wg sync.WaitGroup
+	pairsChan := make(chan models.TradingPair)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pair := range pairsChan {
+				total.Add(int64(probe(pair)))
 			}
+		}()
+	}
+
+dispatch:
+	for _, pair := range pairs {
+		// 已取消时优先退出，不与空闲 worker 的接收随机竞争
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case pairsChan <- pair:
+		case <-ctx.Done():
+			break dispatch
 		}
 	}
+	close(pairsChan)
+	wg.Wait()
 
-	log.Printf("✅ 深度采集完成: 共 %d 条记录", totalDepths)
-	return nil
+	return total.Load()
+}
+
+// collectAndSaveDepth 探测单个池子的深度并批量写入，返回写入的记录数
+func (c *Collector) collectAndSaveDepth(
+	ctx context.Context,
+	db *gorm.DB,
+	pair models.TradingPair,
+	testAmounts []*big.Int,
+	blockNumber uint64,
+	timestamp time.Time,
+) int {
+	if ctx.Err() != nil {
+		return 0
+	}
+
+	depths, err := c.collectPairDepth(ctx, pair, testAmounts, blockNumber, timestamp)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("⚠️  采集深度失败 %s/%s @ %s (fee %d): %v",
+				pair.Token0.Symbol, pair.Token1.Symbol, pair.Dex.Name, pair.FeeTier(), err)
+		}
+		return 0
+	}
+
+	// 探测期间收到停止信号：丢弃结果，关闭后不再写库
+	if len(depths) == 0 || ctx.Err() != nil {
+		return 0
+	}
+
+	// 批量插入
+	if err := db.CreateInBatches(depths, 100).Error; err != nil {
+		log.Printf("⚠️  写入深度数据失败: %v", err)
+		return 0
+	}
+
+	log.Printf("✅ 采集深度: %s/%s @ %s (fee %d) - %d 个测试点",
+		pair.Token0.Symbol, pair.Token1.Symbol, pair.Dex.Name, pair.FeeTier(), len(depths))
+	return len(depths)
+}
+
+// filterDepthFeeTiers 只保留配置中需要探测的费率层级池子
+func (c *Collector) filterDepthFeeTiers(pairs []models.TradingPair) []models.TradingPair {
+	if len(c.depthFeeTiers) == 0 {
+		return pairs
+	}
+
+	allowed := make(map[uint32]bool, len(c.depthFeeTiers))
+	for _, feeTier := range c.depthFeeTiers {
+		allowed[feeTier] = true
+	}
+
+	filtered := pairs[:0]
+	for _, pair := range pairs {
+		if allowed[pair.FeeTier()] {
+			filtered = append(filtered, pair)
+		}
+	}
+	return filtered
 }
 
 // collectPairDepth 采集单个交易对的深度数据
 func (c *Collector) collectPairDepth(
+	ctx context.Context,
 	pair models.TradingPair,
 	testAmounts []*big.Int,
 	blockNumber uint64,
 	timestamp time.Time,
 ) ([]models.LiquidityDepth, error) {
 	depths := make([]models.LiquidityDepth, 0, len(testAmounts)*2)
+	feeTier := pair.FeeTier()
+
+	// Quoter 类型无效时直接报错，避免每个测试点都静默失败
+	if err := web3.ValidateQuoterType(pair.Dex.QuoterType); err != nil {
+		return nil, err
+	}
 
 	// 获取当前价格（用于计算价格影响）
-	priceInfo, err := c.protocolFactory.CreateProtocol(pair.Dex.Protocol)
+	priceInfo, err := c.createProtocol(&pair.Dex)
 	if err != nil {
 		return nil, err
 	}
 
-	currentPriceInfo, err := priceInfo.GetPrice(pair.PairAddress)
+	// 固定快照区块时，价格和报价都读取同一区块，报价缓存也按区块区分
+	quoter := c.web3Client.WithContext(ctx)
+	var currentPriceInfo *dex.PriceInfo
+	if c.pinSnapshot {
+		quoter = quoter.AtBlock(blockNumber)
+		currentPriceInfo, err = dex.GetPriceAtBlock(priceInfo, pair.PairAddress, blockNumber)
+	} else {
+		currentPriceInfo, err = priceInfo.GetPrice(pair.PairAddress)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// 对每个测试金额，查询两个方向的深度
-	for _, amount := range testAmounts {
-		// ===  方向1: token0 → token1 ===
-		result0to1, err := c.web3Client.QuoteExactInputSingle(
-			pair.Dex.QuoterAddress,
-			pair.Token0.Address,
-			pair.Token1.Address,
-			amount,
-			pair.Dex.FeeTier,
-		)
-
-		if err == nil && result0to1.AmountOut.Sign() > 0 {
-			priceImpact := c.web3Client.CalculatePriceImpact(
-				currentPriceInfo.SqrtPriceX96,
-				result0to1.SqrtPriceX96After,
-			)
-
-			slippageBps := uint32(priceImpact * 100) // 转换为基点
-
-			executionPrice := calculateExecutionPrice(amount, result0to1.AmountOut, pair.Token0.Decimals, pair.Token1.Decimals)
+	// 两个方向分别按金额从小到大采集；更小的金额成功后出现的 revert 视为超出池子流动性，
+	// 记录一个边界点后不再查询更大的金额（见 web3.QuoteDepthCurve）
+	directions := []struct {
+		name     string
+		tokenIn  models.Token
+		tokenOut models.Token
+	}{
+		{name: "token0_to_token1", tokenIn: pair.Token0, tokenOut: pair.Token1},
+		{name: "token1_to_token0", tokenIn: pair.Token1, tokenOut: pair.Token0},
+	}
 
-			depths = append(depths, models.LiquidityDepth{
-				PairID:         pair.ID,
-				AmountIn:       amount.String(),
-				AmountOut:      result0to1.AmountOut.String(),
-				PriceImpact:    priceImpact,
-				SlippageBps:    slippageBps,
-				Direction:      "token0_to_token1",
-				ExecutionPrice: executionPrice,
-				BlockNumber:    blockNumber,
-				Timestamp:      timestamp,
-			})
+	for _, dir := range directions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
 
-		// === 方向2: token1 → token0 ===
-		result1to0, err := c.web3Client.QuoteExactInputSingle(
+		results, err := quoter.QuoteDepthCurve(
+			pair.Dex.QuoterType,
 			pair.Dex.QuoterAddress,
-			pair.Token1.Address,
-			pair.Token0.Address,
-			amount,
-			pair.Dex.FeeTier,
+			dir.tokenIn.Address,
+			dir.tokenOut.Address,
+			feeTier,
+			testAmounts,
 		)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			if web3.IsRevert(err) {
+				// 最小金额就 revert：费率层级、Quoter 类型或池子状态有问题，不是流动性不足
+				log.Printf("❌ 深度报价 revert %s %s（检查费率层级 %d、Quoter 类型 %q、池子是否已初始化）: %v",
+					pair.PairAddress, dir.name, feeTier, pair.Dex.QuoterType, err)
+			} else {
+				log.Printf("⚠️  深度报价失败 %s %s: %v", pair.PairAddress, dir.name, err)
+			}
+			// 瞬时错误之前已成功的测试点仍然保存
+		}
+
+		for _, result := range results {
+			if result.ExceedsLiquidity {
+				depths = append(depths, models.LiquidityDepth{
+					PairID:           pair.ID,
+					AmountIn:         result.AmountIn.String(),
+					AmountOut:        "0",
+					PriceImpact:      100,
+					SlippageBps:      10000,
+					ExceedsLiquidity: true,
+					FeeTier:          feeTier,
+					Direction:        dir.name,
+					BlockNumber:      blockNumber,
+					Timestamp:        timestamp,
+				})
+				continue
+			}
+
+			if result.AmountOut.Sign() <= 0 {
+				continue
+			}
 
-		if err == nil && result1to0.AmountOut.Sign() > 0 {
 			priceImpact := c.web3Client.CalculatePriceImpact(
 				currentPriceInfo.SqrtPriceX96,
-				result1to0.SqrtPriceX96After,
+				result.SqrtPriceX96After,
 			)
 
-			slippageBps := uint32(priceImpact * 100)
+			slippageBps := uint32(priceImpact * 100) // 转换为基点
 
-			executionPrice := calculateExecutionPrice(amount, result1to0.AmountOut, pair.Token1.Decimals, pair.Token0.Decimals)
+			executionPrice := units.Price(result.AmountIn, result.AmountOut, dir.tokenIn.Decimals, dir.tokenOut.Decimals)
 
 			depths = append(depths, models.LiquidityDepth{
 				PairID:         pair.ID,
-				AmountIn:       amount.String(),
-				AmountOut:      result1to0.AmountOut.String(),
+				AmountIn:       result.AmountIn.String(),
+				AmountOut:      result.AmountOut.String(),
 				PriceImpact:    priceImpact,
 				SlippageBps:    slippageBps,
-				Direction:      "token1_to_token0",
+				FeeTier:        feeTier,
+				Direction:      dir.name,
 				ExecutionPrice: executionPrice,
 				BlockNumber:    blockNumber,
 				Timestamp:      timestamp,
@@ -167,41 +315,3 @@ func (c *Collector) collectPairDepth(
 
 	return depths, nil
 }
-
-// parseEther 将 ETH 数量转换为 wei
-func parseEther(eth string) *big.Int {
-	// 1 ETH = 1e18 wei
-	ethFloat, _ := new(big.Float).SetString(eth)
-	weiFloat := new(big.Float).Mul(ethFloat, big.NewFloat(1e18))
-	wei, _ := weiFloat.Int(nil)
-	return wei
-}
-
-// calculateExecutionPrice 计算实际成交价格
-func calculateExecutionPrice(amountIn, amountOut *big.Int, decimalsIn, decimalsOut int) string {
-	if amountIn.Sign() == 0 {
-		return "0"
-	}
-
-	// 调整精度
-	adjustedIn := new(big.Float).SetInt(amountIn)
-	adjustedOut := new(big.Float).SetInt(amountOut)
-
-	// 除以 10^decimals
-	adjustedIn.Quo(adjustedIn, big.NewFloat(pow10(decimalsIn)))
-	adjustedOut.Quo(adjustedOut, big.NewFloat(pow10(decimalsOut)))
-
-	// price = amountOut / amountIn
-	price := new(big.Float).Quo(adjustedOut, adjustedIn)
-
-	return price.String()
-}
-
-// pow10 计算 10^n
-func pow10(n int) float64 {
-	result := 1.0
-	for i := 0; i < n; i++ {
-		result *= 10
-	}
-	return result
-}
@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/defi-bot/backend/internal/models"
+)
+
+// benchmarkDepthPools 基准测试使用的 V3 池子数
+const benchmarkDepthPools = 60
+
+// simulatedQuoteLatency 模拟一次 Quoter eth_call 的 RPC 往返耗时
+const simulatedQuoteLatency = time.Millisecond
+
+func testDepthPairs(n int) []models.TradingPair {
+	pairs := make([]models.TradingPair, n)
+	for i := range pairs {
+		pairs[i].ID = uint(i + 1)
+	}
+	return pairs
+}
+
+// simulatedProbe 模拟单个池子的探测：4 个金额 × 2 个方向共 8 次 Quoter 调用，返回 8 个测试点
+func simulatedProbe(models.TradingPair) int {
+	for i := 0; i < 8; i++ {
+		time.Sleep(simulatedQuoteLatency)
+	}
+	return 8
+}
+
+// BenchmarkRunDepthWorkers 对比逐个池子顺序探测（workers=1）和 worker 池并发探测的耗时
+// 深度采集的耗时几乎全部是 RPC 往返，用固定延迟模拟
+func BenchmarkRunDepthWorkers(b *testing.B) {
+	pairs := testDepthPairs(benchmarkDepthPools)
+
+	for _, workers := range []int{1, 4, 8, 16} {
+		name := fmt.Sprintf("workers=%d", workers)
+		if workers == 1 {
+			name = "sequential"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				total := runDepthWorkers(context.Background(), pairs, workers, simulatedProbe)
+				if total != 8*benchmarkDepthPools {
+					b.Fatalf("测试点数 = %d, 期望 %d", total, 8*benchmarkDepthPools)
+				}
+			}
+		})
+	}
+}
+
+func TestRunDepthWorkersTotal(t *testing.T) {
+	pairs := testDepthPairs(benchmarkDepthPools)
+
+	for _, workers := range []int{0, 1, 4, benchmarkDepthPools * 2} {
+		total := runDepthWorkers(context.Background(), pairs, workers, func(pair models.TradingPair) int {
+			return int(pair.ID)
+		})
+		want := int64(benchmarkDepthPools * (benchmarkDepthPools + 1) / 2)
+		if total != want {
+			t.Errorf("workers=%d 时合计 = %d, 期望 %d", workers, total, want)
+		}
+	}
+}
+
+func TestRunDepthWorkersCancel(t *testing.T) {
+	const (
+		workers     = 4
+		cancelAfter = 10
+	)
+	pairs := testDepthPairs(benchmarkDepthPools)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var started, running, lateWrites atomic.Int64
+	var returned atomic.Bool
+
+	done := make(chan int64)
+	go func() {
+		done <- runDepthWorkers(ctx, pairs, workers, func(models.TradingPair) int {
+			running.Add(1)
+			defer running.Add(-1)
+
+			if started.Add(1) == cancelAfter {
+				cancel()
+			}
+			// 模拟进行中的 Quoter 调用：随 ctx 一起取消
+			select {
+			case <-ctx.Done():
+			case <-time.After(20 * time.Millisecond):
+			}
+			if returned.Load() {
+				lateWrites.Add(1)
+			}
+			return 1
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("取消后 runDepthWorkers 未在 5 秒内返回")
+	}
+	returned.Store(true)
+
+	if n := running.Load(); n != 0 {
+		t.Errorf("返回时仍有 %d 个 worker 在探测", n)
+	}
+	// 取消时每个 worker 最多已领取一个池子，之后不再分发
+	if n := started.Load(); n > cancelAfter+workers {
+		t.Errorf("取消后仍开始探测：共 %d 个池子, 期望不超过 %d", n, cancelAfter+workers)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if n := lateWrites.Load(); n != 0 {
+		t.Errorf("返回后仍有 %d 次写入", n)
+	}
+}
+
+func TestCollectAndSaveDepthCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// ctx 已取消时既不探测也不写库（db 为 nil，若写库会 panic）
+	c := &Collector{}
+	if n := c.collectAndSaveDepth(ctx, nil, models.TradingPair{}, nil, 1, time.Now()); n != 0 {
+		t.Errorf("已取消时写入 %d 条, 期望 0", n)
+	}
+}
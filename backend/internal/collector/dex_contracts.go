@@ -0,0 +1,110 @@
+package collector
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/models"
+)
+
+// zeroAddress 配置中表示"无"的零地址
+const zeroAddress = "0x0000000000000000000000000000000000000000"
+
+// DexContractCheck 单个周边合约的 Factory 校验结果
+type DexContractCheck struct {
+	DexID    uint   `json:"dex_id"`
+	DexName  string `json:"dex_name"`
+	Contract string `json:"contract"` // router / quoter
+	Address  string `json:"address"`
+	Expected string `json:"expected_factory"`
+	Actual   string `json:"actual_factory,omitempty"`
+	OK       bool   `json:"ok"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ValidateDexContracts 校验每个活跃 DEX 的 Router（以及 Quoter）是否属于配置的 Factory
+// Router 和 Factory 来自不同部署时，getPair 返回的地址看起来正常但与 Router 实际交易的池子不同，
+// 这里读取 Router / Quoter 的 factory() 与 factory_address 比对，在交易失败之前发现配置错误。
+// Uniswap V4 的 Router 没有 factory()，聚合器没有 Factory，均跳过
+func (c *Collector) ValidateDexContracts() ([]DexContractCheck, error) {
+	db := database.GetDB()
+
+	var dexes []models.Dex
+	if err := db.Where("is_active = ?", true).Order("id").Find(&dexes).Error; err != nil {
+		return nil, fmt.Errorf("查询 DEX 失败: %w", err)
+	}
+
+	checks := make([]DexContractCheck, 0, len(dexes))
+	for i := range dexes {
+		dexInfo := &dexes[i]
+		if isEmptyAddress(dexInfo.FactoryAddress) || dexInfo.Protocol == "uniswap_v4" {
+			continue
+		}
+
+		if !isEmptyAddress(dexInfo.RouterAddress) {
+			checks = append(checks, c.checkFactoryOf(dexInfo, "router", dexInfo.RouterAddress))
+		}
+		if c.protocolFactory.GetProtocolType(dexInfo.Protocol) == "v3" && dexInfo.SupportsQuoter() {
+			checks = append(checks, c.checkFactoryOf(dexInfo, "quoter", dexInfo.QuoterAddress))
+		}
+	}
+
+	return checks, nil
+}
+
+// LogDexContractChecks 执行校验并输出结果（启动时调用，校验失败不阻止启动）
+func (c *Collector) LogDexContractChecks() {
+	checks, err := c.ValidateDexContracts()
+	if err != nil {
+		log.Printf("⚠️  DEX 合约校验失败: %v", err)
+		return
+	}
+
+	failed := 0
+	for _, check := range checks {
+		if check.OK {
+			continue
+		}
+		failed++
+		if check.Error != "" {
+			log.Printf("⚠️  DEX %s 的 %s %s 无法校验: %s", check.DexName, check.Contract, check.Address, check.Error)
+		} else {
+			log.Printf("❌ DEX %s 的 %s %s 属于 Factory %s，与配置的 factory %s 不一致",
+				check.DexName, check.Contract, check.Address, check.Actual, check.Expected)
+		}
+	}
+
+	if failed == 0 {
+		log.Printf("✅ DEX 合约校验通过: %d 个合约", len(checks))
+	} else {
+		log.Printf("⚠️  DEX 合约校验: %d/%d 个合约未通过", failed, len(checks))
+	}
+}
+
+// checkFactoryOf 读取合约的 factory() 并与 DEX 配置比对
+func (c *Collector) checkFactoryOf(dexInfo *models.Dex, contract, address string) DexContractCheck {
+	check := DexContractCheck{
+		DexID:    dexInfo.ID,
+		DexName:  dexInfo.Name,
+		Contract: contract,
+		Address:  address,
+		Expected: dexInfo.FactoryAddress,
+	}
+
+	actual, err := c.web3Client.GetFactoryOf(address)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+
+	check.Actual = actual
+	check.OK = strings.EqualFold(actual, dexInfo.FactoryAddress)
+	return check
+}
+
+// isEmptyAddress 判断配置的地址是否为空
+func isEmptyAddress(address string) bool {
+	return address == "" || strings.EqualFold(address, zeroAddress)
+}
@@ -5,11 +5,12 @@ import (
 	"fmt"
 	"log"
 	"math/big"
-	"time"
 
 	"github.com/defi-bot/backend/internal/database"
 	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/pkg/units"
 	"github.com/defi-bot/backend/pkg/web3"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 // GasCollector Gas 价格采集器
@@ -25,25 +26,25 @@ func NewGasCollector(web3Client *web3.Client) *GasCollector {
 	}
 }
 
-// CollectGasPrice 采集 Gas 价格
+// CollectGasPrice 采集 Gas 价格（RPC 调用和写库都受 ctx 控制）
 // 业界最佳实践：同时获取 Legacy 和 EIP-1559 Gas 价格
-func (g *GasCollector) CollectGasPrice() error {
-	db := database.GetDB()
+func (g *GasCollector) CollectGasPrice(ctx context.Context) error {
+	db := database.GetDB().WithContext(ctx)
 
-	// 获取当前区块号
-	blockNumber, err := g.web3Client.GetBlockNumber()
+	// 获取当前区块（区块号、时间戳、BaseFee）
+	header, err := g.web3Client.WithContext(ctx).GetLatestHeader()
 	if err != nil {
 		return fmt.Errorf("获取区块号失败: %w", err)
 	}
 
 	// 方法1：获取基础 Gas 价格（Legacy）
-	gasPrice, err := g.web3Client.GetClient().SuggestGasPrice(context.Background())
+	gasPrice, err := g.web3Client.SuggestGasPrice(ctx)
 	if err != nil {
-		return fmt.Errorf("获取 Gas 价格失败: %w", err)
+		return err
 	}
 
 	// 方法2：获取 EIP-1559 数据（如果支持）
-	baseFee, priorityFee, maxFee := g.getEIP1559GasPrice()
+	baseFee, priorityFee, maxFee := g.getEIP1559GasPrice(ctx, header)
 
 	// 方法3：计算不同速度的 Gas 价格
 	fastPrice := new(big.Int).Add(gasPrice, percentOf(gasPrice, 20)) // +20%
@@ -70,8 +71,8 @@ func (g *GasCollector) CollectGasPrice() error {
 		SlowPrice:      slowPrice.String(),
 		PendingTxCount: pendingCount,
 		NetworkLoad:    networkLoad,
-		BlockNumber:    blockNumber,
-		Timestamp:      time.Now(),
+		BlockNumber:    header.Number.Uint64(),
+		Timestamp:      blockTime(header),
 	}
 
 	if err := db.Create(&gasPriceRecord).Error; err != nil {
@@ -79,32 +80,22 @@ func (g *GasCollector) CollectGasPrice() error {
 	}
 
 	log.Printf("✅ Gas 价格采集成功: %s Gwei (负载: %s)",
-		weiToGwei(gasPrice), networkLoad)
+		units.FormatUnitsFixed(gasPrice, units.GweiDecimals, 2), networkLoad)
 
 	return nil
 }
 
 // getEIP1559GasPrice 获取 EIP-1559 Gas 价格
 // 业界标准：使用 eth_feeHistory 获取
-func (g *GasCollector) getEIP1559GasPrice() (baseFee, priorityFee, maxFee *big.Int) {
-	client := g.web3Client.GetClient()
-	ctx := context.Background()
-
-	// 尝试获取 EIP-1559 数据
-	header, err := client.HeaderByNumber(ctx, nil)
-	if err != nil {
-		// 如果失败，返回默认值
-		return big.NewInt(0), big.NewInt(0), big.NewInt(0)
-	}
-
-	// 获取 BaseFee（EIP-1559）
+func (g *GasCollector) getEIP1559GasPrice(ctx context.Context, header *types.Header) (baseFee, priorityFee, maxFee *big.Int) {
+	// 获取 BaseFee（EIP-1559，旧链为空）
 	baseFee = header.BaseFee
 	if baseFee == nil {
 		baseFee = big.NewInt(0)
 	}
 
 	// 推荐的 Priority Fee
-	priorityFee, err = client.SuggestGasTipCap(ctx)
+	priorityFee, err := g.web3Client.SuggestGasTipCap(ctx)
 	if err != nil {
 		priorityFee = big.NewInt(0)
 	}
@@ -157,12 +148,3 @@ func percentOf(value *big.Int, percent int) *big.Int {
 	result.Div(result, big.NewInt(100))
 	return result
 }
-
-// weiToGwei 将 wei 转换为 Gwei 字符串
-func weiToGwei(wei *big.Int) string {
-	gwei := new(big.Float).Quo(
-		new(big.Float).SetInt(wei),
-		big.NewFloat(1e9),
-	)
-	return gwei.Text('f', 2)
-}
@@ -0,0 +1,49 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/pkg/cache"
+)
+
+// ErrPairInactive 交易对已停用
+var ErrPairInactive = errors.New("交易对已停用")
+
+// SetPairActive 启用 / 停用单个交易对（不影响所属 DEX 的其他交易对）
+// 价格、深度、成交量采集每轮都按 is_active 重新查询，下一轮起生效；同时清除该交易对的价格缓存，
+// 重新启用时不会读到停用前的旧数据。交易对不存在时返回包装了 gorm.ErrRecordNotFound 的错误
+func (c *Collector) SetPairActive(ctx context.Context, pairID uint, active bool) (*models.TradingPair, error) {
+	db := database.GetDB().WithContext(ctx)
+
+	var pair models.TradingPair
+	if err := db.Preload("Token0").Preload("Token1").Preload("Dex").First(&pair, pairID).Error; err != nil {
+		return nil, fmt.Errorf("查询交易对 %d 失败: %w", pairID, err)
+	}
+
+	if pair.IsActive != active {
+		if err := db.Model(&pair).Update("is_active", active).Error; err != nil {
+			return nil, fmt.Errorf("更新交易对 %d 状态失败: %w", pairID, err)
+		}
+		pair.IsActive = active
+	}
+
+	if c.cacheAvailable() {
+		cacheKey := fmt.Sprintf("price:%s", pair.PairAddress)
+		if err := c.cache.Delete(cacheKey); err != nil && !errors.Is(err, cache.ErrCacheUnavailable) {
+			log.Printf("⚠️  清除交易对 %d 的价格缓存失败: %v", pairID, err)
+		}
+	}
+
+	state := "停用"
+	if active {
+		state = "启用"
+	}
+	log.Printf("✅ 已%s交易对 %d: %s/%s @ %s (%s)",
+		state, pair.ID, pair.Token0.Symbol, pair.Token1.Symbol, pair.Dex.Name, pair.PairAddress)
+	return &pair, nil
+}
@@ -0,0 +1,212 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/pkg/units"
+)
+
+// CoinGecko 接口地址：公共接口不接受 Pro API Key，配置了 Key 时默认使用 Pro 接口
+const (
+	DefaultCoingeckoAPIURL = "https://api.coingecko.com/api/v3"
+	CoingeckoProAPIURL     = "https://pro-api.coingecko.com/api/v3"
+)
+
+// coingeckoPublicHost 公共接口的域名
+const coingeckoPublicHost = "api.coingecko.com"
+
+// coingeckoBatchSize 单次 /coins/markets 查询的最大 ID 数量
+const coingeckoBatchSize = 100
+
+// SupplyOptions 代币供应量 / 市值采集选项
+type SupplyOptions struct {
+	CoingeckoAPIURL string        // CoinGecko 接口地址（为空时按是否配置 Key 选择公共接口或 Pro 接口）
+	CoingeckoAPIKey string        // CoinGecko Pro API Key（可选，不会发送给公共接口）
+	Timeout         time.Duration // 请求超时（默认 10 秒）
+}
+
+// SetSupplyOptions 设置供应量采集选项
+func (c *Collector) SetSupplyOptions(opts SupplyOptions) {
+	c.supplyOptions = opts
+}
+
+// CollectTokenSupply 采集代币总供应量、流通供应量和市值
+// 总供应量从链上 ERC20.totalSupply() 读取；配置了 CoingeckoID 的代币额外从 CoinGecko 获取流通供应量。
+// 市值 = PriceUSD × 流通供应量；流通供应量未知时市值为 NULL（总供应量算出的是完全稀释估值，不是市值）
+func (c *Collector) CollectTokenSupply(ctx context.Context) error {
+	db := database.GetDB().WithContext(ctx)
+	client := c.web3Client.WithContext(ctx)
+
+	var tokens []models.Token
+	if err := db.Where("is_active = ?", true).Find(&tokens).Error; err != nil {
+		return fmt.Errorf("查询代币失败: %w", err)
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// 流通供应量失败不影响总供应量更新
+	circulating, err := c.fetchCirculatingSupply(tokens)
+	if err != nil {
+		log.Printf("⚠️  获取流通供应量失败: %v", err)
+	}
+
+	updated := 0
+	failed := 0
+	for i := range tokens {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		token := &tokens[i]
+
+		totalSupply, err := client.GetTokenTotalSupply(token.Address)
+		if err != nil {
+			log.Printf("⚠️  读取总供应量失败 %s: %v", token.Symbol, err)
+			failed++
+			continue
+		}
+
+		updates := map[string]interface{}{
+			"total_supply": totalSupply.String(),
+		}
+
+		// 本次没有取到流通供应量（如 CoinGecko 请求失败）时沿用上次的值
+		circulatingRaw, _ := new(big.Int).SetString(token.CirculatingSupply, 10)
+		if amount, ok := circulating[token.CoingeckoID]; ok && token.CoingeckoID != "" {
+			circulatingRaw = units.FromFloat(amount, token.Decimals)
+			updates["circulating_supply"] = circulatingRaw.String()
+		}
+
+		if circulatingRaw == nil || circulatingRaw.Sign() <= 0 {
+			updates["market_cap_usd"] = nil
+		} else if token.PriceUSD > 0 {
+			amount := units.ToFloat(circulatingRaw, token.Decimals)
+			updates["market_cap_usd"] = amount * token.PriceUSD
+		}
+
+		if err := db.Model(&models.Token{}).Where("id = ?", token.ID).Updates(updates).Error; err != nil {
+			log.Printf("⚠️  更新代币供应量失败 %s: %v", token.Symbol, err)
+			failed++
+			continue
+		}
+		updated++
+	}
+
+	log.Printf("✅ 供应量采集完成: %d 个代币已更新, %d 个失败, %d 个获取到流通供应量",
+		updated, failed, len(circulating))
+	return nil
+}
+
+// coingeckoMarket /coins/markets 返回（只解析需要的字段）
+type coingeckoMarket struct {
+	ID                string   `json:"id"`
+	CirculatingSupply *float64 `json:"circulating_supply"`
+}
+
+// fetchCirculatingSupply 按 CoingeckoID 批量获取流通供应量（代币单位）
+func (c *Collector) fetchCirculatingSupply(tokens []models.Token) (map[string]float64, error) {
+	ids := make([]string, 0, len(tokens))
+	seen := make(map[string]bool)
+	for _, token := range tokens {
+		if token.CoingeckoID == "" || seen[token.CoingeckoID] {
+			continue
+		}
+		seen[token.CoingeckoID] = true
+		ids = append(ids, token.CoingeckoID)
+	}
+
+	result := make(map[string]float64)
+	for start := 0; start < len(ids); start += coingeckoBatchSize {
+		end := start + coingeckoBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		markets, err := c.queryCoingeckoMarkets(ids[start:end])
+		if err != nil {
+			return result, err
+		}
+		for _, market := range markets {
+			if market.CirculatingSupply != nil && *market.CirculatingSupply > 0 {
+				result[market.ID] = *market.CirculatingSupply
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// coingeckoEndpoint 返回请求的接口地址，以及是否在请求头中携带 Pro API Key
+// 未配置地址时有 Key 使用 Pro 接口、否则使用公共接口；公共接口不发送 Key
+func (c *Collector) coingeckoEndpoint() (string, bool) {
+	apiKey := c.supplyOptions.CoingeckoAPIKey
+	apiURL := c.supplyOptions.CoingeckoAPIURL
+	if apiURL == "" {
+		if apiKey != "" {
+			return CoingeckoProAPIURL, true
+		}
+		return DefaultCoingeckoAPIURL, false
+	}
+
+	if apiKey == "" {
+		return apiURL, false
+	}
+	if parsed, err := url.Parse(apiURL); err == nil && strings.EqualFold(parsed.Hostname(), coingeckoPublicHost) {
+		return apiURL, false
+	}
+	return apiURL, true
+}
+
+// queryCoingeckoMarkets 调用 CoinGecko /coins/markets 接口
+func (c *Collector) queryCoingeckoMarkets(ids []string) ([]coingeckoMarket, error) {
+	apiURL, sendKey := c.coingeckoEndpoint()
+	timeout := c.supplyOptions.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	query := url.Values{}
+	query.Set("vs_currency", "usd")
+	query.Set("ids", strings.Join(ids, ","))
+	query.Set("per_page", strconv.Itoa(len(ids)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimRight(apiURL, "/")+"/coins/markets?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建 CoinGecko 请求失败: %w", err)
+	}
+	if sendKey {
+		req.Header.Set("x-cg-pro-api-key", c.supplyOptions.CoingeckoAPIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求 CoinGecko 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CoinGecko 返回状态码 %d", resp.StatusCode)
+	}
+
+	var markets []coingeckoMarket
+	if err := json.NewDecoder(resp.Body).Decode(&markets); err != nil {
+		return nil, fmt.Errorf("解析 CoinGecko 响应失败: %w", err)
+	}
+
+	return markets, nil
+}
@@ -0,0 +1,32 @@
+package collector
+
+import "testing"
+
+func TestCoingeckoEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		apiURL   string
+		apiKey   string
+		wantURL  string
+		wantSend bool
+	}{
+		{name: "未配置", wantURL: DefaultCoingeckoAPIURL},
+		{name: "只配置 Key 时使用 Pro 接口", apiKey: "k", wantURL: CoingeckoProAPIURL, wantSend: true},
+		{name: "公共接口不发送 Key", apiURL: "https://api.coingecko.com/api/v3", apiKey: "k", wantURL: "https://api.coingecko.com/api/v3"},
+		{name: "显式配置 Pro 接口", apiURL: CoingeckoProAPIURL, apiKey: "k", wantURL: CoingeckoProAPIURL, wantSend: true},
+		{name: "自定义代理", apiURL: "http://cg-proxy.internal/api/v3", apiKey: "k", wantURL: "http://cg-proxy.internal/api/v3", wantSend: true},
+		{name: "自定义地址无 Key", apiURL: "http://cg-proxy.internal/api/v3", wantURL: "http://cg-proxy.internal/api/v3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collector{}
+			c.SetSupplyOptions(SupplyOptions{CoingeckoAPIURL: tt.apiURL, CoingeckoAPIKey: tt.apiKey})
+
+			gotURL, gotSend := c.coingeckoEndpoint()
+			if gotURL != tt.wantURL || gotSend != tt.wantSend {
+				t.Errorf("coingeckoEndpoint = (%q, %v), 期望 (%q, %v)", gotURL, gotSend, tt.wantURL, tt.wantSend)
+			}
+		})
+	}
+}
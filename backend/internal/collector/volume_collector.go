@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/pkg/units"
+)
+
+// 成交量数据源
+const (
+	VolumeSourceLogs     = "logs"     // 通过 eth_getLogs 统计 Swap 事件
+	VolumeSourceSubgraph = "subgraph" // 通过 The Graph 子图查询（开发中）
+)
+
+// VolumeOptions 成交量采集选项
+type VolumeOptions struct {
+	Source        string        // 数据源：logs, subgraph
+	Window        time.Duration // 统计窗口（默认 24 小时）
+	BlockTime     time.Duration // 平均出块时间，用于把时间窗口换算为区块数（默认 12 秒）
+	MaxBlockRange uint64        // 单次 eth_getLogs 查询的最大区块数（默认 2000）
+	SubgraphURL   string        // 子图地址（Source 为 subgraph 时使用）
+}
+
+// SetVolumeOptions 设置成交量采集选项
+func (c *Collector) SetVolumeOptions(opts VolumeOptions) {
+	c.volumeOptions = opts
+}
+
+// CollectVolumes 统计每个交易对在滚动窗口内的成交量
+// 结果写入交易对最新的 PriceRecord.Volume24h（token0 最小单位），
+// 并按代币美元价格汇总到 Token.Volume24hUSD
+func (c *Collector) CollectVolumes(ctx context.Context) error {
+	opts := c.volumeOptions
+	if opts.Window <= 0 {
+		opts.Window = 24 * time.Hour
+	}
+	if opts.BlockTime <= 0 {
+		opts.BlockTime = 12 * time.Second
+	}
+	if opts.MaxBlockRange == 0 {
+		opts.MaxBlockRange = 2000
+	}
+
+	switch opts.Source {
+	case "", VolumeSourceLogs:
+	case VolumeSourceSubgraph:
+		return fmt.Errorf("子图成交量数据源开发中")
+	default:
+		return fmt.Errorf("未知的成交量数据源: %s", opts.Source)
+	}
+
+	db := database.GetDB().WithContext(ctx)
+	client := c.web3Client.WithContext(ctx)
+
+	var pairs []models.TradingPair
+	err := db.Preload("Token0").Preload("Token1").
+		Where("is_active = ?", true).
+		Where("pool_version <> ?", "v4"). // V4 的 Swap 事件由 PoolManager 发出（按 poolId 区分），暂不统计
+		Scopes(activeDexPairs).
+		Find(&pairs).Error
+	if err != nil {
+		return fmt.Errorf("查询交易对失败: %w", err)
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	// 计算区块范围
+	toBlock, err := client.GetBlockNumber()
+	if err != nil {
+		return err
+	}
+	windowBlocks := uint64(opts.Window / opts.BlockTime)
+	fromBlock := uint64(0)
+	if toBlock > windowBlocks {
+		fromBlock = toBlock - windowBlocks
+	}
+
+	addresses := make([]string, len(pairs))
+	pairByAddress := make(map[string]*models.TradingPair, len(pairs))
+	for i := range pairs {
+		addresses[i] = pairs[i].PairAddress
+		pairByAddress[strings.ToLower(pairs[i].PairAddress)] = &pairs[i]
+	}
+
+	// 分段查询 Swap 事件并累加
+	volume0 := make(map[uint]*big.Int)
+	volume1 := make(map[uint]*big.Int)
+	swapCount := 0
+
+	for start := fromBlock; start <= toBlock; start += opts.MaxBlockRange {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := start + opts.MaxBlockRange - 1
+		if end > toBlock {
+			end = toBlock
+		}
+
+		swaps, err := client.GetSwapLogs(addresses, start, end)
+		if err != nil {
+			return err
+		}
+
+		for _, swap := range swaps {
+			pair, ok := pairByAddress[strings.ToLower(swap.PairAddress)]
+			if !ok {
+				continue
+			}
+			if volume0[pair.ID] == nil {
+				volume0[pair.ID] = big.NewInt(0)
+				volume1[pair.ID] = big.NewInt(0)
+			}
+			volume0[pair.ID].Add(volume0[pair.ID], swap.Amount0)
+			volume1[pair.ID].Add(volume1[pair.ID], swap.Amount1)
+			swapCount++
+		}
+	}
+
+	// 写入交易对成交量，并按代币汇总（代币精度换算后）
+	tokenVolumes := make(map[uint]*big.Rat)
+	tokens := make(map[uint]*models.Token)
+
+	for i := range pairs {
+		pair := &pairs[i]
+
+		v0 := volume0[pair.ID]
+		v1 := volume1[pair.ID]
+		if v0 == nil {
+			v0, v1 = big.NewInt(0), big.NewInt(0)
+		}
+
+		err := db.Model(&models.PriceRecord{}).
+			Where("id = (?)", db.Model(&models.PriceRecord{}).Select("MAX(id)").Where("pair_id = ?", pair.ID)).
+			Update("volume_24h", v0.String()).Error
+		if err != nil {
+			log.Printf("⚠️  写入成交量失败 %s/%s: %v", pair.Token0.Symbol, pair.Token1.Symbol, err)
+		}
+
+		addTokenVolume(tokenVolumes, tokens, &pair.Token0, v0)
+		addTokenVolume(tokenVolumes, tokens, &pair.Token1, v1)
+	}
+
+	// 按美元价格更新代币成交量（没有价格的代币跳过）
+	updated := 0
+	for id, volume := range tokenVolumes {
+		token := tokens[id]
+		if token.PriceUSD <= 0 {
+			continue
+		}
+
+		amount, _ := volume.Float64()
+		err := db.Model(&models.Token{}).Where("id = ?", id).
+			Update("volume_24h_usd", amount*token.PriceUSD).Error
+		if err != nil {
+			log.Printf("⚠️  更新代币成交量失败 %s: %v", token.Symbol, err)
+			continue
+		}
+		updated++
+	}
+
+	log.Printf("✅ 成交量采集完成: 区块 %d-%d, %d 笔 Swap, %d 个交易对, %d 个代币已更新美元成交量",
+		fromBlock, toBlock, swapCount, len(pairs), updated)
+	return nil
+}
+
+// addTokenVolume 按代币累加成交量（换算为代币单位）
+func addTokenVolume(volumes map[uint]*big.Rat, tokens map[uint]*models.Token, token *models.Token, amount *big.Int) {
+	if volumes[token.ID] == nil {
+		volumes[token.ID] = new(big.Rat)
+		tokens[token.ID] = token
+	}
+	volumes[token.ID].Add(volumes[token.ID], units.ToRat(amount, token.Decimals))
+}
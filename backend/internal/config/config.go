@@ -1,9 +1,13 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"log"
+	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -20,6 +24,12 @@ type Config struct {
 	Log        LogConfig        `mapstructure:"log"`
 	Server     ServerConfig     `mapstructure:"server"`
 	Redis      RedisConfig      `mapstructure:"redis"`
+	Protocols  ProtocolsConfig  `mapstructure:"protocols"`
+	Volume     VolumeConfig     `mapstructure:"volume"`
+	Supply     SupplyConfig     `mapstructure:"supply"`
+	Honeypot   HoneypotConfig   `mapstructure:"honeypot"`
+	Risk       RiskConfig       `mapstructure:"risk"`
+	Notify     NotifyConfig     `mapstructure:"notify"`
 }
 
 // DatabaseConfig 数据库配置
@@ -44,31 +54,96 @@ type BlockchainConfig struct {
 	Timeout int      `mapstructure:"timeout"`
 	Retry   int      `mapstructure:"retry"`
 	UsePool bool     `mapstructure:"use_pool"` // 是否使用 RPC 池
+
+	ArchiveRPCURLs []string `mapstructure:"archive_rpc_urls"` // 归档 / 重查询节点池（Quoter 模拟、固定区块读取、历史事件），为空时全部走主节点
+	MaxBlockLag    uint64   `mapstructure:"max_block_lag"`    // RPC 池健康检查：区块落后池内最高节点超过该值的节点不再使用（0 表示不检查）
+
+	DialTimeout int `mapstructure:"dial_timeout"` // 连接超时（秒），为 0 时使用 timeout
+	CallTimeout int `mapstructure:"call_timeout"` // 单次调用超时（秒），为 0 时使用 timeout
+
+	MaxRPS    float64          `mapstructure:"max_rps"`    // 每个节点每秒最大请求数（0 表示不限流）
+	RPCLimits []RPCLimitConfig `mapstructure:"rpc_limits"` // 按节点覆盖 max_rps（RPC 池中混用付费/免费节点时使用）
+
+	KeepaliveInterval      int `mapstructure:"keepalive_interval"`       // 连接保活检查间隔（秒），0 表示不检查
+	ReconnectAfterFailures int `mapstructure:"reconnect_after_failures"` // 保活检查连续失败多少次后重新连接（默认 3）
+
+	QuoteCacheTTL int `mapstructure:"quote_cache_ttl"` // Quoter 查询结果缓存时长（秒），0 表示不缓存
+}
+
+// RPCLimitConfig 单个 RPC 节点的限流配置
+type RPCLimitConfig struct {
+	URL    string  `mapstructure:"url"`
+	MaxRPS float64 `mapstructure:"max_rps"`
+}
+
+// GetMaxRPS 获取指定节点的限流值
+func (c *BlockchainConfig) GetMaxRPS(rpcURL string) float64 {
+	for _, limit := range c.RPCLimits {
+		if limit.URL == rpcURL {
+			return limit.MaxRPS
+		}
+	}
+	return c.MaxRPS
+}
+
+// EndpointRPS 按节点覆盖的限流值（key 为 RPC URL）
+func (c *BlockchainConfig) EndpointRPS() map[string]float64 {
+	limits := make(map[string]float64, len(c.RPCLimits))
+	for _, limit := range c.RPCLimits {
+		limits[limit.URL] = limit.MaxRPS
+	}
+	return limits
+}
+
+// GetDialTimeout 获取连接超时
+func (c *BlockchainConfig) GetDialTimeout() time.Duration {
+	if c.DialTimeout > 0 {
+		return time.Duration(c.DialTimeout) * time.Second
+	}
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// GetCallTimeout 获取单次调用超时
+func (c *BlockchainConfig) GetCallTimeout() time.Duration {
+	if c.CallTimeout > 0 {
+		return time.Duration(c.CallTimeout) * time.Second
+	}
+	return time.Duration(c.Timeout) * time.Second
 }
 
 // ContractsConfig 合约配置
 type ContractsConfig struct {
 	ArbitrageCore string `mapstructure:"arbitrage_core"`
 	ConfigManager string `mapstructure:"config_manager"`
+	OneInchOracle string `mapstructure:"oneinch_oracle"` // 1inch OffchainOracle 地址（每条链的部署地址不同，为空时不查询聚合器汇率）
 }
 
 // DexConfig DEX 配置
 type DexConfig struct {
-	Name             string `mapstructure:"name"`
-	DexType          string `mapstructure:"dex_type"`           // DEX类型：amm, aggregator, orderbook, hybrid
-	Protocol         string `mapstructure:"protocol"`           // 协议类型：uniswap_v2, uniswap_v3, sushiswap, curve, 1inch 等
-	Router           string `mapstructure:"router"`             // 路由合约地址
-	Factory          string `mapstructure:"factory"`            // 工厂合约地址（聚合器可为空）
-	Quoter           string `mapstructure:"quoter"`             // Quoter合约地址（V3专用）
-	Fee              int    `mapstructure:"fee"`                // 手续费（基点）
-	FeeTier          uint32 `mapstructure:"fee_tier"`           // V3 费率层级
-	DynamicFee       bool   `mapstructure:"dynamic_fee"`        // 是否为动态费率
-	Version          string `mapstructure:"version"`            // 版本
-	ChainID          int64  `mapstructure:"chain_id"`           // 链 ID
-	SupportFlashLoan bool   `mapstructure:"support_flash_loan"` // 是否支持闪电贷
-	SupportMultiHop  bool   `mapstructure:"support_multi_hop"`  // 是否支持多跳路由
-	SupportV3Ticks   bool   `mapstructure:"support_v3_ticks"`   // 是否支持V3 tick数据
-	Priority         int    `mapstructure:"priority"`           // 优先级（数值越小越优先）
+	Name             string   `mapstructure:"name"`
+	DexType          string   `mapstructure:"dex_type"`           // DEX类型：amm, aggregator, orderbook, hybrid
+	Protocol         string   `mapstructure:"protocol"`           // 协议类型：uniswap_v2, uniswap_v3, sushiswap, curve, 1inch 等
+	Router           string   `mapstructure:"router"`             // 路由合约地址
+	Factory          string   `mapstructure:"factory"`            // 工厂合约地址（聚合器可为空）
+	Quoter           string   `mapstructure:"quoter"`             // Quoter合约地址（V3专用）
+	QuoterType       string   `mapstructure:"quoter_type"`        // Quoter ABI 类型：uniswap_v2quoter（默认）, uniswap_v1quoter, pancake_v3, algebra
+	Fee              int      `mapstructure:"fee"`                // 手续费（基点）
+	FeeTier          uint32   `mapstructure:"fee_tier"`           // V3 费率层级
+	FeeTiers         []uint32 `mapstructure:"fee_tiers"`          // V3 发现池子时遍历的费率层级列表（为空时只使用 fee_tier）
+	DynamicFee       bool     `mapstructure:"dynamic_fee"`        // 是否为动态费率
+	ReserveStyle     string   `mapstructure:"reserve_style"`      // V2 储备量读取方式：combined（默认）, separate
+	Version          string   `mapstructure:"version"`            // 版本
+	ChainID          int64    `mapstructure:"chain_id"`           // 链 ID
+	SupportFlashLoan bool     `mapstructure:"support_flash_loan"` // 是否支持闪电贷
+	SupportMultiHop  bool     `mapstructure:"support_multi_hop"`  // 是否支持多跳路由
+	SupportV3Ticks   bool     `mapstructure:"support_v3_ticks"`   // 是否支持V3 tick数据
+	Priority         int      `mapstructure:"priority"`           // 优先级（数值越小越优先）
+}
+
+// ProtocolsConfig 协议启用配置
+type ProtocolsConfig struct {
+	Allowed []string `mapstructure:"allowed"` // 白名单：非空时只启用列表中的协议
+	Denied  []string `mapstructure:"denied"`  // 黑名单：禁用列表中的协议（优先于白名单）
 }
 
 // TokenConfig 代币配置
@@ -76,6 +151,9 @@ type TokenConfig struct {
 	Symbol   string `mapstructure:"symbol"`
 	Address  string `mapstructure:"address"`
 	Decimals int    `mapstructure:"decimals"`
+
+	CoingeckoID string `mapstructure:"coingecko_id"` // CoinGecko ID（可选，用于获取流通供应量）
+	IsRebasing  bool   `mapstructure:"is_rebasing"`  // 是否为 rebase 代币（如 stETH、AMPL）
 }
 
 // SchedulerConfig 定时任务配置
@@ -83,6 +161,62 @@ type SchedulerConfig struct {
 	CollectInterval int `mapstructure:"collect_interval"`
 	AnalyzeInterval int `mapstructure:"analyze_interval"`
 	CleanupInterval int `mapstructure:"cleanup_interval"`
+
+	PerformanceInterval int `mapstructure:"performance_interval"` // 策略表现统计间隔（分钟）
+	PerformanceWindow   int `mapstructure:"performance_window"`   // 策略表现统计窗口（小时）
+
+	VolumeInterval int `mapstructure:"volume_interval"` // 成交量采集间隔（分钟）
+	SupplyInterval int `mapstructure:"supply_interval"` // 代币供应量 / 市值采集间隔（分钟）
+
+	V3PriceInterval int `mapstructure:"v3_price_interval"` // V3 快速价格采集间隔（秒），0 表示只随 collect_interval 采集
+	DepthInterval   int `mapstructure:"depth_interval"`    // V3 深度采集间隔（秒）
+
+	DepthFeeTiers    []uint32 `mapstructure:"depth_fee_tiers"`   // 深度采集探测的费率层级（为空时探测所有已发现的池子）
+	DepthConcurrency int      `mapstructure:"depth_concurrency"` // 深度采集同时探测的池子数（默认 4）
+
+	PinSnapshotBlock bool `mapstructure:"pin_snapshot_block"` // 价格 / 深度采集时把所有池子的读取固定在本轮区块头的区块
+}
+
+// RiskConfig 风控配置（亏损熔断）
+type RiskConfig struct {
+	MaxConsecutiveLosses int     `mapstructure:"max_consecutive_losses"` // 连续亏损次数上限（0 表示不检查）
+	MaxDrawdownUSD       float64 `mapstructure:"max_drawdown_usd"`       // 窗口内累计亏损上限（美元，0 表示不检查）
+	DrawdownWindow       int     `mapstructure:"drawdown_window"`        // 累计亏损统计窗口（小时）
+	NativeSymbol         string  `mapstructure:"native_symbol"`          // Gas 计价代币符号（默认 WETH）
+	AlertWebhook         string  `mapstructure:"alert_webhook"`          // 熔断告警 Webhook
+}
+
+// NotifyConfig 执行结果推送配置（Webhook）
+type NotifyConfig struct {
+	Webhooks      []string `mapstructure:"webhooks"`      // 接收执行结果的地址（POST JSON，为空不推送）
+	Secret        string   `mapstructure:"secret"`        // HMAC-SHA256 签名密钥（请求头 X-Signature-256: sha256=<hex>）
+	Opportunities bool     `mapstructure:"opportunities"` // 是否同时推送新发现的套利机会
+	QueueSize     int      `mapstructure:"queue_size"`    // 待发送事件队列长度（满了丢弃，不阻塞交易）
+	MaxRetries    int      `mapstructure:"max_retries"`   // 非 2xx 时的重试次数（指数退避）
+	Timeout       int      `mapstructure:"timeout"`       // 单次请求超时（秒）
+}
+
+// HoneypotConfig 蜜罐代币检测配置
+type HoneypotConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // 是否在发现新代币时检测
+	APIURL  string `mapstructure:"api_url"` // 检测接口（honeypot.is 兼容），为空使用默认地址
+	Timeout int    `mapstructure:"timeout"` // 请求超时（秒）
+}
+
+// VolumeConfig 成交量采集配置
+type VolumeConfig struct {
+	Source        string `mapstructure:"source"`          // 数据源：logs（Swap 事件）, subgraph
+	WindowHours   int    `mapstructure:"window_hours"`    // 统计窗口（小时）
+	BlockTime     int    `mapstructure:"block_time"`      // 平均出块时间（秒），用于换算区块范围
+	MaxBlockRange uint64 `mapstructure:"max_block_range"` // 单次 eth_getLogs 查询的最大区块数
+	SubgraphURL   string `mapstructure:"subgraph_url"`    // 子图地址
+}
+
+// SupplyConfig 代币供应量 / 市值采集配置
+type SupplyConfig struct {
+	CoingeckoAPIURL string `mapstructure:"coingecko_api_url"` // CoinGecko 接口地址（为空时有 Key 用 Pro 接口，否则用公共接口）
+	CoingeckoAPIKey string `mapstructure:"coingecko_api_key"` // CoinGecko Pro API Key（可选）
+	Timeout         int    `mapstructure:"timeout"`           // 请求超时（秒）
 }
 
 // ArbitrageConfig 套利配置
@@ -90,8 +224,28 @@ type ArbitrageConfig struct {
 	MinProfitRate float64 `mapstructure:"min_profit_rate"`
 	MaxSlippage   float64 `mapstructure:"max_slippage"`
 	MaxGasPrice   int64   `mapstructure:"max_gas_price"`
+
+	ValidityBlocks uint64 `mapstructure:"validity_blocks"` // 套利机会有效区块数（发现区块 + N 之后过期，0 表示只按时间过期）
+
+	// === 机会入库策略 ===
+	PersistMinProfitRate float64 `mapstructure:"persist_min_profit_rate"` // 只保存利润率不低于该值的机会（百分比，0 表示不过滤）
+	PersistDedupWindow   int     `mapstructure:"persist_dedup_window"`    // 相同路径签名的去重窗口（秒，0 表示不去重）
+	PersistSampleRate    int     `mapstructure:"persist_sample_rate"`     // 每 N 条新机会保存 1 条（0 或 1 表示全部保存）
+
+	// === 路径搜索 ===
+	MaxCandidatePaths int      `mapstructure:"max_candidate_paths"` // 单次路径搜索最多保留的候选路径数（超过时按池子流动性保留，0 表示不限制）
+	HubTokens         []string `mapstructure:"hub_tokens"`          // 枢纽代币符号，路径的中间代币限制为枢纽代币（为空不限制）
+	HubMode           string   `mapstructure:"hub_mode"`            // 枢纽代币限制方式：hubs_only（默认）, one_wildcard（最多一个非枢纽代币）
+	MaxPathsExplored  int      `mapstructure:"max_paths_explored"`  // 单次路径搜索最多扩展的节点数（耗尽时返回已找到的路径，0 表示不限制）
+	MaxDFSDuration    int      `mapstructure:"max_dfs_duration"`    // 单次路径搜索最长耗时（毫秒，0 表示不限制）
 }
 
+// 枢纽代币限制方式
+const (
+	HubModeHubsOnly    = "hubs_only"    // 中间代币只能是枢纽代币
+	HubModeOneWildcard = "one_wildcard" // 中间代币最多一个非枢纽代币
+)
+
 // LogConfig 日志配置
 type LogConfig struct {
 	Level      string `mapstructure:"level"`
@@ -99,12 +253,15 @@ type LogConfig struct {
 	MaxSize    int    `mapstructure:"max_size"`
 	MaxBackups int    `mapstructure:"max_backups"`
 	MaxAge     int    `mapstructure:"max_age"`
+
+	SuccessSampleRate int `mapstructure:"success_sample_rate"` // 逐交易对成功日志采样：每 N 条输出 1 条，0 表示只输出汇总（debug 级别始终全部输出）
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Port       int    `mapstructure:"port"`        // HTTP API 端口（0 表示不启动）
+	Mode       string `mapstructure:"mode"`        // 运行模式：debug, release
+	AdminToken string `mapstructure:"admin_token"` // 管理接口（/admin/*、POST /pairs/{id}/refresh）的访问令牌（为空则不注册管理接口）
 }
 
 // RedisConfig Redis 配置
@@ -115,9 +272,16 @@ type RedisConfig struct {
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
 	TTL      int    `mapstructure:"ttl"` // 默认过期时间（秒）
+
+	// === 熔断配置 ===
+	BreakerThreshold int `mapstructure:"breaker_threshold"` // 连续失败多少次后暂停使用缓存
+	BreakerCooldown  int `mapstructure:"breaker_cooldown"`  // 暂停时长（秒），到期后探测恢复
 }
 
-var globalConfig *Config
+var (
+	globalConfig *Config
+	configMu     sync.RWMutex
+)
 
 // LoadConfig 加载配置文件
 func LoadConfig(configPath string) (*Config, error) {
@@ -128,22 +292,72 @@ func LoadConfig(configPath string) (*Config, error) {
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
-	if err := viper.ReadInConfig(); err != nil {
+	config, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	configMu.Lock()
+	globalConfig = config
+	configMu.Unlock()
+
+	log.Printf("配置加载成功: %s", configPath)
+	return config, nil
+}
+
+// ReloadConfig 重新读取 LoadConfig 指定的配置文件（运行时热加载）
+func ReloadConfig() (*Config, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	if globalConfig == nil {
+		return nil, fmt.Errorf("配置未初始化，请先调用 LoadConfig")
+	}
+
+	config, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	globalConfig = config
+	log.Printf("配置重新加载成功: %s", viper.ConfigFileUsed())
+	return config, nil
+}
+
+// readConfig 读取并解析配置文件
+// 先替换 ${VAR} / ${VAR:default} 占位符，再应用 <KEY>_FILE 形式的 secret 文件
+func readConfig() (*Config, error) {
+	content, err := os.ReadFile(viper.ConfigFileUsed())
+	if err != nil {
 		return nil, fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
+	expanded, err := expandEnv(content)
+	if err != nil {
+		return nil, fmt.Errorf("替换环境变量失败: %w", err)
+	}
+
+	if err := viper.ReadConfig(bytes.NewReader(expanded)); err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	if err := applySecretFiles(); err != nil {
+		return nil, err
+	}
+
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
 
-	globalConfig = &config
-	log.Printf("配置加载成功: %s", configPath)
 	return &config, nil
 }
 
 // GetConfig 获取全局配置
 func GetConfig() *Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+
 	if globalConfig == nil {
 		log.Fatal("配置未初始化，请先调用 LoadConfig")
 	}
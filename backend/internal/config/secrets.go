@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// envPattern 匹配配置文件中的 ${VAR} 和 ${VAR:default}
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::([^}]*))?\}`)
+
+// secretKeys 支持通过 <KEY>_FILE 环境变量从文件读取的敏感配置项
+// 例如 DATABASE_PASSWORD_FILE=/run/secrets/db_password 会覆盖 database.password
+var secretKeys = []string{
+	"database.password",
+	"blockchain.rpc_url",
+	"redis.password",
+	"server.admin_token",
+	"supply.coingecko_api_key",
+	"notify.secret",
+}
+
+// secretListKeys 以 <KEY>_FILE 读取的列表配置项（RPC 地址通常带 API Key）
+// 文件中的地址以逗号或换行分隔，去掉首尾空白并跳过空项
+var secretListKeys = []string{
+	"blockchain.rpc_urls",
+	"blockchain.archive_rpc_urls",
+}
+
+// expandEnv 替换配置文件中的环境变量占位符
+// ${VAR} 依次取环境变量 VAR、VAR_FILE 指向的文件内容，都没有时为空字符串；${VAR:default} 在都没有时使用 default。
+// 值直接替换进 YAML 文本，包含 #、: 等特殊字符时占位符需要加引号（如 password: "${DB_PASSWORD}"）
+func expandEnv(content []byte) ([]byte, error) {
+	var expandErr error
+	expanded := envPattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		parts := envPattern.FindSubmatch(match)
+		name, defaultValue := string(parts[1]), string(parts[2])
+
+		value, ok, err := lookupEnv(name)
+		if err != nil {
+			if expandErr == nil {
+				expandErr = err
+			}
+			return match
+		}
+		if !ok {
+			value = defaultValue
+		}
+		return []byte(value)
+	})
+
+	if expandErr != nil {
+		return nil, expandErr
+	}
+	return expanded, nil
+}
+
+// lookupEnv 读取环境变量，未设置时读取 <name>_FILE 指向的文件（Docker / K8s secret 挂载）
+func lookupEnv(name string) (string, bool, error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true, nil
+	}
+
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", false, nil
+	}
+
+	value, err := readSecretFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("读取 %s_FILE 失败: %w", name, err)
+	}
+	return value, true, nil
+}
+
+// applySecretFiles 用 <KEY>_FILE 指向的文件内容覆盖敏感配置项
+func applySecretFiles() error {
+	for _, key := range secretKeys {
+		value, ok, err := readSecretFileFor(key)
+		if err != nil {
+			return err
+		}
+		if ok {
+			viper.Set(key, value)
+		}
+	}
+
+	for _, key := range secretListKeys {
+		value, ok, err := readSecretFileFor(key)
+		if err != nil {
+			return err
+		}
+		if ok {
+			viper.Set(key, splitSecretList(value))
+		}
+	}
+	return nil
+}
+
+// readSecretFileFor 读取配置项 key 对应的 <KEY>_FILE（如 blockchain.rpc_urls -> BLOCKCHAIN_RPC_URLS_FILE），未设置时返回 false
+func readSecretFileFor(key string) (string, bool, error) {
+	envName := strings.ToUpper(strings.ReplaceAll(key, ".", "_")) + "_FILE"
+	path := os.Getenv(envName)
+	if path == "" {
+		return "", false, nil
+	}
+
+	value, err := readSecretFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("读取 %s 失败: %w", envName, err)
+	}
+	return value, true, nil
+}
+
+// splitSecretList 按逗号或换行拆分列表，去掉首尾空白并跳过空项
+func splitSecretList(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n'
+	})
+
+	items := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if item := strings.TrimSpace(field); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// readSecretFile 读取 secret 文件（去掉末尾换行）
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitSecretList(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{name: "逗号分隔", value: "https://a,https://b", want: []string{"https://a", "https://b"}},
+		{name: "换行分隔", value: "https://a\nhttps://b\r\n", want: []string{"https://a", "https://b"}},
+		{name: "混合分隔并去掉空白和空项", value: " https://a ,\n\n https://b,,\n", want: []string{"https://a", "https://b"}},
+		{name: "空文件", value: "\n", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitSecretList(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitSecretList(%q) = %q, 期望 %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// writeTestFile 在临时目录写入文件并返回路径
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("写入 %s 失败: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadConfigRPCURLsFromFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := writeTestFile(t, dir, "config.yaml", `
+blockchain:
+  rpc_url: "https://primary"
+  rpc_urls: ["https://from-yaml"]
+  archive_rpc_urls: ["https://archive-from-yaml"]
+`)
+	t.Setenv("BLOCKCHAIN_RPC_URLS_FILE", writeTestFile(t, dir, "rpc_urls", "https://a/key1,\nhttps://b/key2\n"))
+	t.Setenv("BLOCKCHAIN_ARCHIVE_RPC_URLS_FILE", writeTestFile(t, dir, "archive_rpc_urls", "https://archive/key3\n"))
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+
+	if want := []string{"https://a/key1", "https://b/key2"}; !reflect.DeepEqual(cfg.Blockchain.RPCURLs, want) {
+		t.Errorf("rpc_urls = %q, 期望 %q", cfg.Blockchain.RPCURLs, want)
+	}
+	if want := []string{"https://archive/key3"}; !reflect.DeepEqual(cfg.Blockchain.ArchiveRPCURLs, want) {
+		t.Errorf("archive_rpc_urls = %q, 期望 %q", cfg.Blockchain.ArchiveRPCURLs, want)
+	}
+	if cfg.Blockchain.RPCURL != "https://primary" {
+		t.Errorf("rpc_url = %q, 不应被列表文件影响", cfg.Blockchain.RPCURL)
+	}
+
+	t.Setenv("BLOCKCHAIN_RPC_URLS_FILE", filepath.Join(dir, "missing"))
+	if _, err := LoadConfig(configPath); err == nil {
+		t.Error("文件不存在时应返回错误")
+	}
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ValidationError 配置校验失败，Issues 列出所有问题（一次修复，不必逐个试错）
+type ValidationError struct {
+	Issues []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("配置校验失败（%d 个问题）: %s", len(e.Issues), strings.Join(e.Issues, "; "))
+}
+
+// Validate 离线校验配置（不连接数据库和 RPC）
+// 检查必填项、地址格式、DEX 的 Router / Factory / Quoter 以及 V3 / V4 的费率层级；
+// 全部通过时返回 nil，否则返回 *ValidationError
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	c.validateDatabase(v)
+	c.validateBlockchain(v)
+	c.validateDexes(v)
+	c.validateTokens(v)
+	c.validateArbitrage(v)
+
+	if c.Contracts.OneInchOracle != "" {
+		v.address("contracts.oneinch_oracle", c.Contracts.OneInchOracle)
+	}
+	for _, webhook := range c.Notify.Webhooks {
+		if u, err := url.Parse(webhook); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			v.addf("notify.webhooks 地址格式错误: %q", webhook)
+		}
+	}
+	if c.Server.Port < 0 || c.Server.Port > 65535 {
+		v.addf("server.port 超出范围: %d", c.Server.Port)
+	}
+
+	if len(v.issues) > 0 {
+		return &ValidationError{Issues: v.issues}
+	}
+	return nil
+}
+
+// validator 收集校验问题
+type validator struct {
+	issues []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.issues = append(v.issues, fmt.Sprintf(format, args...))
+}
+
+// required 必填字符串
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf("%s 不能为空", field)
+	}
+}
+
+// address 地址格式（0x + 40 位十六进制）
+func (v *validator) address(field, value string) {
+	if !common.IsHexAddress(value) {
+		v.addf("%s 不是合法地址: %q", field, value)
+	}
+}
+
+// optionalAddress 可为空的地址
+func (v *validator) optionalAddress(field, value string) {
+	if value != "" {
+		v.address(field, value)
+	}
+}
+
+func (c *Config) validateDatabase(v *validator) {
+	v.required("database.host", c.Database.Host)
+	v.required("database.user", c.Database.User)
+	v.required("database.dbname", c.Database.DBName)
+	if c.Database.Port <= 0 || c.Database.Port > 65535 {
+		v.addf("database.port 超出范围: %d", c.Database.Port)
+	}
+}
+
+func (c *Config) validateBlockchain(v *validator) {
+	bc := &c.Blockchain
+	if bc.ChainID <= 0 {
+		v.addf("blockchain.chain_id 必须大于 0")
+	}
+
+	urls := bc.RPCURLs
+	if bc.RPCURL != "" {
+		urls = append([]string{bc.RPCURL}, urls...)
+	}
+	if len(urls) == 0 {
+		v.addf("blockchain.rpc_url 和 blockchain.rpc_urls 不能都为空")
+	}
+	urls = append(urls, bc.ArchiveRPCURLs...)
+	for _, rpcURL := range urls {
+		u, err := url.Parse(rpcURL)
+		if err != nil || u.Host == "" {
+			v.addf("RPC 地址格式错误: %q", rpcURL)
+			continue
+		}
+		switch u.Scheme {
+		case "http", "https", "ws", "wss":
+		default:
+			v.addf("RPC 地址协议不支持（需要 http/https/ws/wss）: %q", rpcURL)
+		}
+	}
+}
+
+func (c *Config) validateDexes(v *validator) {
+	names := make(map[string]bool, len(c.Dexes))
+	for i, dex := range c.Dexes {
+		field := fmt.Sprintf("dexes[%d]", i)
+		if dex.Name != "" {
+			field = fmt.Sprintf("dexes[%d](%s)", i, dex.Name)
+		}
+
+		v.required(field+".name", dex.Name)
+		v.required(field+".protocol", dex.Protocol)
+		if names[dex.Name] {
+			v.addf("%s.name 重复", field)
+		}
+		names[dex.Name] = true
+
+		v.optionalAddress(field+".router", dex.Router)
+		v.optionalAddress(field+".quoter", dex.Quoter)
+
+		// 聚合器没有 Factory，其他 DEX 靠 Factory 发现交易对
+		if dex.DexType == "aggregator" {
+			v.optionalAddress(field+".factory", dex.Factory)
+		} else {
+			v.address(field+".factory", dex.Factory)
+		}
+
+		if dex.ChainID != 0 && c.Blockchain.ChainID != 0 && dex.ChainID != c.Blockchain.ChainID {
+			v.addf("%s.chain_id=%d 与 blockchain.chain_id=%d 不一致", field, dex.ChainID, c.Blockchain.ChainID)
+		}
+
+		// V3 / V4 每个费率层级是独立的池子，没有费率层级时无法定位池子
+		concentrated := dex.Version == "v3" || dex.Version == "v4" || dex.SupportV3Ticks
+		if concentrated && !dex.DynamicFee && dex.FeeTier == 0 && len(dex.FeeTiers) == 0 {
+			v.addf("%s 是 V3/V4 DEX，需要配置 fee_tier 或 fee_tiers", field)
+		}
+	}
+}
+
+func (c *Config) validateArbitrage(v *validator) {
+	switch c.Arbitrage.HubMode {
+	case "", HubModeHubsOnly, HubModeOneWildcard:
+	default:
+		v.addf("arbitrage.hub_mode 无效: %q（可选 %s, %s）", c.Arbitrage.HubMode, HubModeHubsOnly, HubModeOneWildcard)
+	}
+	if c.Arbitrage.MaxCandidatePaths < 0 {
+		v.addf("arbitrage.max_candidate_paths 不能为负数: %d", c.Arbitrage.MaxCandidatePaths)
+	}
+	if c.Arbitrage.MaxPathsExplored < 0 {
+		v.addf("arbitrage.max_paths_explored 不能为负数: %d", c.Arbitrage.MaxPathsExplored)
+	}
+	if c.Arbitrage.MaxDFSDuration < 0 {
+		v.addf("arbitrage.max_dfs_duration 不能为负数: %d", c.Arbitrage.MaxDFSDuration)
+	}
+}
+
+func (c *Config) validateTokens(v *validator) {
+	addresses := make(map[string]bool, len(c.Tokens))
+	for i, token := range c.Tokens {
+		field := fmt.Sprintf("tokens[%d]", i)
+		if token.Symbol != "" {
+			field = fmt.Sprintf("tokens[%d](%s)", i, token.Symbol)
+		}
+
+		v.required(field+".symbol", token.Symbol)
+		v.address(field+".address", token.Address)
+		if token.Decimals < 0 || token.Decimals > 255 { // ERC20 decimals 为 uint8
+			v.addf("%s.decimals 超出范围: %d", field, token.Decimals)
+		}
+
+		key := strings.ToLower(token.Address)
+		if addresses[key] {
+			v.addf("%s.address 重复", field)
+		}
+		addresses[key] = true
+	}
+}
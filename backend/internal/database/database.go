@@ -1,18 +1,24 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/defi-bot/backend/internal/config"
 	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/internal/repository"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-var db *gorm.DB
+var (
+	db            *gorm.DB
+	tokenRepo     *repository.TokenRepository
+	executionRepo *repository.ExecutionRepository
+)
 
 // InitDB 初始化数据库连接
 func InitDB(cfg *config.DatabaseConfig) error {
@@ -50,6 +56,10 @@ func InitDB(cfg *config.DatabaseConfig) error {
 		return fmt.Errorf("数据库连接测试失败: %w", err)
 	}
 
+	// 初始化仓储
+	tokenRepo = repository.NewTokenRepository(db)
+	executionRepo = repository.NewExecutionRepository(db)
+
 	log.Println("数据库连接成功")
 	return nil
 }
@@ -62,31 +72,106 @@ func GetDB() *gorm.DB {
 	return db
 }
 
+// GetTokenRepository 获取代币仓储
+func GetTokenRepository() *repository.TokenRepository {
+	if tokenRepo == nil {
+		log.Fatal("数据库未初始化")
+	}
+	return tokenRepo
+}
+
+// GetExecutionRepository 获取执行记录仓储（执行记录的唯一写入入口，保证幂等键生效）
+func GetExecutionRepository() *repository.ExecutionRepository {
+	if executionRepo == nil {
+		log.Fatal("数据库未初始化")
+	}
+	return executionRepo
+}
+
 // AutoMigrate 自动迁移数据库表
 func AutoMigrate() error {
 	log.Println("开始数据库迁移...")
 
-	// 迁移所有模型
-	err := db.AutoMigrate(
-		&models.Token{},
-		&models.Dex{},
-		&models.TradingPair{},
-		&models.PairReserve{},
-		&models.PriceRecord{},
-		&models.LiquidityDepth{},  // ✅ 新增：流动性深度表
-		&models.GasPriceHistory{}, // ✅ 新增：Gas价格历史表
-		&models.ArbitrageOpportunity{},
-		&models.ArbitrageExecution{},
-	)
+	// PostgreSQL 的索引名在 schema 内唯一：pair_reserves 旧的 idx_pair_time 与 price_records 同名，
+	// 会导致后者建索引失败，需在迁移前删除（已改名为 idx_reserve_pair_time）
+	if err := dropIndexIfExists(&models.PairReserve{}, "idx_pair_time"); err != nil {
+		return err
+	}
 
-	if err != nil {
+	// market_cap_usd 改为可空（流通供应量未知时为 NULL，不再用总供应量代替）
+	if err := migrateNullableMarketCap(); err != nil {
+		return err
+	}
+
+	// discovered_at 新增时用 created_at 回填已有机会（否则会被默认值填成迁移时间）
+	backfillDiscoveredAt := db.Migrator().HasTable(&models.ArbitrageOpportunity{}) &&
+		!db.Migrator().HasColumn(&models.ArbitrageOpportunity{}, "discovered_at")
+
+	// 迁移所有模型
+	if err := db.AutoMigrate(migratedModels...); err != nil {
 		return fmt.Errorf("数据库迁移失败: %w", err)
 	}
 
+	if backfillDiscoveredAt {
+		if err := db.Exec("UPDATE arbitrage_opportunities SET discovered_at = created_at").Error; err != nil {
+			return fmt.Errorf("回填 discovered_at 失败: %w", err)
+		}
+	}
+
+	// 交易哈希唯一索引改为部分索引（发送前的 pending 记录没有哈希），删除旧的全表唯一索引
+	if err := dropIndexIfExists(&models.ArbitrageExecution{}, "idx_arbitrage_executions_tx_hash"); err != nil {
+		return err
+	}
+
 	log.Println("数据库迁移完成")
 	return nil
 }
 
+// migrateNullableMarketCap 旧版本的 market_cap_usd 默认 0，流通供应量未知时按总供应量计算（实际是完全稀释估值）：
+// 去掉默认值，并把没有流通供应量的代币市值置为 NULL
+// 以列上是否还有默认值判断是否已迁移，只执行一次
+func migrateNullableMarketCap() error {
+	columns, err := db.Migrator().ColumnTypes(&models.Token{})
+	if err != nil {
+		return nil // 表不存在，AutoMigrate 会按新定义创建
+	}
+
+	for _, column := range columns {
+		if column.Name() != "market_cap_usd" {
+			continue
+		}
+		if _, hasDefault := column.DefaultValue(); !hasDefault {
+			return nil
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE tokens ALTER COLUMN market_cap_usd DROP DEFAULT").Error; err != nil {
+				return err
+			}
+			return tx.Exec(`UPDATE tokens SET market_cap_usd = NULL
+				WHERE market_cap_usd = 0 OR circulating_supply IS NULL OR circulating_supply = ''`).Error
+		})
+		if err != nil {
+			return fmt.Errorf("迁移 market_cap_usd 为可空列失败: %w", err)
+		}
+		log.Println("已将 tokens.market_cap_usd 迁移为可空列")
+	}
+	return nil
+}
+
+// dropIndexIfExists 删除已废弃的索引
+func dropIndexIfExists(model interface{}, name string) error {
+	migrator := db.Migrator()
+	if !migrator.HasIndex(model, name) {
+		return nil
+	}
+	if err := migrator.DropIndex(model, name); err != nil {
+		return fmt.Errorf("删除索引 %s 失败: %w", name, err)
+	}
+	log.Printf("已删除旧索引: %s", name)
+	return nil
+}
+
 // CloseDB 关闭数据库连接
 func CloseDB() error {
 	if db != nil {
@@ -100,113 +185,15 @@ func CloseDB() error {
 }
 
 // SeedData 初始化种子数据
-func SeedData(cfg *config.Config) error {
+func SeedData(ctx context.Context, cfg *config.Config) error {
 	log.Println("开始初始化种子数据...")
 
-	// 初始化代币数据
-	for _, tokenCfg := range cfg.Tokens {
-		var token models.Token
-		result := db.Where("address = ?", tokenCfg.Address).First(&token)
-
-		if result.Error == gorm.ErrRecordNotFound {
-			// 代币不存在，创建新记录
-			token = models.Token{
-				Address:  tokenCfg.Address,
-				Symbol:   tokenCfg.Symbol,
-				Name:     tokenCfg.Symbol, // 可以后续更新
-				Decimals: tokenCfg.Decimals,
-				ChainID:  cfg.Blockchain.ChainID,
-				IsActive: true,
-			}
-			if err := db.Create(&token).Error; err != nil {
-				log.Printf("创建代币 %s 失败: %v", tokenCfg.Symbol, err)
-				continue
-			}
-			log.Printf("创建代币: %s (%s)", tokenCfg.Symbol, tokenCfg.Address)
-		}
-	}
-
-	// 初始化 DEX 数据
-	for _, dexCfg := range cfg.Dexes {
-		var dex models.Dex
-		result := db.Where("name = ?", dexCfg.Name).First(&dex)
-
-		// 设置默认值
-		protocol := dexCfg.Protocol
-		if protocol == "" {
-			protocol = "uniswap_v2"
-		}
-
-		version := dexCfg.Version
-		if version == "" {
-			version = "v2"
-		}
-
-		chainID := dexCfg.ChainID
-		if chainID == 0 {
-			chainID = cfg.Blockchain.ChainID
-		}
-
-		dexType := dexCfg.DexType
-		if dexType == "" {
-			dexType = "amm" // 默认为 AMM
-		}
-
-		priority := dexCfg.Priority
-		if priority == 0 {
-			priority = 100 // 默认优先级
-		}
-
-		if result.Error == gorm.ErrRecordNotFound {
-			// DEX 不存在，创建新记录
-			dex = models.Dex{
-				Name:             dexCfg.Name,
-				DexType:          dexType,
-				Protocol:         protocol,
-				RouterAddress:    dexCfg.Router,
-				FactoryAddress:   dexCfg.Factory,
-				QuoterAddress:    dexCfg.Quoter,
-				Fee:              dexCfg.Fee,
-				FeeTier:          dexCfg.FeeTier,
-				DynamicFee:       dexCfg.DynamicFee,
-				ChainID:          chainID,
-				IsActive:         true,
-				SupportFlashLoan: dexCfg.SupportFlashLoan,
-				SupportMultiHop:  dexCfg.SupportMultiHop,
-				SupportV3Ticks:   dexCfg.SupportV3Ticks,
-				Version:          version,
-				Priority:         priority,
-			}
-			if err := db.Create(&dex).Error; err != nil {
-				log.Printf("创建 DEX %s 失败: %v", dexCfg.Name, err)
-				continue
-			}
-			log.Printf("✅ 创建 DEX: %s (类型: %s, 协议: %s, 版本: %s)", dexCfg.Name, dexType, protocol, version)
-		} else {
-			// DEX 已存在，更新配置
-			dex.DexType = dexType
-			dex.Protocol = protocol
-			dex.RouterAddress = dexCfg.Router
-			dex.FactoryAddress = dexCfg.Factory
-			dex.QuoterAddress = dexCfg.Quoter
-			dex.Fee = dexCfg.Fee
-			dex.FeeTier = dexCfg.FeeTier
-			dex.DynamicFee = dexCfg.DynamicFee
-			dex.Version = version
-			dex.ChainID = chainID
-			dex.SupportFlashLoan = dexCfg.SupportFlashLoan
-			dex.SupportMultiHop = dexCfg.SupportMultiHop
-			dex.SupportV3Ticks = dexCfg.SupportV3Ticks
-			dex.Priority = priority
-
-			if err := db.Save(&dex).Error; err != nil {
-				log.Printf("更新 DEX %s 失败: %v", dexCfg.Name, err)
-				continue
-			}
-			log.Printf("✅ 更新 DEX: %s (类型: %s, 协议: %s, 版本: %s)", dexCfg.Name, dexType, protocol, version)
-		}
+	diff := ReconcileConfig(ctx, cfg)
+	if err := diff.Err(); err != nil {
+		log.Printf("⚠️  种子数据部分失败: %s", diff.Summary())
+		return fmt.Errorf("种子数据不完整: %w", err)
 	}
 
-	log.Println("种子数据初始化完成")
+	log.Printf("✅ 种子数据初始化完成: %s", diff.Summary())
 	return nil
 }
@@ -0,0 +1,323 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/defi-bot/backend/internal/config"
+	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/pkg/web3"
+	"gorm.io/gorm"
+)
+
+// reconcileMu 防止多个对账任务并发执行
+var reconcileMu sync.Mutex
+
+// DexChange 单个 DEX 的变更
+type DexChange struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"` // 发生变化的字段
+}
+
+// ReconcileFailure 单个代币 / DEX 同步失败
+type ReconcileFailure struct {
+	Kind  string `json:"kind"` // token / dex
+	Name  string `json:"name"`
+	Op    string `json:"op"` // query / create / update / validate
+	Error string `json:"error"`
+}
+
+// ReconcileDiff 配置对账结果
+type ReconcileDiff struct {
+	TokensCreated []string           `json:"tokens_created"`
+	TokensUpdated []string           `json:"tokens_updated"`
+	DexesCreated  []string           `json:"dexes_created"`
+	DexesUpdated  []DexChange        `json:"dexes_updated"`
+	Unchanged     int                `json:"unchanged"`
+	Failed        []ReconcileFailure `json:"failed"`
+}
+
+// addFailure 记录失败的条目（继续处理其余条目）
+func (d *ReconcileDiff) addFailure(kind, name, op string, err error) {
+	log.Printf("❌ %s %s %s 失败: %v", kind, name, op, err)
+	d.Failed = append(d.Failed, ReconcileFailure{Kind: kind, Name: name, Op: op, Error: err.Error()})
+}
+
+// Err 有条目同步失败时返回汇总错误（列出每个失败的条目），全部成功时返回 nil
+func (d *ReconcileDiff) Err() error {
+	if len(d.Failed) == 0 {
+		return nil
+	}
+
+	items := make([]string, len(d.Failed))
+	for i, f := range d.Failed {
+		items[i] = fmt.Sprintf("%s %s %s: %s", f.Kind, f.Name, f.Op, f.Error)
+	}
+	return fmt.Errorf("%d 个条目同步失败: %s", len(d.Failed), strings.Join(items, "; "))
+}
+
+// HasChanges 是否有任何变更
+func (d *ReconcileDiff) HasChanges() bool {
+	return len(d.TokensCreated) > 0 || len(d.TokensUpdated) > 0 ||
+		len(d.DexesCreated) > 0 || len(d.DexesUpdated) > 0
+}
+
+// Summary 变更摘要
+func (d *ReconcileDiff) Summary() string {
+	return fmt.Sprintf("新增代币=%d, 更新代币=%d, 新增DEX=%d, 更新DEX=%d, 未变化=%d, 失败=%d",
+		len(d.TokensCreated), len(d.TokensUpdated), len(d.DexesCreated), len(d.DexesUpdated), d.Unchanged, len(d.Failed))
+}
+
+// ReconcileConfig 将配置中的代币和 DEX 同步到数据库
+// 幂等：只创建缺失的记录、只更新有变化的字段，可在运行时重复调用
+// 采集任务每轮都会重新查询 DEX 和代币，因此变更在下一轮采集时生效，不会打断进行中的采集。
+// 瞬时数据库错误会重试；仍然失败的条目记录在 diff.Failed 中，其余条目照常同步，由调用方通过 diff.Err() 决定如何处理
+// ctx 取消后不再重试，剩余条目以取消错误记入 diff.Failed
+func ReconcileConfig(ctx context.Context, cfg *config.Config) *ReconcileDiff {
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+
+	diff := &ReconcileDiff{}
+	reconcileTokens(ctx, cfg, diff)
+	reconcileDexes(ctx, cfg, diff)
+
+	return diff
+}
+
+// reconcileTokens 同步代币配置
+func reconcileTokens(ctx context.Context, cfg *config.Config, diff *ReconcileDiff) {
+	tokens := GetTokenRepository()
+
+	for _, tokenCfg := range cfg.Tokens {
+		var token *models.Token
+		err := withRetry(ctx, "查询代币 "+tokenCfg.Symbol, func() (err error) {
+			token, err = tokens.GetByAddress(tokenCfg.Address)
+			return err
+		})
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 代币不存在，创建新记录
+			token = &models.Token{
+				Address:  tokenCfg.Address,
+				Symbol:   tokenCfg.Symbol,
+				Name:     tokenCfg.Symbol, // 可以后续更新
+				Decimals: tokenCfg.Decimals,
+				ChainID:  cfg.Blockchain.ChainID,
+				IsActive: true,
+
+				CoingeckoID: tokenCfg.CoingeckoID,
+				IsRebasing:  tokenCfg.IsRebasing,
+			}
+			if err := withRetry(ctx, "创建代币 "+tokenCfg.Symbol, func() error { return tokens.Create(token) }); err != nil {
+				diff.addFailure("token", tokenCfg.Symbol, "create", err)
+				continue
+			}
+			log.Printf("创建代币: %s (%s)", tokenCfg.Symbol, tokenCfg.Address)
+			diff.TokensCreated = append(diff.TokensCreated, tokenCfg.Symbol)
+			continue
+		}
+		if err != nil {
+			diff.addFailure("token", tokenCfg.Symbol, "query", err)
+			continue
+		}
+
+		// 代币已存在，仅同步配置中管理的字段
+		if token.Symbol == tokenCfg.Symbol && token.Decimals == tokenCfg.Decimals &&
+			token.CoingeckoID == tokenCfg.CoingeckoID && token.IsRebasing == tokenCfg.IsRebasing {
+			diff.Unchanged++
+			continue
+		}
+
+		token.Symbol = tokenCfg.Symbol
+		token.Decimals = tokenCfg.Decimals
+		token.CoingeckoID = tokenCfg.CoingeckoID
+		token.IsRebasing = tokenCfg.IsRebasing
+		if err := withRetry(ctx, "更新代币 "+tokenCfg.Symbol, func() error { return tokens.Save(token) }); err != nil {
+			diff.addFailure("token", tokenCfg.Symbol, "update", err)
+			continue
+		}
+		log.Printf("更新代币: %s (%s)", tokenCfg.Symbol, tokenCfg.Address)
+		diff.TokensUpdated = append(diff.TokensUpdated, tokenCfg.Symbol)
+	}
+}
+
+// reconcileDexes 同步 DEX 配置
+func reconcileDexes(ctx context.Context, cfg *config.Config, diff *ReconcileDiff) {
+	for _, dexCfg := range cfg.Dexes {
+		if err := web3.ValidateQuoterType(dexCfg.QuoterType); err != nil {
+			diff.addFailure("dex", dexCfg.Name, "validate", err)
+			continue
+		}
+
+		desired := dexFromConfig(&dexCfg, cfg.Blockchain.ChainID)
+
+		var dex models.Dex
+		err := withRetry(ctx, "查询 DEX "+dexCfg.Name, func() error {
+			return db.WithContext(ctx).Where("name = ?", dexCfg.Name).First(&dex).Error
+		})
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// DEX 不存在，创建新记录
+			if err := withRetry(ctx, "创建 DEX "+dexCfg.Name, func() error { return db.WithContext(ctx).Create(desired).Error }); err != nil {
+				diff.addFailure("dex", dexCfg.Name, "create", err)
+				continue
+			}
+			log.Printf("✅ 创建 DEX: %s (类型: %s, 协议: %s, 版本: %s)",
+				desired.Name, desired.DexType, desired.Protocol, desired.Version)
+			diff.DexesCreated = append(diff.DexesCreated, desired.Name)
+			continue
+		}
+		if err != nil {
+			diff.addFailure("dex", dexCfg.Name, "query", err)
+			continue
+		}
+
+		// DEX 已存在，只在配置有变化时更新
+		changed := applyDexConfig(&dex, desired)
+		if len(changed) == 0 {
+			diff.Unchanged++
+			continue
+		}
+
+		if err := withRetry(ctx, "更新 DEX "+dexCfg.Name, func() error { return db.WithContext(ctx).Save(&dex).Error }); err != nil {
+			diff.addFailure("dex", dexCfg.Name, "update", err)
+			continue
+		}
+		log.Printf("✅ 更新 DEX: %s (类型: %s, 协议: %s, 版本: %s, 变更: %v)",
+			dex.Name, dex.DexType, dex.Protocol, dex.Version, changed)
+		diff.DexesUpdated = append(diff.DexesUpdated, DexChange{Name: dex.Name, Fields: changed})
+	}
+}
+
+// dexFromConfig 根据配置构造 DEX 记录（填充默认值）
+func dexFromConfig(dexCfg *config.DexConfig, defaultChainID int64) *models.Dex {
+	// 设置默认值
+	protocol := dexCfg.Protocol
+	if protocol == "" {
+		protocol = "uniswap_v2"
+	}
+
+	version := dexCfg.Version
+	if version == "" {
+		version = "v2"
+	}
+
+	chainID := dexCfg.ChainID
+	if chainID == 0 {
+		chainID = defaultChainID
+	}
+
+	dexType := dexCfg.DexType
+	if dexType == "" {
+		dexType = "amm" // 默认为 AMM
+	}
+
+	priority := dexCfg.Priority
+	if priority == 0 {
+		priority = 100 // 默认优先级
+	}
+
+	quoterType := dexCfg.QuoterType
+	if quoterType == "" && dexCfg.Quoter != "" {
+		quoterType = web3.QuoterTypeUniswapV2 // 默认使用 Uniswap QuoterV2 ABI
+	}
+
+	reserveStyle := dexCfg.ReserveStyle
+	if reserveStyle == "" {
+		reserveStyle = "combined" // 默认使用 getReserves()
+	}
+
+	return &models.Dex{
+		Name:             dexCfg.Name,
+		DexType:          dexType,
+		Protocol:         protocol,
+		RouterAddress:    dexCfg.Router,
+		FactoryAddress:   dexCfg.Factory,
+		QuoterAddress:    dexCfg.Quoter,
+		QuoterType:       quoterType,
+		Fee:              dexCfg.Fee,
+		FeeTier:          dexCfg.FeeTier,
+		FeeTiers:         dexCfg.FeeTiers,
+		DynamicFee:       dexCfg.DynamicFee,
+		ReserveStyle:     reserveStyle,
+		ChainID:          chainID,
+		IsActive:         true,
+		SupportFlashLoan: dexCfg.SupportFlashLoan,
+		SupportMultiHop:  dexCfg.SupportMultiHop,
+		SupportV3Ticks:   dexCfg.SupportV3Ticks,
+		Version:          version,
+		Priority:         priority,
+	}
+}
+
+// applyDexConfig 将配置值写入已有 DEX 记录，返回发生变化的字段名
+// IsActive 不由配置管理（允许在数据库中手动停用），因此不会被覆盖
+func applyDexConfig(dex, desired *models.Dex) []string {
+	changed := make([]string, 0)
+
+	setString := func(field string, dst *string, src string) {
+		if *dst != src {
+			*dst = src
+			changed = append(changed, field)
+		}
+	}
+	setBool := func(field string, dst *bool, src bool) {
+		if *dst != src {
+			*dst = src
+			changed = append(changed, field)
+		}
+	}
+
+	setString("dex_type", &dex.DexType, desired.DexType)
+	setString("protocol", &dex.Protocol, desired.Protocol)
+	setString("router_address", &dex.RouterAddress, desired.RouterAddress)
+	setString("factory_address", &dex.FactoryAddress, desired.FactoryAddress)
+	setString("quoter_address", &dex.QuoterAddress, desired.QuoterAddress)
+	setString("quoter_type", &dex.QuoterType, desired.QuoterType)
+	setString("reserve_style", &dex.ReserveStyle, desired.ReserveStyle)
+	setString("version", &dex.Version, desired.Version)
+	setBool("dynamic_fee", &dex.DynamicFee, desired.DynamicFee)
+	setBool("support_flash_loan", &dex.SupportFlashLoan, desired.SupportFlashLoan)
+	setBool("support_multi_hop", &dex.SupportMultiHop, desired.SupportMultiHop)
+	setBool("support_v3_ticks", &dex.SupportV3Ticks, desired.SupportV3Ticks)
+
+	if dex.Fee != desired.Fee {
+		dex.Fee = desired.Fee
+		changed = append(changed, "fee")
+	}
+	if dex.FeeTier != desired.FeeTier {
+		dex.FeeTier = desired.FeeTier
+		changed = append(changed, "fee_tier")
+	}
+	if !equalFeeTiers(dex.FeeTiers, desired.FeeTiers) {
+		dex.FeeTiers = desired.FeeTiers
+		changed = append(changed, "fee_tiers")
+	}
+	if dex.ChainID != desired.ChainID {
+		dex.ChainID = desired.ChainID
+		changed = append(changed, "chain_id")
+	}
+	if dex.Priority != desired.Priority {
+		dex.Priority = desired.Priority
+		changed = append(changed, "priority")
+	}
+
+	return changed
+}
+
+// equalFeeTiers 比较两个费率层级列表
+func equalFeeTiers(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/defi-bot/backend/internal/config"
+	"github.com/defi-bot/backend/internal/models"
+)
+
+// fullDexYAML 每个 DexConfig 字段都设置为非默认值的配置
+const fullDexYAML = `
+blockchain:
+  chain_id: 1
+dexes:
+  - name: "PancakeSwap V3"
+    dex_type: "hybrid"
+    protocol: "uniswap_v3"
+    router: "0x1b81D678ffb9C0263b24A97847620C99d213eB14"
+    factory: "0x0BFbCF9fa4f9C56B0F40a671Ad40E0805A091865"
+    quoter: "0xB048Bbc1Ee6b733FFfCFb9e9CeF7375518e25997"
+    quoter_type: "pancake_v3"
+    fee: 25
+    fee_tier: 2500
+    fee_tiers: [100, 500, 2500, 10000]
+    dynamic_fee: true
+    reserve_style: "separate"
+    version: "v3"
+    chain_id: 56
+    support_flash_loan: true
+    support_multi_hop: true
+    support_v3_ticks: true
+    priority: 5
+`
+
+// loadFullDexConfig 从临时文件加载 fullDexYAML，并确认 DexConfig 的每个字段都被设置
+// 新增 DexConfig 字段而没有更新 fullDexYAML 时测试失败
+func loadFullDexConfig(t *testing.T) config.DexConfig {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(fullDexYAML), 0o600); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if len(cfg.Dexes) != 1 {
+		t.Fatalf("DEX 数量 = %d, 期望 1", len(cfg.Dexes))
+	}
+
+	dexCfg := cfg.Dexes[0]
+	value := reflect.ValueOf(dexCfg)
+	for i := 0; i < value.NumField(); i++ {
+		if value.Field(i).IsZero() {
+			t.Errorf("DexConfig.%s 未从 YAML 读取（tag %q）", value.Type().Field(i).Name, value.Type().Field(i).Tag.Get("mapstructure"))
+		}
+	}
+	return dexCfg
+}
+
+func TestDexFromConfigRoundTrip(t *testing.T) {
+	dexCfg := loadFullDexConfig(t)
+
+	got := dexFromConfig(&dexCfg, 1)
+	want := &models.Dex{
+		Name:             "PancakeSwap V3",
+		DexType:          "hybrid",
+		Protocol:         "uniswap_v3",
+		RouterAddress:    "0x1b81D678ffb9C0263b24A97847620C99d213eB14",
+		FactoryAddress:   "0x0BFbCF9fa4f9C56B0F40a671Ad40E0805A091865",
+		QuoterAddress:    "0xB048Bbc1Ee6b733FFfCFb9e9CeF7375518e25997",
+		QuoterType:       "pancake_v3",
+		Fee:              25,
+		FeeTier:          2500,
+		FeeTiers:         []uint32{100, 500, 2500, 10000},
+		DynamicFee:       true,
+		ReserveStyle:     "separate",
+		Version:          "v3",
+		ChainID:          56,
+		IsActive:         true,
+		SupportFlashLoan: true,
+		SupportMultiHop:  true,
+		SupportV3Ticks:   true,
+		Priority:         5,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dexFromConfig =\n%+v\n期望\n%+v", got, want)
+	}
+}
+
+func TestApplyDexConfigRoundTrip(t *testing.T) {
+	dexCfg := loadFullDexConfig(t)
+	desired := dexFromConfig(&dexCfg, 1)
+
+	// 已有记录的所有配置字段都与期望值不同（IsActive 不由配置管理）
+	existing := &models.Dex{ID: 7, Name: desired.Name, IsActive: false}
+	changed := applyDexConfig(existing, desired)
+
+	wantChanged := []string{
+		"chain_id", "dex_type", "dynamic_fee", "factory_address", "fee", "fee_tier", "fee_tiers",
+		"priority", "protocol", "quoter_address", "quoter_type", "reserve_style", "router_address",
+		"support_flash_loan", "support_multi_hop", "support_v3_ticks", "version",
+	}
+	sort.Strings(changed)
+	if !reflect.DeepEqual(changed, wantChanged) {
+		t.Errorf("变化字段 = %v, 期望 %v", changed, wantChanged)
+	}
+
+	applied := *existing
+	applied.ID, applied.IsActive = desired.ID, desired.IsActive
+	if !reflect.DeepEqual(&applied, desired) {
+		t.Errorf("同步后的记录 =\n%+v\n期望\n%+v", &applied, desired)
+	}
+	if existing.ID != 7 || existing.IsActive {
+		t.Errorf("ID / IsActive 不应被配置覆盖: id=%d active=%v", existing.ID, existing.IsActive)
+	}
+
+	// 再次同步相同配置没有变化
+	if again := applyDexConfig(existing, desired); len(again) != 0 {
+		t.Errorf("重复同步仍有变化字段: %v", again)
+	}
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"time"
+)
+
+// 瞬时数据库错误的重试参数
+const (
+	dbRetryAttempts = 3
+	dbRetryBackoff  = 200 * time.Millisecond
+)
+
+// withRetry 执行数据库操作，遇到瞬时错误（连接中断、死锁、序列化冲突等）时按指数退避重试
+// 约束冲突、数据错误等非瞬时错误直接返回，重试也不会成功
+// ctx 取消（如 /admin/reload 的客户端断开、进程关闭）时立即停止退避并返回 ctx.Err()
+func withRetry(ctx context.Context, op string, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < dbRetryAttempts; attempt++ {
+		if attempt > 0 {
+			backoff := dbRetryBackoff << (attempt - 1)
+			log.Printf("⚠️  %s 失败（第 %d 次），%v 后重试: %v", op, attempt, backoff, err)
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("%s 已取消: %w", op, ctx.Err())
+			}
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("%s 已取消: %w", op, ctxErr)
+		}
+
+		err = fn()
+		if err == nil || !isTransientDBError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// sqlStateError 带 SQLSTATE 的驱动错误（pgconn.PgError 实现了该接口）
+type sqlStateError interface {
+	SQLState() string
+}
+
+// isTransientDBError 判断错误是否为瞬时错误（重试可能成功）
+func isTransientDBError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		code := stateErr.SQLState()
+		switch {
+		case strings.HasPrefix(code, "08"): // connection_exception
+			return true
+		case code == "40001", code == "40P01": // serialization_failure, deadlock_detected
+			return true
+		case code == "53300", code == "57P01", code == "57P03": // too_many_connections, admin_shutdown, cannot_connect_now
+			return true
+		}
+	}
+
+	return false
+}
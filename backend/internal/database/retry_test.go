@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithRetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	err := withRetry(ctx, "测试操作", func() error {
+		calls++
+		return driver.ErrBadConn
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, 期望 context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Errorf("调用次数 = %d, 期望 1（退避期间取消后不应再次执行）", calls)
+	}
+	if elapsed := time.Since(start); elapsed >= dbRetryBackoff {
+		t.Errorf("取消后 %v 才返回，应在退避结束前返回", elapsed)
+	}
+}
+
+func TestWithRetryCancelledBeforeStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	err := withRetry(ctx, "测试操作", func() error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || called {
+		t.Errorf("err = %v, called = %v, 期望直接返回 context.Canceled 且不执行操作", err, called)
+	}
+}
+
+func TestWithRetryRetriesTransientErrors(t *testing.T) {
+	calls := 0
+	err := withRetry(context.Background(), "测试操作", func() error {
+		calls++
+		if calls < 2 {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("err = %v, calls = %d, 期望第 2 次成功", err, calls)
+	}
+}
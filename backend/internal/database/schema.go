@@ -0,0 +1,20 @@
+package database
+
+import "github.com/defi-bot/backend/internal/models"
+
+// migratedModels 需要迁移的全部模型
+// 新增带 TableName() 的模型后必须加到这里，否则表不会创建（schema_test.go 会检查遗漏）
+var migratedModels = []interface{}{
+	&models.Token{},
+	&models.Dex{},
+	&models.TradingPair{},
+	&models.PairReserve{},
+	&models.PriceRecord{},
+	&models.LiquidityDepth{},
+	&models.GasPriceHistory{},
+	&models.ArbitrageOpportunity{},
+	&models.ArbitrageExecution{},
+	&models.StrategyPerformance{},
+	&models.Setting{},
+	&models.PairLatest{},
+}
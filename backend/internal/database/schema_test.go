@@ -0,0 +1,112 @@
+package database
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/defi-bot/backend/internal/testutil/pgtest"
+	"gorm.io/gorm"
+)
+
+// tableModelNames 扫描 models 包源码，返回所有带 TableName() 方法的导出类型名
+// Go 反射无法枚举包内类型，只能从源码收集，再与 migratedModels 对照
+func tableModelNames(t *testing.T) []string {
+	t.Helper()
+
+	pkgs, err := parser.ParseDir(token.NewFileSet(), "../models", func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("解析 models 包失败: %v", err)
+	}
+
+	var names []string
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil || fn.Name.Name != "TableName" || len(fn.Recv.List) != 1 {
+					continue
+				}
+				recv := fn.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); ok && ident.IsExported() {
+					names = append(names, ident.Name)
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// migratedModelNames migratedModels 中各模型的类型名
+func migratedModelNames() map[string]interface{} {
+	names := make(map[string]interface{}, len(migratedModels))
+	for _, model := range migratedModels {
+		names[reflect.TypeOf(model).Elem().Name()] = model
+	}
+	return names
+}
+
+// TestMigratedModelsComplete 每个带 TableName() 的模型都必须注册到 migratedModels
+func TestMigratedModelsComplete(t *testing.T) {
+	names := tableModelNames(t)
+	if len(names) == 0 {
+		t.Fatal("models 包中没有找到带 TableName() 的类型")
+	}
+
+	registered := migratedModelNames()
+	for _, name := range names {
+		if _, ok := registered[name]; !ok {
+			t.Errorf("models.%s 定义了 TableName() 但未加入 migratedModels", name)
+		}
+	}
+}
+
+// TestAutoMigrateSchema 在临时 schema 中执行 AutoMigrate，检查每个模型的表和声明的索引都已创建
+func TestAutoMigrateSchema(t *testing.T) {
+	testDB := pgtest.Open(t)
+
+	saved := db
+	db = testDB
+	t.Cleanup(func() { db = saved })
+
+	if err := AutoMigrate(); err != nil {
+		t.Fatalf("AutoMigrate 失败: %v", err)
+	}
+
+	registered := migratedModelNames()
+	migrator := testDB.Migrator()
+	for _, name := range tableModelNames(t) {
+		model, ok := registered[name]
+		if !ok {
+			continue // 由 TestMigratedModelsComplete 报告
+		}
+
+		stmt := &gorm.Statement{DB: testDB}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatalf("解析模型 %s 失败: %v", name, err)
+		}
+		table := stmt.Schema.Table
+
+		if !migrator.HasTable(model) {
+			t.Errorf("表 %s（models.%s）不存在", table, name)
+			continue
+		}
+
+		for indexName := range stmt.Schema.ParseIndexes() {
+			if !migrator.HasIndex(model, indexName) {
+				t.Errorf("索引 %s.%s（models.%s）不存在", table, indexName, name)
+			}
+		}
+	}
+}
@@ -1,30 +1,39 @@
 package models
 
 import (
+	"math/big"
 	"time"
+
+	"github.com/defi-bot/backend/pkg/units"
 )
 
 // ArbitrageExecution 套利执行记录表
 type ArbitrageExecution struct {
 	ID              uint      `gorm:"primaryKey" json:"id"`
-	OpportunityID   uint      `gorm:"index" json:"opportunity_id"`                    // 套利机会 ID（可为空，手动执行时）
-	VaultAddress    string    `gorm:"index;size:42" json:"vault_address"`             // 金库地址
-	TokenInID       uint      `gorm:"index;not null" json:"token_in_id"`              // 输入代币 ID
-	TokenOutID      uint      `gorm:"not null" json:"token_out_id"`                   // 输出代币 ID
-	AmountIn        string    `gorm:"type:varchar(78);not null" json:"amount_in"`     // 输入金额
-	AmountOut       string    `gorm:"type:varchar(78);not null" json:"amount_out"`    // 输出金额
-	ActualProfit    string    `gorm:"type:varchar(78);not null" json:"actual_profit"` // 实际利润
-	ProfitRate      float64   `gorm:"not null" json:"profit_rate"`                    // 利润率（百分比）
-	SwapPath        string    `gorm:"type:text;not null" json:"swap_path"`            // 交易路径（JSON 数组）
-	DexPath         string    `gorm:"type:text;not null" json:"dex_path"`             // DEX 路径（JSON 数组）
-	GasUsed         uint64    `gorm:"not null" json:"gas_used"`                       // 实际 Gas 消耗
-	GasPrice        string    `gorm:"type:varchar(78);not null" json:"gas_price"`     // Gas 价格（wei）
-	TxHash          string    `gorm:"uniqueIndex;not null;size:66" json:"tx_hash"`    // 交易哈希
-	BlockNumber     uint64    `gorm:"index;not null" json:"block_number"`             // 区块号
-	Status          string    `gorm:"index;not null;size:20" json:"status"`           // 状态：pending, success, failed
-	ErrorMessage    string    `gorm:"type:text" json:"error_message"`                 // 错误信息
-	ExecutionTimeMs int64     `gorm:"not null" json:"execution_time_ms"`              // 执行时间（毫秒）
-	Timestamp       time.Time `gorm:"index;not null" json:"timestamp"`                // 时间戳
+	OpportunityID   uint      `gorm:"index;uniqueIndex:idx_exec_opp_nonce_key,where:opportunity_id <> 0 AND nonce <> 0" json:"opportunity_id"` // 套利机会 ID（可为空，手动执行时）
+	Nonce           uint64    `gorm:"uniqueIndex:idx_exec_opp_nonce_key" json:"nonce"`                                                         // 交易 nonce（与机会 ID 组成幂等键，重试时复用同一条记录；手动执行和历史记录为 0，不参与唯一约束）
+	VaultAddress    string    `gorm:"index;size:42" json:"vault_address"`                                                                      // 金库地址
+	TokenInID       uint      `gorm:"index;not null" json:"token_in_id"`                                                                       // 输入代币 ID
+	TokenOutID      uint      `gorm:"not null" json:"token_out_id"`                                                                            // 输出代币 ID
+	AmountIn        string    `gorm:"type:varchar(78);not null" json:"amount_in"`                                                              // 输入金额
+	AmountOut       string    `gorm:"type:varchar(78);not null" json:"amount_out"`                                                             // 输出金额
+	ActualProfit    string    `gorm:"type:varchar(78);not null" json:"actual_profit"`                                                          // 实际利润
+	ProfitRate      float64   `gorm:"not null" json:"profit_rate"`                                                                             // 利润率（百分比）
+	NetProfit       *string   `gorm:"type:varchar(78)" json:"net_profit"`                                                                      // 扣除 Gas 后的净利润（输入代币最小单位，Gas 按完成时价格折算；价格未知时为 NULL）
+	NetProfitUSD    *float64  `json:"net_profit_usd"`                                                                                          // 扣除 Gas 后的净利润（美元，完成时价格；价格未知时为 NULL）
+	ROI             *float64  `json:"roi"`                                                                                                     // 净利润 / 输入金额（价格未知时为 NULL）
+	SwapPath        string    `gorm:"type:text;not null" json:"swap_path"`                                                                     // 交易路径（JSON 数组）
+	DexPath         string    `gorm:"type:text;not null" json:"dex_path"`                                                                      // DEX 路径（JSON 数组）
+	GasUsed         uint64    `gorm:"not null" json:"gas_used"`                                                                                // 实际 Gas 消耗
+	GasPrice        string    `gorm:"type:varchar(78);not null" json:"gas_price"`                                                              // Gas 价格（wei）
+	TxHash          string    `gorm:"uniqueIndex:idx_exec_tx_hash,where:tx_hash <> '';size:66" json:"tx_hash"`                                 // 交易哈希（发送前为空）
+	BlockNumber     uint64    `gorm:"index;not null" json:"block_number"`                                                                      // 区块号
+	Status          string    `gorm:"index;not null;size:20" json:"status"`                                                                    // 状态：pending, success, failed
+	ErrorMessage    string    `gorm:"type:text" json:"error_message"`                                                                          // 错误信息
+	ExecutionTimeMs int64     `gorm:"not null" json:"execution_time_ms"`                                                                       // 执行时间（毫秒）
+	BlockDrift      *int64    `json:"block_drift"`                                                                                             // 区块漂移（上链区块 - 发现区块，0 表示同块上链），NULL 表示未记录
+	LatencyMs       *int64    `json:"latency_ms"`                                                                                              // 从首次发现机会到收到回执的时间（毫秒），NULL 表示未记录
+	Timestamp       time.Time `gorm:"index;not null" json:"timestamp"`                                                                         // 时间戳
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 
@@ -38,3 +47,83 @@ type ArbitrageExecution struct {
 func (ArbitrageExecution) TableName() string {
 	return "arbitrage_executions"
 }
+
+// GasCost Gas 成本（wei）
+func (e *ArbitrageExecution) GasCost() *big.Int {
+	gasCost := big.NewInt(0)
+	if gasPrice, ok := new(big.Int).SetString(e.GasPrice, 10); ok {
+		gasCost.Mul(new(big.Int).SetUint64(e.GasUsed), gasPrice)
+	}
+	return gasCost
+}
+
+// NetProfitUSDAt 按给定的原生币价格计算含 Gas 的实际盈亏（美元，需预加载 TokenIn）
+// 失败的执行没有利润，只计 Gas 成本；需要的价格未知（<= 0）时返回 false
+func (e *ArbitrageExecution) NetProfitUSDAt(nativePriceUSD float64) (float64, bool) {
+	if nativePriceUSD <= 0 {
+		return 0, false
+	}
+	gasUSD := units.ToFloat(e.GasCost(), units.EtherDecimals) * nativePriceUSD
+
+	if e.Status != "success" {
+		return -gasUSD, true
+	}
+
+	profit, ok := new(big.Int).SetString(e.ActualProfit, 10)
+	if !ok || e.TokenIn.PriceUSD <= 0 {
+		return 0, false
+	}
+	return units.ToFloat(profit, e.TokenIn.Decimals)*e.TokenIn.PriceUSD - gasUSD, true
+}
+
+// NetProfitAt 按给定的原生币价格计算扣除 Gas 后的净利润（输入代币最小单位，需预加载 TokenIn）
+// 利润保持整数精度，只有 Gas 成本按两种代币的价格比换算；价格未知（<= 0）时返回 false
+func (e *ArbitrageExecution) NetProfitAt(nativePriceUSD float64) (*big.Int, bool) {
+	if nativePriceUSD <= 0 || e.TokenIn.PriceUSD <= 0 {
+		return nil, false
+	}
+
+	profit := big.NewInt(0)
+	if e.Status == "success" {
+		var ok bool
+		if profit, ok = new(big.Int).SetString(e.ActualProfit, 10); !ok {
+			return nil, false
+		}
+	}
+
+	// gasInTokenIn = gasWei × nativePrice × 10^decimals / (tokenPrice × 10^18)
+	gas := new(big.Rat).SetInt(e.GasCost())
+	gas.Mul(gas, new(big.Rat).SetFloat64(nativePriceUSD))
+	gas.Mul(gas, new(big.Rat).SetInt(units.Pow10(e.TokenIn.Decimals)))
+	gas.Quo(gas, new(big.Rat).SetFloat64(e.TokenIn.PriceUSD))
+	gas.Quo(gas, new(big.Rat).SetInt(units.Pow10(units.EtherDecimals)))
+
+	gasInTokenIn := new(big.Int).Quo(gas.Num(), gas.Denom())
+	return profit.Sub(profit, gasInTokenIn), true
+}
+
+// Valuate 按当前价格计算 NetProfit、NetProfitUSD 和 ROI（需预加载 TokenIn）
+// Gas 成本以原生币计价，需要输入代币和原生币的美元价格才能折算；任一价格未知时三项都为空（NULL）并返回 false
+func (e *ArbitrageExecution) Valuate(nativePriceUSD float64) bool {
+	e.NetProfit, e.NetProfitUSD, e.ROI = nil, nil, nil
+
+	netUSD, ok := e.NetProfitUSDAt(nativePriceUSD)
+	if !ok {
+		return false
+	}
+	net, ok := e.NetProfitAt(nativePriceUSD)
+	if !ok {
+		return false
+	}
+
+	netProfit := net.String()
+	e.NetProfit = &netProfit
+	e.NetProfitUSD = &netUSD
+
+	// ROI = 净利润 / 输入金额（同一代币最小单位，直接按整数比计算）
+	if amountIn, ok := new(big.Int).SetString(e.AmountIn, 10); ok && amountIn.Sign() > 0 {
+		roi, _ := new(big.Rat).SetFrac(net, amountIn).Float64()
+		e.ROI = &roi
+	}
+	return true
+}
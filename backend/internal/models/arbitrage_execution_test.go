@@ -0,0 +1,82 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestArbitrageExecutionValuate(t *testing.T) {
+	usdc := Token{Symbol: "USDC", Decimals: 6, PriceUSD: 1}
+	weth := Token{Symbol: "WETH", Decimals: 18, PriceUSD: 2000}
+
+	// Gas: 100000 × 20 gwei = 0.002 ETH，按 $2000 为 $4（= 4 USDC = 4_000_000）
+	tests := []struct {
+		name      string
+		exec      ArbitrageExecution
+		nativeUSD float64
+		wantOK    bool
+		wantNet   string
+		wantUSD   float64
+		wantROI   float64
+	}{
+		{
+			name: "成功执行扣除 Gas",
+			exec: ArbitrageExecution{Status: "success", TokenIn: usdc, AmountIn: "1000000000",
+				ActualProfit: "10000000", GasUsed: 100000, GasPrice: "20000000000"},
+			nativeUSD: 2000, wantOK: true, wantNet: "6000000", wantUSD: 6, wantROI: 0.006,
+		},
+		{
+			name: "失败执行只计 Gas",
+			exec: ArbitrageExecution{Status: "failed", TokenIn: usdc, AmountIn: "1000000000",
+				ActualProfit: "0", GasUsed: 100000, GasPrice: "20000000000"},
+			nativeUSD: 2000, wantOK: true, wantNet: "-4000000", wantUSD: -4, wantROI: -0.004,
+		},
+		{
+			// 超过 float64 精度的利润保持整数精度
+			name: "大额利润不丢精度",
+			exec: ArbitrageExecution{Status: "success", TokenIn: weth, AmountIn: "1000000000000000000000",
+				ActualProfit: "123456789012345678901", GasUsed: 0, GasPrice: "0"},
+			nativeUSD: 2000, wantOK: true, wantNet: "123456789012345678901",
+			wantUSD: 123.456789012345678901 * 2000, wantROI: 0.123456789012345678901,
+		},
+		{
+			name: "输入代币价格未知",
+			exec: ArbitrageExecution{Status: "success", TokenIn: Token{Decimals: 6}, AmountIn: "1000",
+				ActualProfit: "10", GasUsed: 100000, GasPrice: "20000000000"},
+			nativeUSD: 2000, wantOK: false,
+		},
+		{
+			name: "原生币价格未知",
+			exec: ArbitrageExecution{Status: "failed", TokenIn: usdc, AmountIn: "1000",
+				ActualProfit: "0", GasUsed: 100000, GasPrice: "20000000000"},
+			nativeUSD: 0, wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := tt.exec
+			ok := exec.Valuate(tt.nativeUSD)
+			if ok != tt.wantOK {
+				t.Fatalf("Valuate = %v, 期望 %v", ok, tt.wantOK)
+			}
+
+			if !tt.wantOK {
+				if exec.NetProfit != nil || exec.NetProfitUSD != nil || exec.ROI != nil {
+					t.Errorf("价格未知时应为空: net=%v usd=%v roi=%v", exec.NetProfit, exec.NetProfitUSD, exec.ROI)
+				}
+				return
+			}
+
+			if exec.NetProfit == nil || *exec.NetProfit != tt.wantNet {
+				t.Errorf("NetProfit = %v, 期望 %s", exec.NetProfit, tt.wantNet)
+			}
+			if exec.NetProfitUSD == nil || math.Abs(*exec.NetProfitUSD-tt.wantUSD) > 1e-9*math.Max(1, math.Abs(tt.wantUSD)) {
+				t.Errorf("NetProfitUSD = %v, 期望 %v", exec.NetProfitUSD, tt.wantUSD)
+			}
+			if exec.ROI == nil || math.Abs(*exec.ROI-tt.wantROI) > 1e-12 {
+				t.Errorf("ROI = %v, 期望 %v", exec.ROI, tt.wantROI)
+			}
+		})
+	}
+}
@@ -1,25 +1,37 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
+// ErrOpportunityExpired 套利机会已过期（超过有效时间或有效区块）
+var ErrOpportunityExpired = errors.New("套利机会已过期")
+
+// ErrNonCyclicPath 交易路径不是闭环（起止代币不同），ExpectedProfit = 输出 - 输入 没有意义
+var ErrNonCyclicPath = errors.New("套利路径不是闭环")
+
 // ArbitrageOpportunity 套利机会表
 type ArbitrageOpportunity struct {
 	ID         uint `gorm:"primaryKey" json:"id"`
-	TokenInID  uint `gorm:"index;not null" json:"token_in_id"` // 输入代币 ID
-	TokenOutID uint `gorm:"not null" json:"token_out_id"`      // 输出代币 ID（中间代币）
+	TokenInID  uint `gorm:"index;index:idx_opp_token_status_time,priority:1;index:idx_opp_pending_best,priority:1,where:status = 'pending';not null" json:"token_in_id"` // 输入代币 ID
+	TokenOutID uint `gorm:"index:idx_opp_pending_best,priority:2;not null" json:"token_out_id"`                                                                          // 输出代币 ID（中间代币）
 
 	// === 套利类型标识 ===
 	ArbitrageType string `gorm:"index;size:20;not null;default:'cross_dex'" json:"arbitrage_type"`
 	// 类型：cross_dex（跨DEX）, fee_tier（V3费率套利）, triangular（三角套利）, flash_loan（闪电贷套利）
 
 	// === 金额和利润 ===
-	AmountIn       string  `gorm:"type:varchar(78);not null" json:"amount_in"`       // 输入金额
-	ExpectedProfit string  `gorm:"type:varchar(78);not null" json:"expected_profit"` // 预期利润
-	MinProfit      string  `gorm:"type:varchar(78);not null" json:"min_profit"`      // 最小利润（合约需要）
-	ProfitRate     float64 `gorm:"not null" json:"profit_rate"`                      // 利润率（百分比）
-	MinProfitUSD   float64 `gorm:"default:0" json:"min_profit_usd"`                  // 最小美元利润
+	AmountIn       string  `gorm:"type:varchar(78);not null" json:"amount_in"`                                  // 输入金额
+	ExpectedProfit string  `gorm:"type:varchar(78);not null" json:"expected_profit"`                            // 预期利润
+	MinProfit      string  `gorm:"type:varchar(78);not null" json:"min_profit"`                                 // 最小利润（合约需要）
+	ProfitRate     float64 `gorm:"index:idx_opp_pending_best,priority:3,sort:desc;not null" json:"profit_rate"` // 利润率（百分比），pending 部分索引支撑每个代币对的最佳机会查询
+	MinProfitUSD   float64 `gorm:"default:0" json:"min_profit_usd"`                                             // 最小美元利润
 
 	// === 路径信息 ===
 	SwapPath   string `gorm:"type:jsonb;not null" json:"swap_path"`   // 交易路径（JSON 数组，代币地址）
@@ -30,6 +42,9 @@ type ArbitrageOpportunity struct {
 	PoolAddresses string `gorm:"type:jsonb" json:"pool_addresses"` // V3 池地址数组 ["0xabc...", "0xdef..."]
 	FeeTiers      string `gorm:"type:jsonb" json:"fee_tiers"`      // V3 费率数组 [500, 10000]
 
+	// 路径签名（套利类型 + 代币路径 + DEX 路径 + 池地址 + 费率的哈希），用于合并重复发现的同一机会
+	PathSignature string `gorm:"index;size:64" json:"path_signature"`
+
 	// === 滑点和深度 ===
 	ExpectedSlippage   float64 `gorm:"default:0" json:"expected_slippage"`          // 预期滑点（百分比）
 	MaxSlippage        float64 `gorm:"default:1.0" json:"max_slippage"`             // 最大可接受滑点（百分比）
@@ -40,12 +55,15 @@ type ArbitrageOpportunity struct {
 	GasEstimate uint64 `gorm:"not null" json:"gas_estimate"`          // Gas 估算
 
 	// === 状态管理 ===
-	Status    string    `gorm:"index;not null;size:20;default:'pending'" json:"status"` // 状态：pending, executing, executed, expired, failed
-	Priority  int       `gorm:"index;default:0" json:"priority"`                        // 优先级（利润率高的优先）
-	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at"`                       // 过期时间
+	Status          string    `gorm:"index;index:idx_opp_token_status_time,priority:2;not null;size:20;default:'pending'" json:"status"` // 状态：pending, executing, executed, expired, failed
+	Priority        int       `gorm:"index;default:0" json:"priority"`                                                                   // 优先级（利润率高的优先）
+	ExpiresAt       time.Time `gorm:"index;not null" json:"expires_at"`                                                                  // 过期时间
+	ValidUntilBlock uint64    `gorm:"index;default:0" json:"valid_until_block"`                                                          // 有效截止区块（发现区块 + validity_blocks），0 表示不按区块过期
+	DiscoveryBlock  uint64    `gorm:"default:0" json:"discovery_block"`                                                                  // 发现（或最近一次重复发现）时的区块号，0 表示未记录
+	DiscoveredAt    time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"discovered_at"`                                           // 首次发现时间（重复发现不会刷新，用于计算发现到上链的延迟）
 
 	// === 时间戳 ===
-	CreatedAt time.Time `json:"created_at"`
+	CreatedAt time.Time `gorm:"index:idx_opp_token_status_time,priority:3" json:"created_at"` // 创建时间（参与复合索引）
 	UpdatedAt time.Time `json:"updated_at"`
 
 	// 关联
@@ -62,3 +80,47 @@ func (ArbitrageOpportunity) TableName() string {
 func (a *ArbitrageOpportunity) IsExpired() bool {
 	return time.Now().After(a.ExpiresAt)
 }
+
+// IsExpiredAt 检查在指定区块是否已过期（同时检查时间和区块）
+// 快速出块的链上时间过期太粗糙，套利机会实际只在状态改变前有效
+func (a *ArbitrageOpportunity) IsExpiredAt(currentBlock uint64) bool {
+	if a.IsExpired() {
+		return true
+	}
+	return a.ValidUntilBlock > 0 && currentBlock > a.ValidUntilBlock
+}
+
+// CheckValidAt 检查在指定区块是否仍可执行，过期时返回包装了 ErrOpportunityExpired 的错误
+func (a *ArbitrageOpportunity) CheckValidAt(currentBlock uint64) error {
+	if a.IsExpiredAt(currentBlock) {
+		return fmt.Errorf("%w: 机会 %d（有效至区块 %d，当前区块 %d）",
+			ErrOpportunityExpired, a.ID, a.ValidUntilBlock, currentBlock)
+	}
+	return nil
+}
+
+// ComputePathSignature 计算路径签名（同一路径在不同周期重复发现时签名相同）
+func (a *ArbitrageOpportunity) ComputePathSignature() string {
+	key := strings.Join([]string{
+		a.ArbitrageType,
+		strings.ToLower(a.SwapPath),
+		a.DexPath,
+		strings.ToLower(a.PoolAddresses),
+		a.FeeTiers,
+	}, "|")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckCyclic 检查交易路径是否为闭环（首尾代币相同）
+// 利润按 输出 - 输入 计算，只有起止为同一代币时才成立；非闭环路径需要先统一计价，不能直接入库
+func (a *ArbitrageOpportunity) CheckCyclic() error {
+	var path []string
+	if err := json.Unmarshal([]byte(a.SwapPath), &path); err != nil {
+		return fmt.Errorf("解析交易路径失败: %w", err)
+	}
+	if len(path) < 2 || !strings.EqualFold(path[0], path[len(path)-1]) {
+		return fmt.Errorf("%w: %s", ErrNonCyclicPath, a.SwapPath)
+	}
+	return nil
+}
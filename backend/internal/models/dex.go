@@ -18,11 +18,16 @@ type Dex struct {
 	RouterAddress  string `gorm:"not null;size:42" json:"router_address"`  // 路由合约地址
 	FactoryAddress string `gorm:"not null;size:42" json:"factory_address"` // 工厂合约地址（聚合器可为空）
 	QuoterAddress  string `gorm:"size:42" json:"quoter_address"`           // Quoter合约地址（V3专用）
+	QuoterType     string `gorm:"size:30" json:"quoter_type"`              // Quoter ABI 类型：uniswap_v2quoter（默认）, uniswap_v1quoter, pancake_v3, algebra
 
 	// === 费用配置 ===
-	Fee        int    `gorm:"not null" json:"fee"`              // 手续费（基点，如 30 表示 0.3%）
-	FeeTier    uint32 `gorm:"default:0" json:"fee_tier"`        // V3 费率层级（如 500, 3000, 10000），V2 为 0
-	DynamicFee bool   `gorm:"default:false" json:"dynamic_fee"` // 是否为动态费率（如 1inch）
+	Fee        int      `gorm:"not null" json:"fee"`                         // 手续费（基点，如 30 表示 0.3%）
+	FeeTier    uint32   `gorm:"default:0" json:"fee_tier"`                   // V3 费率层级（如 500, 3000, 10000），V2 为 0
+	FeeTiers   []uint32 `gorm:"serializer:json;type:jsonb" json:"fee_tiers"` // V3 发现池子时遍历的费率层级列表（为空时只使用 FeeTier）
+	DynamicFee bool     `gorm:"default:false" json:"dynamic_fee"`            // 是否为动态费率（如 1inch）
+
+	// === 储备量读取方式（V2）===
+	ReserveStyle string `gorm:"size:20;default:'combined'" json:"reserve_style"` // combined: getReserves(); separate: getReserves() 失败时回退到 reserve0()/reserve1()
 
 	// === 功能支持 ===
 	SupportFlashLoan bool `gorm:"default:false" json:"support_flash_loan"` // 是否支持闪电贷
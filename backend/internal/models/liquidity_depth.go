@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // LiquidityDepth 流动性深度表
@@ -16,6 +18,13 @@ type LiquidityDepth struct {
 	PriceImpact float64 `gorm:"not null" json:"price_impact"`                // 价格影响（滑点）百分比
 	SlippageBps uint32  `gorm:"not null" json:"slippage_bps"`                // 滑点（基点，1 bps = 0.01%）
 
+	// 金额超出池子可用流动性（Quoter revert）：AmountOut 为 0，滑点记为 100%
+	// 每个方向只记录第一个超出的金额，标出深度曲线的边界
+	ExceedsLiquidity bool `gorm:"default:false" json:"exceeds_liquidity"`
+
+	// V3 费率层级（同一代币对的不同费率层级是不同的池子，深度分别记录）
+	FeeTier uint32 `gorm:"index;default:0" json:"fee_tier"`
+
 	// 交易方向
 	Direction string `gorm:"index:idx_pair_direction_time;size:20;not null" json:"direction"` // "token0_to_token1" 或 "token1_to_token0"
 
@@ -45,3 +54,24 @@ func (l *LiquidityDepth) GetSlippagePercent() float64 {
 func (l *LiquidityDepth) IsHighSlippage() bool {
 	return l.SlippageBps > 100 // 100 bps = 1%
 }
+
+// DeepestTierDepth 查询同一 DEX、同一代币对在指定方向和输入金额下滑点最小的费率层级的最新深度
+// 用于套利金额评估时选择流动性最深的池子；没有数据时返回 gorm.ErrRecordNotFound
+func DeepestTierDepth(db *gorm.DB, dexID, token0ID, token1ID uint, direction, amountIn string) (*LiquidityDepth, error) {
+	// 每个池子只取最新一次采集
+	latest := db.Model(&LiquidityDepth{}).
+		Select("MAX(liquidity_depths.id)").
+		Joins("JOIN trading_pairs ON trading_pairs.id = liquidity_depths.pair_id").
+		Where("trading_pairs.dex_id = ? AND trading_pairs.token0_id = ? AND trading_pairs.token1_id = ?", dexID, token0ID, token1ID).
+		Where("liquidity_depths.direction = ? AND liquidity_depths.amount_in = ?", direction, amountIn).
+		Group("liquidity_depths.pair_id")
+
+	var depth LiquidityDepth
+	err := db.Where("id IN (?) AND exceeds_liquidity = ?", latest, false).
+		Order("slippage_bps ASC").
+		First(&depth).Error
+	if err != nil {
+		return nil, err
+	}
+	return &depth, nil
+}
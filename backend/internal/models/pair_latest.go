@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// PairLatest 交易对最新状态表（每个交易对一行）
+// pair_reserves / price_records 是按时间累积的历史表，"当前状态"查询需要扫描历史；
+// 采集器每次写入历史时同步 upsert 本表，读取当前状态只需 O(交易对数)
+type PairLatest struct {
+	PairID uint `gorm:"primaryKey;autoIncrement:false" json:"pair_id"` // 交易对 ID
+
+	// === 储备量 / 价格 ===
+	Reserve0     string `gorm:"type:varchar(78);not null" json:"reserve0"`      // 代币0储备量
+	Reserve1     string `gorm:"type:varchar(78);not null" json:"reserve1"`      // 代币1储备量
+	Price        string `gorm:"type:varchar(78);not null" json:"price"`         // 价格（token1/token0）
+	InversePrice string `gorm:"type:varchar(78);not null" json:"inverse_price"` // 反向价格（token0/token1）
+
+	// === V3 数据 ===
+	SqrtPriceX96 string `gorm:"type:varchar(78)" json:"sqrt_price_x96"` // V3 当前价格的平方根（按记录的代币顺序为 token1/token0，与链上顺序相反时取倒数）
+	Tick         int32  `gorm:"default:0" json:"tick"`                  // V3 当前tick（按记录的代币顺序，与链上顺序相反时取反）
+	Liquidity    string `gorm:"type:varchar(78)" json:"liquidity"`      // V3 当前活跃流动性
+
+	// === 元数据 ===
+	BlockNumber uint64    `gorm:"not null" json:"block_number"` // 区块号
+	BlockHash   string    `gorm:"size:66" json:"block_hash"`    // 区块哈希
+	Timestamp   time.Time `gorm:"not null" json:"timestamp"`    // 采集时间
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 关联
+	Pair TradingPair `gorm:"foreignKey:PairID" json:"pair,omitempty"`
+}
+
+// TableName 指定表名
+func (PairLatest) TableName() string {
+	return "pair_latest"
+}
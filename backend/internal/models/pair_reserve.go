@@ -2,25 +2,27 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // PairReserve 流动性池储备表（实时数据）
 type PairReserve struct {
 	ID     uint `gorm:"primaryKey" json:"id"`
-	PairID uint `gorm:"index:idx_pair_time;not null" json:"pair_id"` // 交易对 ID
+	PairID uint `gorm:"index:idx_reserve_pair_time,priority:1;not null" json:"pair_id"` // 交易对 ID
 
 	// === V2 储备量数据 ===
 	Reserve0 string `gorm:"type:varchar(78);not null" json:"reserve0"` // 代币0储备量（使用字符串存储大整数）
 	Reserve1 string `gorm:"type:varchar(78);not null" json:"reserve1"` // 代币1储备量
 
 	// === V3 流动性数据 ===
-	SqrtPriceX96 string `gorm:"type:varchar(78)" json:"sqrt_price_x96"` // V3 当前价格的平方根（96位定点数）
-	Tick         int32  `gorm:"default:0" json:"tick"`                  // V3 当前tick
+	SqrtPriceX96 string `gorm:"type:varchar(78)" json:"sqrt_price_x96"` // V3 当前价格的平方根（96位定点数，按记录的代币顺序为 token1/token0，与链上顺序相反时取倒数）
+	Tick         int32  `gorm:"default:0" json:"tick"`                  // V3 当前tick（按记录的代币顺序，与链上顺序相反时取反）
 	Liquidity    string `gorm:"type:varchar(78)" json:"liquidity"`      // V3 当前活跃流动性
 
 	// === 元数据 ===
-	BlockNumber uint64    `gorm:"index;not null" json:"block_number"`            // 区块号
-	Timestamp   time.Time `gorm:"index:idx_pair_time;not null" json:"timestamp"` // 时间戳
+	BlockNumber uint64    `gorm:"index;not null" json:"block_number"`                                     // 区块号
+	Timestamp   time.Time `gorm:"index;index:idx_reserve_pair_time,priority:2;not null" json:"timestamp"` // 时间戳
 	CreatedAt   time.Time `json:"created_at"`
 
 	// 关联
@@ -31,3 +33,17 @@ type PairReserve struct {
 func (PairReserve) TableName() string {
 	return "pair_reserves"
 }
+
+// LatestReserve 获取交易对最新的储备量记录（使用 pair_id + timestamp 复合索引）
+// 没有记录时返回 gorm.ErrRecordNotFound
+func LatestReserve(db *gorm.DB, pairID uint) (*PairReserve, error) {
+	var reserve PairReserve
+	err := db.Where("pair_id = ?", pairID).
+		Order("timestamp DESC").
+		Order("id DESC").
+		First(&reserve).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reserve, nil
+}
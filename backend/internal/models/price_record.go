@@ -16,9 +16,10 @@ type PriceRecord struct {
 	Reserve1     string `gorm:"type:varchar(78);not null" json:"reserve1"`      // 代币1储备量
 
 	// === V3 核心数据 ===
-	SqrtPriceX96     string `gorm:"type:varchar(78)" json:"sqrt_price_x96"`      // V3 当前价格的平方根（96位定点数）
-	Tick             int32  `gorm:"default:0" json:"tick"`                       // V3 当前tick
+	SqrtPriceX96     string `gorm:"type:varchar(78)" json:"sqrt_price_x96"`      // V3 当前价格的平方根（96位定点数，按记录的代币顺序为 token1/token0，与链上顺序相反时取倒数）
+	Tick             int32  `gorm:"default:0" json:"tick"`                       // V3 当前tick（按记录的代币顺序，与链上顺序相反时取反）
 	Liquidity        string `gorm:"type:varchar(78)" json:"liquidity"`           // V3 当前活跃流动性
+	Fee              uint32 `gorm:"not null;default:0" json:"fee"`               // V3 采集时的池子费率（动态费率池会随时间变化，老数据为 0）
 	FeeGrowthGlobal0 string `gorm:"type:varchar(78)" json:"fee_growth_global_0"` // V3 手续费增长0
 	FeeGrowthGlobal1 string `gorm:"type:varchar(78)" json:"fee_growth_global_1"` // V3 手续费增长1
 
@@ -31,6 +32,7 @@ type PriceRecord struct {
 
 	// === 元数据 ===
 	BlockNumber uint64    `gorm:"index;not null" json:"block_number"`            // 区块号
+	BlockHash   string    `gorm:"size:66" json:"block_hash"`                     // 区块哈希（用于链重组检测）
 	Timestamp   time.Time `gorm:"index:idx_pair_time;not null" json:"timestamp"` // 时间戳
 	CreatedAt   time.Time `json:"created_at"`
 
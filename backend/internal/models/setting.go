@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// Setting 运行时设置表（键值对）
+// 保存需要在重启后保留的运行时状态，如交易开关
+type Setting struct {
+	Key       string    `gorm:"primaryKey;size:64" json:"key"` // 设置项名称
+	Value     string    `gorm:"type:text" json:"value"`        // 设置值
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Setting) TableName() string {
+	return "settings"
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+)
+
+// StrategyPerformance 策略表现统计表
+// 按 套利类型 + DEX 路径 + 输入代币 分组，统计一个时间窗口内预期利润与实际利润的差距
+type StrategyPerformance struct {
+	ID            uint   `gorm:"primaryKey" json:"id"`
+	ArbitrageType string `gorm:"index;size:20;not null" json:"arbitrage_type"` // 套利类型：cross_dex, fee_tier, triangular, flash_loan（无关联机会时为 manual）
+	DexPath       string `gorm:"type:text;not null" json:"dex_path"`           // DEX 路径（JSON 数组）
+	TokenInID     uint   `gorm:"index;not null" json:"token_in_id"`            // 输入代币 ID（利润以该代币计价）
+
+	// === 统计窗口 ===
+	WindowStart time.Time `gorm:"not null" json:"window_start"`
+	WindowEnd   time.Time `gorm:"index;not null" json:"window_end"`
+
+	// === 执行统计 ===
+	Executions int     `gorm:"not null" json:"executions"` // 执行次数
+	Successes  int     `gorm:"not null" json:"successes"`  // 成功次数（交易未回滚）
+	Wins       int     `gorm:"not null" json:"wins"`       // 盈利次数（扣除 Gas 后利润 > 0）
+	Unpriced   int     `gorm:"default:0" json:"unpriced"`  // 缺少价格、未计入净利润和盈利次数的执行次数
+	WinRate    float64 `gorm:"not null" json:"win_rate"`   // 胜率（百分比）
+
+	// === 预期 vs 实际 ===
+	MeanSlippage   float64 `gorm:"not null" json:"mean_slippage"`   // 平均利润偏差（百分比，(预期-实际)/预期）
+	MedianSlippage float64 `gorm:"not null" json:"median_slippage"` // 利润偏差中位数（百分比）
+
+	// === 利润（输入代币最小单位；Gas 成本为原生币 wei，两者不能直接相减）===
+	TotalExpectedProfit string  `gorm:"type:varchar(78);not null" json:"total_expected_profit"` // 预期利润合计
+	TotalActualProfit   string  `gorm:"type:varchar(78);not null" json:"total_actual_profit"`   // 实际利润合计
+	TotalGasCost        string  `gorm:"type:varchar(78);not null" json:"total_gas_cost"`        // Gas 成本合计（原生币 wei）
+	NetProfit           string  `gorm:"type:varchar(78);not null" json:"net_profit"`            // 扣除 Gas 后的净利润（输入代币最小单位，Gas 按统计时价格换算）
+	NetProfitUSD        float64 `gorm:"default:0" json:"net_profit_usd"`                        // 扣除 Gas 后的净利润（美元，统计时价格）
+
+	CreatedAt time.Time `json:"created_at"`
+
+	// 关联
+	TokenIn Token `gorm:"foreignKey:TokenInID" json:"token_in,omitempty"`
+}
+
+// TableName 指定表名
+func (StrategyPerformance) TableName() string {
+	return "strategy_performance"
+}
@@ -19,14 +19,20 @@ type Token struct {
 	Price24hChange float64   `gorm:"default:0" json:"price_24h_change"` // 24小时价格变化（百分比）
 
 	// === 市场数据 ===
-	TotalSupply       string  `gorm:"type:varchar(78)" json:"total_supply"`       // 总供应量
-	CirculatingSupply string  `gorm:"type:varchar(78)" json:"circulating_supply"` // 流通供应量
-	MarketCapUSD      float64 `gorm:"default:0" json:"market_cap_usd"`            // 市值（美元）
-	Volume24hUSD      float64 `gorm:"default:0" json:"volume_24h_usd"`            // 24小时成交量（美元）
+	TotalSupply       string   `gorm:"type:varchar(78)" json:"total_supply"`       // 总供应量
+	CirculatingSupply string   `gorm:"type:varchar(78)" json:"circulating_supply"` // 流通供应量
+	MarketCapUSD      *float64 `json:"market_cap_usd"`                             // 市值（美元，按流通供应量计算；流通供应量未知时为 NULL）
+	Volume24hUSD      float64  `gorm:"default:0" json:"volume_24h_usd"`            // 24小时成交量（美元）
 
 	// === 代币属性 ===
 	IsStablecoin bool `gorm:"default:false" json:"is_stablecoin"` // 是否为稳定币
 	IsWrapped    bool `gorm:"default:false" json:"is_wrapped"`    // 是否为包装代币（如WETH）
+	IsRebasing   bool `gorm:"default:false" json:"is_rebasing"`   // 是否为 rebase 代币（余额不经转账变化，如 stETH、AMPL），池子储备量不能缓存
+
+	// === 安全检测 ===
+	IsHoneypot        bool       `gorm:"index;default:false" json:"is_honeypot"` // 是否为蜜罐代币（可买不可卖），包含该代币的交易对不参与套利
+	HoneypotReason    string     `gorm:"size:200" json:"honeypot_reason"`        // 判定原因
+	HoneypotCheckedAt *time.Time `json:"honeypot_checked_at"`                    // 最近检测时间（未检测为空）
 
 	// === 外部数据源 ID ===
 	CoingeckoID     string `gorm:"size:50" json:"coingecko_id"`     // CoinGecko ID（用于获取价格）
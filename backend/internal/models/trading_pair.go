@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
@@ -10,11 +11,12 @@ type TradingPair struct {
 	DexID       uint   `gorm:"index:idx_dex_tokens;not null" json:"dex_id"`      // DEX ID
 	Token0ID    uint   `gorm:"index:idx_dex_tokens;not null" json:"token0_id"`   // 代币0 ID
 	Token1ID    uint   `gorm:"index:idx_dex_tokens;not null" json:"token1_id"`   // 代币1 ID
-	PairAddress string `gorm:"uniqueIndex;not null;size:42" json:"pair_address"` // 交易对合约地址
+	PairAddress string `gorm:"uniqueIndex;not null;size:66" json:"pair_address"` // 交易对合约地址（V4 为 32 字节 poolId）
 
 	// === V3 特有字段 ===
 	TickSpacing int32  `gorm:"default:0" json:"tick_spacing"`            // V3 tick间距（60, 200等）
-	PoolVersion string `gorm:"size:10;default:'v2'" json:"pool_version"` // 池版本（"v2", "v3"）
+	Fee         uint32 `gorm:"default:0" json:"fee"`                     // V3 池子费率（发现时读取池子的 fee()，如 500, 3000），V2 为 0
+	PoolVersion string `gorm:"size:10;default:'v2'" json:"pool_version"` // 池版本（"v2", "v3", "v4"）
 
 	// === 流动性状态 ===
 	MinLiquidity       string    `gorm:"type:varchar(78)" json:"min_liquidity"`     // 最小流动性阈值
@@ -38,3 +40,27 @@ type TradingPair struct {
 func (TradingPair) TableName() string {
 	return "trading_pairs"
 }
+
+// FeeTier 获取池子的费率层级（老数据未记录时回退到 DEX 配置）
+func (p *TradingPair) FeeTier() uint32 {
+	if p.Fee != 0 {
+		return p.Fee
+	}
+	return p.Dex.FeeTier
+}
+
+// HasRebasingToken 判断交易对是否包含 rebase 代币（需预加载 Token0 / Token1）
+func (p *TradingPair) HasRebasingToken() bool {
+	return p.Token0.IsRebasing || p.Token1.IsRebasing
+}
+
+// HasHoneypotToken 判断交易对是否包含被标记为蜜罐的代币（需预加载 Token0 / Token1）
+func (p *TradingPair) HasHoneypotToken() bool {
+	return p.Token0.IsHoneypot || p.Token1.IsHoneypot
+}
+
+// TokensReversed 判断记录的代币顺序是否与链上相反（需预加载 Token0 / Token1）
+// V2/V3/V4 池子的 token0 都是地址较小的代币；老数据按配置顺序保存时储备量和价格会被反向标注
+func (p *TradingPair) TokensReversed() bool {
+	return strings.ToLower(p.Token0.Address) > strings.ToLower(p.Token1.Address)
+}
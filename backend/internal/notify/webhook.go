@@ -0,0 +1,286 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/defi-bot/backend/internal/models"
+)
+
+// SignatureHeader 请求体 HMAC-SHA256 签名的请求头（值为 sha256=<hex>），配置了 secret 时发送
+const SignatureHeader = "X-Signature-256"
+
+// EventHeader 事件类型请求头
+const EventHeader = "X-Event"
+
+// 事件类型
+const (
+	EventExecution   = "execution"
+	EventOpportunity = "opportunity"
+)
+
+// WebhookConfig Webhook 推送配置
+type WebhookConfig struct {
+	URLs       []string      // 接收地址
+	Secret     string        // HMAC 签名密钥（为空不签名）
+	QueueSize  int           // 待发送事件队列长度（默认 256），满了直接丢弃
+	MaxRetries int           // 非 2xx 或请求失败时的重试次数（默认 3）
+	Timeout    time.Duration // 单次请求超时（默认 10 秒）
+
+	RetryBackoff time.Duration // 首次重试前的等待时间，之后每次翻倍（默认 1 秒）
+}
+
+// Event 推送的事件
+type Event struct {
+	Type      string      `json:"type"` // execution / opportunity
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// ExecutionPayload 执行结果事件内容
+type ExecutionPayload struct {
+	ID            uint     `json:"id"`
+	OpportunityID uint     `json:"opportunity_id"`
+	VaultAddress  string   `json:"vault_address"`
+	TxHash        string   `json:"tx_hash"`
+	BlockNumber   uint64   `json:"block_number"`
+	Status        string   `json:"status"`
+	ErrorMessage  string   `json:"error_message,omitempty"`
+	TokenInID     uint     `json:"token_in_id"`
+	AmountIn      string   `json:"amount_in"`
+	AmountOut     string   `json:"amount_out"`
+	ActualProfit  string   `json:"actual_profit"`
+	NetProfit     *string  `json:"net_profit"`     // 价格未知时为 null
+	NetProfitUSD  *float64 `json:"net_profit_usd"` // 价格未知时为 null
+	ROI           *float64 `json:"roi"`            // 价格未知时为 null
+	GasUsed       uint64   `json:"gas_used"`
+	GasPrice      string   `json:"gas_price"`
+	SwapPath      string   `json:"swap_path"`
+	DexPath       string   `json:"dex_path"`
+}
+
+// OpportunityPayload 套利机会事件内容
+type OpportunityPayload struct {
+	ID             uint    `json:"id"`
+	ArbitrageType  string  `json:"arbitrage_type"`
+	TokenInID      uint    `json:"token_in_id"`
+	AmountIn       string  `json:"amount_in"`
+	ExpectedProfit string  `json:"expected_profit"`
+	ProfitRate     float64 `json:"profit_rate"`
+	SwapPath       string  `json:"swap_path"`
+	DexPath        string  `json:"dex_path"`
+	DiscoveryBlock uint64  `json:"discovery_block"`
+}
+
+// WebhookDispatcher 把执行结果 / 套利机会异步推送到外部系统
+// 事件先进入有界队列，由后台协程逐个发送；队列满时丢弃并记录日志，调用方永远不会被阻塞
+// Close 之后到达的事件直接丢弃，因此关闭前不必先注销通知器也不会 panic
+type WebhookDispatcher struct {
+	config WebhookConfig
+	client *http.Client
+	queue  chan Event
+	done   chan struct{}
+
+	// ctx 在 Close 超时后取消，中断正在进行的请求和退避等待
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex // 保护 closed 和关闭 queue，保证不会向已关闭的 queue 发送
+	closed bool
+}
+
+// NewWebhookDispatcher 创建并启动 Webhook 推送器
+func NewWebhookDispatcher(cfg WebhookConfig) *WebhookDispatcher {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 256
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &WebhookDispatcher{
+		config: cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		queue:  make(chan Event, cfg.QueueSize),
+		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	go d.run()
+	return d
+}
+
+// NotifyExecution 推送执行结果（非阻塞）
+func (d *WebhookDispatcher) NotifyExecution(exec *models.ArbitrageExecution) {
+	d.enqueue(EventExecution, ExecutionPayload{
+		ID:            exec.ID,
+		OpportunityID: exec.OpportunityID,
+		VaultAddress:  exec.VaultAddress,
+		TxHash:        exec.TxHash,
+		BlockNumber:   exec.BlockNumber,
+		Status:        exec.Status,
+		ErrorMessage:  exec.ErrorMessage,
+		TokenInID:     exec.TokenInID,
+		AmountIn:      exec.AmountIn,
+		AmountOut:     exec.AmountOut,
+		ActualProfit:  exec.ActualProfit,
+		NetProfit:     exec.NetProfit,
+		NetProfitUSD:  exec.NetProfitUSD,
+		ROI:           exec.ROI,
+		GasUsed:       exec.GasUsed,
+		GasPrice:      exec.GasPrice,
+		SwapPath:      exec.SwapPath,
+		DexPath:       exec.DexPath,
+	})
+}
+
+// NotifyOpportunity 推送新发现的套利机会（非阻塞）
+func (d *WebhookDispatcher) NotifyOpportunity(opp *models.ArbitrageOpportunity) {
+	d.enqueue(EventOpportunity, OpportunityPayload{
+		ID:             opp.ID,
+		ArbitrageType:  opp.ArbitrageType,
+		TokenInID:      opp.TokenInID,
+		AmountIn:       opp.AmountIn,
+		ExpectedProfit: opp.ExpectedProfit,
+		ProfitRate:     opp.ProfitRate,
+		SwapPath:       opp.SwapPath,
+		DexPath:        opp.DexPath,
+		DiscoveryBlock: opp.DiscoveryBlock,
+	})
+}
+
+// Close 停止接收新事件，等待队列中已有事件发送完成
+// ctx 到期时中断正在进行的请求，丢弃剩余事件并返回错误；可重复调用
+func (d *WebhookDispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-d.done
+		return fmt.Errorf("等待 Webhook 事件发送超时: %w", ctx.Err())
+	}
+}
+
+// enqueue 事件入队，队列满或已关闭时丢弃
+func (d *WebhookDispatcher) enqueue(eventType string, data interface{}) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		log.Printf("⚠️  Webhook 推送器已关闭，丢弃 %s 事件", eventType)
+		return
+	}
+
+	event := Event{Type: eventType, Timestamp: time.Now(), Data: data}
+	select {
+	case d.queue <- event:
+	default:
+		log.Printf("⚠️  Webhook 队列已满，丢弃 %s 事件", eventType)
+	}
+}
+
+// run 后台发送协程
+func (d *WebhookDispatcher) run() {
+	defer close(d.done)
+
+	dropped := 0
+	for event := range d.queue {
+		// Close 超时后不再发送，只清空队列
+		if d.ctx.Err() != nil {
+			dropped++
+			continue
+		}
+
+		body, err := json.Marshal(event)
+		if err != nil {
+			log.Printf("⚠️  序列化 Webhook 事件失败: %v", err)
+			continue
+		}
+		for _, url := range d.config.URLs {
+			if err := d.deliver(url, event.Type, body); err != nil {
+				log.Printf("⚠️  Webhook 推送失败 %s: %v", url, err)
+			}
+		}
+	}
+	if dropped > 0 {
+		log.Printf("⚠️  Webhook 推送器关闭超时，丢弃 %d 个未发送事件", dropped)
+	}
+}
+
+// deliver 发送到单个地址，失败时按 RetryBackoff 的 1、2、4... 倍退避重试
+func (d *WebhookDispatcher) deliver(url, eventType string, body []byte) error {
+	var lastErr error
+	backoff := d.config.RetryBackoff
+	for attempt := 0; attempt <= d.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-d.ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("推送器已关闭: %w", lastErr)
+			}
+			backoff *= 2
+		}
+
+		lastErr = d.post(url, eventType, body)
+		if lastErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("重试 %d 次后仍失败: %w", d.config.MaxRetries, lastErr)
+}
+
+// post 发送一次请求，非 2xx 视为失败
+func (d *WebhookDispatcher) post(url, eventType string, body []byte) error {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventType)
+	if d.config.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+sign(d.config.Secret, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sign 计算请求体的 HMAC-SHA256（十六进制）
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
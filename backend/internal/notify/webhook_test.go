@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/defi-bot/backend/internal/models"
+)
+
+// closeDispatcher 关闭推送器，超时视为测试失败
+func closeDispatcher(t *testing.T, d *WebhookDispatcher) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Close(ctx); err != nil {
+		t.Fatalf("关闭推送器失败: %v", err)
+	}
+}
+
+func TestWebhookSignsBody(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		body      []byte
+		signature string
+		eventType string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		eventType = r.Header.Get(EventHeader)
+	}))
+	defer server.Close()
+
+	d := NewWebhookDispatcher(WebhookConfig{URLs: []string{server.URL}, Secret: "s3cret"})
+	d.NotifyExecution(&models.ArbitrageExecution{ID: 42, TxHash: "0xabc", Status: "success"})
+	closeDispatcher(t, d)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := "sha256=" + sign("s3cret", body); signature != want {
+		t.Errorf("签名 = %q, 期望 %q", signature, want)
+	}
+	if eventType != EventExecution {
+		t.Errorf("事件类型 = %q, 期望 %q", eventType, EventExecution)
+	}
+
+	var event struct {
+		Type string           `json:"type"`
+		Data ExecutionPayload `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("解析请求体失败: %v", err)
+	}
+	if event.Type != EventExecution || event.Data.ID != 42 || event.Data.TxHash != "0xabc" {
+		t.Errorf("请求体 = %+v, 与执行记录不一致", event)
+	}
+}
+
+func TestWebhookRetriesNon2xx(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	d := NewWebhookDispatcher(WebhookConfig{URLs: []string{server.URL}, MaxRetries: 3, RetryBackoff: time.Millisecond})
+	d.NotifyOpportunity(&models.ArbitrageOpportunity{ID: 1})
+	closeDispatcher(t, d)
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("请求次数 = %d, 期望 3（两次 502 后成功）", got)
+	}
+}
+
+func TestWebhookDropsWhenQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+	}))
+	defer server.Close()
+
+	d := NewWebhookDispatcher(WebhookConfig{URLs: []string{server.URL}, QueueSize: 1})
+
+	// 第一个事件被后台协程取走并阻塞在请求中，第二个占满队列，其余被丢弃
+	d.NotifyOpportunity(&models.ArbitrageOpportunity{ID: 1})
+	deadline := time.Now().Add(5 * time.Second)
+	for calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 2; i <= 5; i++ {
+		d.NotifyOpportunity(&models.ArbitrageOpportunity{ID: uint(i)})
+	}
+	close(release)
+	closeDispatcher(t, d)
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("请求次数 = %d, 期望 2（队列满时其余事件被丢弃）", got)
+	}
+}
+
+func TestWebhookEnqueueAfterClose(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	d := NewWebhookDispatcher(WebhookConfig{URLs: []string{server.URL}})
+	closeDispatcher(t, d)
+
+	// 关闭后仍收到的事件直接丢弃，不能向已关闭的队列发送而 panic
+	d.NotifyExecution(&models.ArbitrageExecution{ID: 1})
+	d.NotifyOpportunity(&models.ArbitrageOpportunity{ID: 1})
+	closeDispatcher(t, d)
+
+	if got := calls.Load(); got != 0 {
+		t.Errorf("请求次数 = %d, 关闭后的事件不应发送", got)
+	}
+}
+
+func TestWebhookCloseDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// 一直失败且退避很长：没有截止时间的话 Close 会一直等待
+	d := NewWebhookDispatcher(WebhookConfig{URLs: []string{server.URL}, MaxRetries: 5, RetryBackoff: time.Hour})
+	for i := 1; i <= 3; i++ {
+		d.NotifyOpportunity(&models.ArbitrageOpportunity{ID: uint(i)})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := d.Close(ctx)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, 期望 context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Close 耗时 %v, 应在截止时间后尽快返回", elapsed)
+	}
+}
@@ -0,0 +1,369 @@
+// Package pathfinder 在交易对构成的代币图上枚举闭环套利路径
+// 邻接表按池子地址排序，相同的交易对集合总是得到相同顺序的路径，便于去重、缓存和复现
+package pathfinder
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/defi-bot/backend/internal/config"
+	"github.com/defi-bot/backend/internal/models"
+)
+
+// SearchPolicy 路径搜索限制
+type SearchPolicy struct {
+	// MaxCandidatePaths 单次 FindAllPaths 最多保留的路径数（0 表示不限制）
+	// 超过时按路径上最小池子流动性保留最大的若干条，避免大图在 5 跳时生成数百万条路径耗尽内存
+	MaxCandidatePaths int
+
+	// HubTokens 枢纽代币符号（如 WETH、USDC），为空时不限制中间代币
+	// 设置后路径的中间代币（起点以外的代币）只能是枢纽代币，最多允许 MaxNonHubTokens 个例外
+	HubTokens       []string
+	MaxNonHubTokens int
+
+	// MaxPathsExplored 单次搜索最多扩展的节点数，MaxDFSDuration 单次搜索最长耗时（0 表示不限制）
+	// 任一预算耗尽时提前结束搜索，返回已找到的路径，避免稠密代币图拖慢分析周期
+	MaxPathsExplored int
+	MaxDFSDuration   time.Duration
+}
+
+// NewSearchPolicy 由套利配置生成路径搜索限制
+func NewSearchPolicy(cfg config.ArbitrageConfig) SearchPolicy {
+	policy := SearchPolicy{
+		MaxCandidatePaths: cfg.MaxCandidatePaths,
+		HubTokens:         cfg.HubTokens,
+		MaxPathsExplored:  cfg.MaxPathsExplored,
+		MaxDFSDuration:    time.Duration(cfg.MaxDFSDuration) * time.Millisecond,
+	}
+	if cfg.HubMode == config.HubModeOneWildcard {
+		policy.MaxNonHubTokens = 1
+	}
+	return policy
+}
+
+// Edge 代币图中的一条有向边：经由一个池子把 TokenIn 换成 TokenOut
+type Edge struct {
+	PairID      uint
+	PoolAddress string // 小写池子地址（排序键）
+	TokenIn     uint   // 输入代币 ID
+	TokenOut    uint   // 输出代币 ID
+	FeeTier     uint32
+	Liquidity   float64 // 池子流动性（美元，未知时为 0）
+}
+
+// Path 一条交易路径，相邻两条边首尾相接
+type Path []Edge
+
+// Tokens 路径经过的代币 ID（闭环路径首尾相同）
+func (p Path) Tokens() []uint {
+	if len(p) == 0 {
+		return nil
+	}
+	tokens := make([]uint, 0, len(p)+1)
+	tokens = append(tokens, p[0].TokenIn)
+	for _, edge := range p {
+		tokens = append(tokens, edge.TokenOut)
+	}
+	return tokens
+}
+
+// MinLiquidity 路径上流动性最小的池子的流动性（决定路径能承载的交易规模）
+func (p Path) MinLiquidity() float64 {
+	if len(p) == 0 {
+		return 0
+	}
+	min := p[0].Liquidity
+	for _, edge := range p[1:] {
+		if edge.Liquidity < min {
+			min = edge.Liquidity
+		}
+	}
+	return min
+}
+
+// Pools 路径依次经过的池子地址
+func (p Path) Pools() []string {
+	pools := make([]string, len(p))
+	for i, edge := range p {
+		pools[i] = edge.PoolAddress
+	}
+	return pools
+}
+
+// PathFinder 代币图与路径枚举
+type PathFinder struct {
+	policy     SearchPolicy
+	tokenGraph map[uint][]Edge // 代币 ID -> 以该代币为输入的边（按池子地址排序）
+	hubs       map[uint]bool   // 枢纽代币 ID（由 HubTokens 按符号匹配）
+
+	searches   atomic.Uint64 // 累计搜索次数
+	budgetHits atomic.Uint64 // 其中因预算耗尽或 ctx 结束而提前返回的次数
+}
+
+// ctxCheckInterval 每扩展多少个节点检查一次 ctx（避免每个节点都读取 ctx 状态）
+const ctxCheckInterval = 64
+
+// NewPathFinder 创建不限制搜索规模的路径查找器
+func NewPathFinder() *PathFinder {
+	return NewPathFinderWithPolicy(SearchPolicy{})
+}
+
+// NewPathFinderWithPolicy 创建带搜索限制的路径查找器
+func NewPathFinderWithPolicy(policy SearchPolicy) *PathFinder {
+	return &PathFinder{policy: policy, tokenGraph: make(map[uint][]Edge)}
+}
+
+// BuildTokenGraph 由交易对构建代币图（替换已有的图），池子流动性视为未知
+func (p *PathFinder) BuildTokenGraph(pairs []models.TradingPair) {
+	p.BuildTokenGraphWithLiquidity(pairs, nil)
+}
+
+// BuildTokenGraphWithLiquidity 由交易对构建代币图（替换已有的图）
+// 每个池子产生两个方向的边；邻接表按池子地址排序，与传入顺序无关
+// liquidity 为交易对 ID -> 流动性（美元），候选路径超过 MaxCandidatePaths 时据此取舍
+// 设置了 HubTokens 时按代币符号识别枢纽代币；包含蜜罐代币的交易对不进入图（都需预加载 Token0 / Token1）
+func (p *PathFinder) BuildTokenGraphWithLiquidity(pairs []models.TradingPair, liquidity map[uint]float64) {
+	graph := make(map[uint][]Edge)
+	hubs := make(map[uint]bool)
+	for i := range pairs {
+		pair := &pairs[i]
+		if pair.Token0ID == pair.Token1ID || pair.HasHoneypotToken() {
+			continue
+		}
+		pool := strings.ToLower(pair.PairAddress)
+		feeTier := pair.FeeTier()
+		poolLiquidity := liquidity[pair.ID]
+
+		if p.isHubSymbol(pair.Token0.Symbol) {
+			hubs[pair.Token0ID] = true
+		}
+		if p.isHubSymbol(pair.Token1.Symbol) {
+			hubs[pair.Token1ID] = true
+		}
+
+		graph[pair.Token0ID] = append(graph[pair.Token0ID], Edge{
+			PairID: pair.ID, PoolAddress: pool, TokenIn: pair.Token0ID, TokenOut: pair.Token1ID, FeeTier: feeTier, Liquidity: poolLiquidity,
+		})
+		graph[pair.Token1ID] = append(graph[pair.Token1ID], Edge{
+			PairID: pair.ID, PoolAddress: pool, TokenIn: pair.Token1ID, TokenOut: pair.Token0ID, FeeTier: feeTier, Liquidity: poolLiquidity,
+		})
+	}
+
+	for _, edges := range graph {
+		sort.Slice(edges, func(i, j int) bool {
+			if edges[i].PoolAddress != edges[j].PoolAddress {
+				return edges[i].PoolAddress < edges[j].PoolAddress
+			}
+			return edges[i].PairID < edges[j].PairID
+		})
+	}
+	p.tokenGraph = graph
+	p.hubs = hubs
+}
+
+// isHubSymbol 判断代币符号是否在 HubTokens 中（不区分大小写）
+func (p *PathFinder) isHubSymbol(symbol string) bool {
+	if symbol == "" {
+		return false
+	}
+	for _, hub := range p.policy.HubTokens {
+		if strings.EqualFold(hub, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// Neighbors 以 token 为输入的边（按池子地址排序）
+func (p *PathFinder) Neighbors(token uint) []Edge {
+	return p.tokenGraph[token]
+}
+
+// FindAllPaths 枚举从 start 出发、最多 maxHops 跳后回到 start 的闭环路径
+// 同一路径中的池子不重复使用，中间代币不重复经过；2 跳即跨 DEX 套利，3 跳即三角套利。
+// 结果按深度优先顺序返回，图相同时顺序固定。
+// 设置了 HubTokens 时中间代币只能是枢纽代币（最多 MaxNonHubTokens 个例外）；
+// 路径数超过 MaxCandidatePaths 时只保留最小池子流动性最大的若干条（仍按深度优先顺序返回）
+func (p *PathFinder) FindAllPaths(start uint, maxHops int) []Path {
+	return p.FindAllPathsContext(context.Background(), start, maxHops)
+}
+
+// FindAllPathsContext 同 FindAllPaths，但受搜索预算约束：
+// 扩展节点数达到 MaxPathsExplored、耗时超过 MaxDFSDuration 或 ctx 结束（如分析周期的截止时间）时
+// 停止搜索，返回已找到的路径
+func (p *PathFinder) FindAllPathsContext(ctx context.Context, start uint, maxHops int) []Path {
+	if maxHops < 2 {
+		return nil
+	}
+
+	if p.policy.MaxDFSDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.policy.MaxDFSDuration)
+		defer cancel()
+	}
+	startTime := time.Now()
+	explored := 0
+	var stopReason string
+
+	candidates := newCandidateSet(p.policy.MaxCandidatePaths)
+	var current Path
+	visitedTokens := map[uint]bool{start: true}
+	usedPools := make(map[string]bool)
+	constrainHubs := len(p.policy.HubTokens) > 0
+	nonHubTokens := 0
+
+	var walk func(token uint)
+	walk = func(token uint) {
+		for _, edge := range p.tokenGraph[token] {
+			if stopReason != "" {
+				return
+			}
+			if usedPools[edge.PoolAddress] {
+				continue
+			}
+
+			if edge.TokenOut == start {
+				if len(current) >= 1 {
+					cycle := make(Path, len(current)+1)
+					copy(cycle, current)
+					cycle[len(current)] = edge
+					candidates.add(cycle)
+				}
+				continue
+			}
+
+			if visitedTokens[edge.TokenOut] || len(current)+2 > maxHops {
+				continue
+			}
+
+			nonHub := constrainHubs && !p.hubs[edge.TokenOut]
+			if nonHub && nonHubTokens >= p.policy.MaxNonHubTokens {
+				continue
+			}
+			if p.policy.MaxPathsExplored > 0 && explored >= p.policy.MaxPathsExplored {
+				stopReason = "节点数达到上限"
+				return
+			}
+			if explored%ctxCheckInterval == 0 && ctx.Err() != nil {
+				stopReason = ctx.Err().Error()
+				return
+			}
+			explored++
+
+			if nonHub {
+				nonHubTokens++
+			}
+
+			visitedTokens[edge.TokenOut] = true
+			usedPools[edge.PoolAddress] = true
+			current = append(current, edge)
+
+			walk(edge.TokenOut)
+
+			current = current[:len(current)-1]
+			delete(usedPools, edge.PoolAddress)
+			delete(visitedTokens, edge.TokenOut)
+			if nonHub {
+				nonHubTokens--
+			}
+		}
+	}
+	walk(start)
+
+	searches := p.searches.Add(1)
+	if stopReason != "" {
+		hits := p.budgetHits.Add(1)
+		log.Printf("⚠️  代币 %d 的路径搜索提前结束（%s，已扩展 %d 个节点，耗时 %v），返回已找到的 %d 条路径；累计 %d/%d 次搜索触发预算",
+			start, stopReason, explored, time.Since(startTime).Round(time.Millisecond), candidates.total, hits, searches)
+	}
+	if candidates.dropped > 0 {
+		log.Printf("⚠️  代币 %d 的候选路径 %d 条超过上限 %d，按池子流动性保留 %d 条",
+			start, candidates.total, p.policy.MaxCandidatePaths, len(candidates.heap))
+	}
+	return candidates.paths()
+}
+
+// BudgetStats 累计搜索次数和其中触发搜索预算（提前返回）的次数，用于调整 MaxPathsExplored / MaxDFSDuration
+func (p *PathFinder) BudgetStats() (searches, budgetHits uint64) {
+	return p.searches.Load(), p.budgetHits.Load()
+}
+
+// candidate 候选路径及其在深度优先顺序中的序号
+type candidate struct {
+	path  Path
+	score float64 // 路径上最小的池子流动性
+	seq   int
+}
+
+// candidateHeap 最小堆，堆顶是最差的候选（流动性最小；相同时序号最大，即最晚找到）
+type candidateHeap []candidate
+
+func (h candidateHeap) Len() int { return len(h) }
+func (h candidateHeap) Less(i, j int) bool {
+	if h[i].score != h[j].score {
+		return h[i].score < h[j].score
+	}
+	return h[i].seq > h[j].seq
+}
+func (h candidateHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *candidateHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *candidateHeap) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// candidateSet 收集候选路径，limit > 0 时用有界堆只保留最好的 limit 条
+type candidateSet struct {
+	limit   int
+	heap    candidateHeap
+	total   int // 找到的路径总数
+	dropped int // 因超过上限被丢弃的路径数
+}
+
+func newCandidateSet(limit int) *candidateSet {
+	return &candidateSet{limit: limit}
+}
+
+func (s *candidateSet) add(path Path) {
+	c := candidate{path: path, score: path.MinLiquidity(), seq: s.total}
+	s.total++
+
+	if s.limit <= 0 {
+		s.heap = append(s.heap, c)
+		return
+	}
+	if len(s.heap) < s.limit {
+		heap.Push(&s.heap, c)
+		return
+	}
+
+	s.dropped++
+	// 新路径序号最大，流动性相同时不替换，保留先找到的路径
+	if c.score > s.heap[0].score {
+		s.heap[0] = c
+		heap.Fix(&s.heap, 0)
+	}
+}
+
+// paths 按深度优先顺序返回保留的路径
+func (s *candidateSet) paths() []Path {
+	if len(s.heap) == 0 {
+		return nil
+	}
+	kept := make([]candidate, len(s.heap))
+	copy(kept, s.heap)
+	sort.Slice(kept, func(i, j int) bool { return kept[i].seq < kept[j].seq })
+
+	paths := make([]Path, len(kept))
+	for i, c := range kept {
+		paths[i] = c.path
+	}
+	return paths
+}
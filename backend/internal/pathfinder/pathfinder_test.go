@@ -0,0 +1,324 @@
+package pathfinder
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/defi-bot/backend/internal/config"
+	"github.com/defi-bot/backend/internal/models"
+)
+
+const (
+	weth uint = 1
+	usdc uint = 2
+	dai  uint = 3
+	wbtc uint = 4
+)
+
+// testPairs 小型代币图：WETH/USDC 两个池子（跨 DEX），USDC/DAI、DAI/WETH 构成三角，WBTC 只有一个池子
+func testPairs() []models.TradingPair {
+	return []models.TradingPair{
+		{ID: 1, PairAddress: "0x00000000000000000000000000000000000000AA", Token0ID: weth, Token1ID: usdc, Fee: 500},
+		{ID: 2, PairAddress: "0x00000000000000000000000000000000000000bb", Token0ID: usdc, Token1ID: weth, Fee: 3000},
+		{ID: 3, PairAddress: "0x00000000000000000000000000000000000000cc", Token0ID: usdc, Token1ID: dai},
+		{ID: 4, PairAddress: "0x00000000000000000000000000000000000000dd", Token0ID: dai, Token1ID: weth},
+		{ID: 5, PairAddress: "0x00000000000000000000000000000000000000ee", Token0ID: wbtc, Token1ID: weth},
+	}
+}
+
+// poolNames 用池子地址末两位表示路径，如 "aa-bb"
+func poolNames(paths []Path) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		pools := path.Pools()
+		for j, pool := range pools {
+			pools[j] = pool[len(pool)-2:]
+		}
+		names[i] = strings.Join(pools, "-")
+	}
+	return names
+}
+
+func TestBuildTokenGraphSorted(t *testing.T) {
+	pairs := testPairs()
+	// 逆序传入，邻接表仍按池子地址排序
+	for i, j := 0, len(pairs)-1; i < j; i, j = i+1, j-1 {
+		pairs[i], pairs[j] = pairs[j], pairs[i]
+	}
+
+	finder := NewPathFinder()
+	finder.BuildTokenGraph(pairs)
+
+	tests := []struct {
+		token uint
+		pools []string
+	}{
+		{token: weth, pools: []string{"aa", "bb", "dd", "ee"}},
+		{token: usdc, pools: []string{"aa", "bb", "cc"}},
+		{token: dai, pools: []string{"cc", "dd"}},
+		{token: wbtc, pools: []string{"ee"}},
+	}
+	for _, tt := range tests {
+		got := poolNames([]Path{finder.Neighbors(tt.token)})[0]
+		if want := strings.Join(tt.pools, "-"); got != want {
+			t.Errorf("代币 %d 的邻接池子 = %s, 期望 %s", tt.token, got, want)
+		}
+		for _, edge := range finder.Neighbors(tt.token) {
+			if edge.TokenIn != tt.token {
+				t.Errorf("代币 %d 的邻接边输入代币为 %d", tt.token, edge.TokenIn)
+			}
+		}
+	}
+}
+
+func TestFindAllPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   uint
+		maxHops int
+		want    []string
+	}{
+		{
+			// 只有同一代币对不同池子之间的往返
+			name: "2 跳", start: weth, maxHops: 2,
+			want: []string{"aa-bb", "bb-aa"},
+		},
+		{
+			name: "3 跳", start: weth, maxHops: 3,
+			want: []string{"aa-bb", "aa-cc-dd", "bb-aa", "bb-cc-dd", "dd-cc-aa", "dd-cc-bb"},
+		},
+		{
+			// 图中没有 4 个不同代币的环，结果与 3 跳相同
+			name: "4 跳", start: weth, maxHops: 4,
+			want: []string{"aa-bb", "aa-cc-dd", "bb-aa", "bb-cc-dd", "dd-cc-aa", "dd-cc-bb"},
+		},
+		{
+			name: "从 DAI 出发", start: dai, maxHops: 3,
+			want: []string{"cc-aa-dd", "cc-bb-dd", "dd-aa-cc", "dd-bb-cc"},
+		},
+		{
+			// 单个池子不能自己构成闭环
+			name: "只有一个池子的代币", start: wbtc, maxHops: 3, want: nil,
+		},
+		{
+			name: "不足 2 跳", start: weth, maxHops: 1, want: nil,
+		},
+	}
+
+	finder := NewPathFinder()
+	finder.BuildTokenGraph(testPairs())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := finder.FindAllPaths(tt.start, tt.maxHops)
+			if got := poolNames(paths); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAllPaths = %v, 期望 %v", got, tt.want)
+			}
+
+			for _, path := range paths {
+				tokens := path.Tokens()
+				if tokens[0] != tt.start || tokens[len(tokens)-1] != tt.start {
+					t.Errorf("路径 %v 不是从 %d 出发的闭环", tokens, tt.start)
+				}
+				for i := 1; i < len(path); i++ {
+					if path[i].TokenIn != path[i-1].TokenOut {
+						t.Errorf("路径 %v 第 %d 跳不连续", poolNames([]Path{path}), i)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFindAllPathsDeterministic(t *testing.T) {
+	pairs := testPairs()
+	first := NewPathFinder()
+	first.BuildTokenGraph(pairs)
+	want := poolNames(first.FindAllPaths(weth, 3))
+
+	// 不同的传入顺序得到完全相同的路径顺序
+	orders := [][]int{{4, 3, 2, 1, 0}, {2, 0, 4, 1, 3}, {1, 3, 0, 4, 2}}
+	for _, order := range orders {
+		shuffled := make([]models.TradingPair, len(order))
+		for i, idx := range order {
+			shuffled[i] = pairs[idx]
+		}
+
+		finder := NewPathFinder()
+		finder.BuildTokenGraph(shuffled)
+		if got := poolNames(finder.FindAllPaths(weth, 3)); !reflect.DeepEqual(got, want) {
+			t.Errorf("传入顺序 %v 时 FindAllPaths = %v, 期望 %v", order, got, want)
+		}
+	}
+}
+
+func TestBuildTokenGraphFeeTier(t *testing.T) {
+	finder := NewPathFinder()
+	finder.BuildTokenGraph([]models.TradingPair{
+		{ID: 1, PairAddress: "0x01", Token0ID: weth, Token1ID: usdc, Fee: 500},
+		// 老数据未记录池子费率时回退到 DEX 配置
+		{ID: 2, PairAddress: "0x02", Token0ID: weth, Token1ID: usdc, Dex: models.Dex{FeeTier: 3000}},
+	})
+
+	edges := finder.Neighbors(usdc)
+	if len(edges) != 2 || edges[0].FeeTier != 500 || edges[1].FeeTier != 3000 {
+		t.Errorf("USDC 的邻接边 = %+v, 期望费率 500 和 3000", edges)
+	}
+}
+
+func TestBuildTokenGraphSkipsHoneypot(t *testing.T) {
+	pairs := testPairs()
+	pairs[4].Token0 = models.Token{IsHoneypot: true} // WBTC 被标记为蜜罐
+
+	finder := NewPathFinder()
+	finder.BuildTokenGraph(pairs)
+
+	if edges := finder.Neighbors(wbtc); len(edges) != 0 {
+		t.Errorf("蜜罐代币仍有邻接边: %+v", edges)
+	}
+	if got, want := poolNames([]Path{finder.Neighbors(weth)})[0], "aa-bb-dd"; got != want {
+		t.Errorf("WETH 的邻接池子 = %s, 期望 %s", got, want)
+	}
+}
+
+func TestFindAllPathsMaxCandidatePaths(t *testing.T) {
+	liquidity := map[uint]float64{1: 100, 2: 50, 3: 1000, 4: 200}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []string
+	}{
+		{name: "不限制", limit: 0, want: []string{"aa-bb", "aa-cc-dd", "bb-aa", "bb-cc-dd", "dd-cc-aa", "dd-cc-bb"}},
+		{name: "上限大于路径数", limit: 10, want: []string{"aa-bb", "aa-cc-dd", "bb-aa", "bb-cc-dd", "dd-cc-aa", "dd-cc-bb"}},
+		{
+			// 经过 aa + dd 的两条路径最小流动性为 100，其余都经过 bb（50）
+			name: "保留流动性最大的路径", limit: 2, want: []string{"aa-cc-dd", "dd-cc-aa"},
+		},
+		{
+			// 流动性相同时保留先找到的路径，结果仍按深度优先顺序
+			name: "流动性相同时保留先找到的", limit: 3, want: []string{"aa-bb", "aa-cc-dd", "dd-cc-aa"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := NewPathFinderWithPolicy(SearchPolicy{MaxCandidatePaths: tt.limit})
+			finder.BuildTokenGraphWithLiquidity(testPairs(), liquidity)
+
+			if got := poolNames(finder.FindAllPaths(weth, 3)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAllPaths = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// withSymbols 给测试交易对加上代币符号（枢纽代币按符号识别）
+func withSymbols(pairs []models.TradingPair) []models.TradingPair {
+	symbols := map[uint]string{weth: "WETH", usdc: "USDC", dai: "DAI", wbtc: "WBTC"}
+	for i := range pairs {
+		pairs[i].Token0.Symbol = symbols[pairs[i].Token0ID]
+		pairs[i].Token1.Symbol = symbols[pairs[i].Token1ID]
+	}
+	return pairs
+}
+
+func TestFindAllPathsHubTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy SearchPolicy
+		start  uint
+		want   []string
+	}{
+		{
+			name: "不限制", policy: SearchPolicy{}, start: weth,
+			want: []string{"aa-bb", "aa-cc-dd", "bb-aa", "bb-cc-dd", "dd-cc-aa", "dd-cc-bb"},
+		},
+		{
+			// DAI 不是枢纽代币，经过 DAI 的三角路径被排除
+			name: "只经过枢纽代币", policy: SearchPolicy{HubTokens: []string{"weth", "usdc"}}, start: weth,
+			want: []string{"aa-bb", "bb-aa"},
+		},
+		{
+			name: "允许一个非枢纽代币", policy: SearchPolicy{HubTokens: []string{"WETH", "USDC"}, MaxNonHubTokens: 1}, start: weth,
+			want: []string{"aa-bb", "aa-cc-dd", "bb-aa", "bb-cc-dd", "dd-cc-aa", "dd-cc-bb"},
+		},
+		{
+			// 起点不是中间代币，不受限制
+			name: "从非枢纽代币出发", policy: SearchPolicy{HubTokens: []string{"WETH", "USDC"}}, start: dai,
+			want: []string{"cc-aa-dd", "cc-bb-dd", "dd-aa-cc", "dd-bb-cc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := NewPathFinderWithPolicy(tt.policy)
+			finder.BuildTokenGraph(withSymbols(testPairs()))
+
+			if got := poolNames(finder.FindAllPaths(tt.start, 3)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAllPaths = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSearchPolicyHubMode(t *testing.T) {
+	tests := []struct {
+		mode string
+		want int
+	}{
+		{mode: "", want: 0},
+		{mode: config.HubModeHubsOnly, want: 0},
+		{mode: config.HubModeOneWildcard, want: 1},
+	}
+	for _, tt := range tests {
+		policy := NewSearchPolicy(config.ArbitrageConfig{HubTokens: []string{"WETH"}, HubMode: tt.mode})
+		if policy.MaxNonHubTokens != tt.want {
+			t.Errorf("hub_mode %q 的 MaxNonHubTokens = %d, 期望 %d", tt.mode, policy.MaxNonHubTokens, tt.want)
+		}
+	}
+}
+
+func TestFindAllPathsContextBudget(t *testing.T) {
+	all := []string{"aa-bb", "aa-cc-dd", "bb-aa", "bb-cc-dd", "dd-cc-aa", "dd-cc-bb"}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		policy  SearchPolicy
+		want    []string
+		wantHit bool
+	}{
+		{name: "不限制", ctx: context.Background(), want: all},
+		{name: "预算足够", ctx: context.Background(), policy: SearchPolicy{MaxPathsExplored: 100}, want: all},
+		{
+			// 只扩展 WETH -> USDC（aa）一个节点：能找到 aa-bb，继续扩展 USDC -> DAI 时预算耗尽
+			name: "节点数耗尽时返回已找到的路径", ctx: context.Background(), policy: SearchPolicy{MaxPathsExplored: 1},
+			want: []string{"aa-bb"}, wantHit: true,
+		},
+		{name: "ctx 已结束", ctx: cancelled, want: nil, wantHit: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := NewPathFinderWithPolicy(tt.policy)
+			finder.BuildTokenGraph(testPairs())
+
+			if got := poolNames(finder.FindAllPathsContext(tt.ctx, weth, 3)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAllPathsContext = %v, 期望 %v", got, tt.want)
+			}
+
+			searches, hits := finder.BudgetStats()
+			if searches != 1 || (hits == 1) != tt.wantHit {
+				t.Errorf("BudgetStats = (%d, %d), 期望 1 次搜索、触发预算 %v", searches, hits, tt.wantHit)
+			}
+		})
+	}
+}
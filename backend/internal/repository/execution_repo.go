@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/defi-bot/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 执行记录状态
+const (
+	ExecutionStatusPending = "pending"
+	ExecutionStatusSuccess = "success"
+	ExecutionStatusFailed  = "failed"
+)
+
+// ExecutionRepository 套利执行记录仓储
+// 以 (opportunity_id, nonce) 作为幂等键：发送交易前先写入 pending 记录，
+// 瞬时错误后重试时复用同一条记录，避免重复计算利润
+type ExecutionRepository struct {
+	db           *gorm.DB
+	nativeSymbol string // Gas 计价代币的符号（用其 price_usd 把 Gas 成本换算为美元）
+}
+
+// defaultNativeSymbol 默认的 Gas 计价代币
+const defaultNativeSymbol = "WETH"
+
+// NewExecutionRepository 创建执行记录仓储
+func NewExecutionRepository(db *gorm.DB) *ExecutionRepository {
+	return &ExecutionRepository{db: db, nativeSymbol: defaultNativeSymbol}
+}
+
+// SetNativeSymbol 设置 Gas 计价代币的符号（默认 WETH）
+func (r *ExecutionRepository) SetNativeSymbol(symbol string) {
+	if symbol != "" {
+		r.nativeSymbol = symbol
+	}
+}
+
+// BeginAttempt 发送交易前登记执行记录（幂等）
+// exec.VaultAddress 记录本次执行使用的金库（资金来源），用于按金库统计利润
+// 同一 (opportunity_id, nonce) 已存在时复用该记录并刷新发送参数，状态重置为 pending；
+// 已完成（success / failed）的记录不会被覆盖，直接返回。
+// 没有幂等键（手动执行 opportunity_id 为 0，或 nonce 为 0）时每次都新增记录
+func (r *ExecutionRepository) BeginAttempt(exec *models.ArbitrageExecution) (*models.ArbitrageExecution, error) {
+	exec.Status = ExecutionStatusPending
+
+	if exec.OpportunityID == 0 || exec.Nonce == 0 {
+		if err := r.db.Create(exec).Error; err != nil {
+			return nil, fmt.Errorf("登记执行记录失败: %w", err)
+		}
+		return exec, nil
+	}
+
+	// 冲突目标需带上部分唯一索引的条件，PostgreSQL 才能匹配到 idx_exec_opp_nonce_key
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "opportunity_id"}, {Name: "nonce"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "opportunity_id <> 0 AND nonce <> 0"},
+		}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"vault_address", "amount_in", "swap_path", "dex_path", "gas_price", "updated_at",
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: "arbitrage_executions", Name: "status"}, Value: ExecutionStatusPending},
+		}},
+	}).Create(exec).Error
+	if err != nil {
+		return nil, fmt.Errorf("登记执行记录失败: %w", err)
+	}
+
+	// 重新读取，拿到已存在记录的 ID 和当前状态
+	var stored models.ArbitrageExecution
+	err = r.db.Where("opportunity_id = ? AND nonce = ?", exec.OpportunityID, exec.Nonce).
+		First(&stored).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询执行记录失败: %w", err)
+	}
+
+	return &stored, nil
+}
+
+// SetTxHash 交易发送后记录交易哈希
+func (r *ExecutionRepository) SetTxHash(id uint, txHash string) error {
+	err := r.db.Model(&models.ArbitrageExecution{}).
+		Where("id = ?", id).
+		Update("tx_hash", txHash).Error
+	if err != nil {
+		return fmt.Errorf("更新交易哈希失败: %w", err)
+	}
+	return nil
+}
+
+// Complete 收到回执后写入最终结果（status 为 success 或 failed）
+// result.BlockNumber 为交易上链区块，非 0 时同时记录相对机会发现区块的漂移；
+// 按完成时的价格记录扣除 Gas 后的净利润和 ROI，写入后推送执行结果通知
+// 只更新仍为 pending 的记录，重复回调不会覆盖已记录的结果
+func (r *ExecutionRepository) Complete(id uint, status string, result *models.ArbitrageExecution) error {
+	if status != ExecutionStatusSuccess && status != ExecutionStatusFailed {
+		return fmt.Errorf("无效的执行状态: %s", status)
+	}
+
+	updated := r.db.Model(&models.ArbitrageExecution{}).
+		Where("id = ? AND status = ?", id, ExecutionStatusPending).
+		Updates(map[string]interface{}{
+			"status":            status,
+			"amount_out":        result.AmountOut,
+			"actual_profit":     result.ActualProfit,
+			"profit_rate":       result.ProfitRate,
+			"gas_used":          result.GasUsed,
+			"block_number":      result.BlockNumber,
+			"error_message":     result.ErrorMessage,
+			"execution_time_ms": result.ExecutionTimeMs,
+		})
+	if updated.Error != nil {
+		return fmt.Errorf("更新执行结果失败: %w", updated.Error)
+	}
+	if updated.RowsAffected == 0 {
+		return nil // 重复回调
+	}
+
+	if result.BlockNumber > 0 {
+		if err := r.recordDrift(id, result.BlockNumber, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	stored, err := r.recordNetProfit(id)
+	if err != nil {
+		return err
+	}
+	notifyExecution(stored)
+	return nil
+}
+
+// recordNetProfit 按完成时的代币价格计算并写入净利润、美元净利润和 ROI，返回最终记录
+// 输入代币或 Gas 计价代币没有价格时三项写入 NULL（不会把 Gas 成本当作 0）；Gas 计价代币不存在时返回错误
+func (r *ExecutionRepository) recordNetProfit(id uint) (*models.ArbitrageExecution, error) {
+	var stored models.ArbitrageExecution
+	if err := r.db.Preload("TokenIn").First(&stored, id).Error; err != nil {
+		return nil, fmt.Errorf("查询执行记录失败: %w", err)
+	}
+
+	var native models.Token
+	if err := r.db.Where("symbol = ?", r.nativeSymbol).First(&native).Error; err != nil {
+		return nil, fmt.Errorf("查询 Gas 计价代币 %s 失败: %w", r.nativeSymbol, err)
+	}
+
+	if !stored.Valuate(native.PriceUSD) {
+		log.Printf("⚠️  执行记录 %d 缺少价格（%s=$%g, %s=$%g），净利润记为空",
+			id, r.nativeSymbol, native.PriceUSD, stored.TokenIn.Symbol, stored.TokenIn.PriceUSD)
+	}
+
+	err := r.db.Model(&models.ArbitrageExecution{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"net_profit":     stored.NetProfit,
+			"net_profit_usd": stored.NetProfitUSD,
+			"roi":            stored.ROI,
+		}).Error
+	if err != nil {
+		return nil, fmt.Errorf("更新净利润失败: %w", err)
+	}
+	return &stored, nil
+}
+
+// recordDrift 根据关联机会的发现区块和首次发现时间记录区块漂移和发现到上链的延迟
+// 手动执行（无关联机会）或机会未记录发现区块时跳过（保持 NULL）
+func (r *ExecutionRepository) recordDrift(id uint, includedBlock uint64, receivedAt time.Time) error {
+	var opp models.ArbitrageOpportunity
+	err := r.db.Select("arbitrage_opportunities.discovery_block", "arbitrage_opportunities.discovered_at").
+		Joins("JOIN arbitrage_executions ON arbitrage_executions.opportunity_id = arbitrage_opportunities.id").
+		Where("arbitrage_executions.id = ?", id).
+		First(&opp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("查询执行关联的套利机会失败: %w", err)
+	}
+	if opp.DiscoveryBlock == 0 || includedBlock < opp.DiscoveryBlock {
+		return nil
+	}
+
+	// 区块漂移相对最近一次发现的区块（执行基于的数据），延迟从首次发现算起（discovered_at 不随重复发现刷新）
+	updates := map[string]interface{}{
+		"block_drift": int64(includedBlock - opp.DiscoveryBlock),
+	}
+	if !opp.DiscoveredAt.IsZero() {
+		updates["latency_ms"] = receivedAt.Sub(opp.DiscoveredAt).Milliseconds()
+	}
+
+	err = r.db.Model(&models.ArbitrageExecution{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+	if err != nil {
+		return fmt.Errorf("更新区块漂移失败: %w", err)
+	}
+	return nil
+}
+
+// DriftBucket 区块漂移直方图的一个桶
+type DriftBucket struct {
+	BlockDrift    int64    `json:"block_drift"`     // 漂移区块数（0 表示同块上链）
+	Executions    int64    `json:"executions"`      // 执行次数
+	Successful    int64    `json:"successful"`      // 成功次数
+	MeanLatencyMs *float64 `json:"mean_latency_ms"` // 平均发现到上链延迟（毫秒，桶内都未记录延迟时为 null）
+}
+
+// BlockDriftHistogram 统计 since 之后完成的执行的区块漂移分布（只包含已记录漂移的执行，含同块上链的 0）
+func (r *ExecutionRepository) BlockDriftHistogram(since time.Time) ([]DriftBucket, error) {
+	var buckets []DriftBucket
+	err := r.db.Model(&models.ArbitrageExecution{}).
+		Select(`block_drift,
+			COUNT(*) AS executions,
+			COUNT(*) FILTER (WHERE status = ?) AS successful,
+			AVG(latency_ms) AS mean_latency_ms`, ExecutionStatusSuccess).
+		Where("block_drift IS NOT NULL AND timestamp >= ?", since).
+		Group("block_drift").
+		Order("block_drift").
+		Scan(&buckets).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计区块漂移失败: %w", err)
+	}
+	return buckets, nil
+}
+
+// VaultProfit 单个金库、单个输入代币的利润汇总
+// 利润以输入代币最小单位计，不同代币不能直接相加，因此按 (vault, token_in) 分组
+type VaultProfit struct {
+	VaultAddress string `json:"vault_address"`
+	TokenInID    uint   `json:"token_in_id"`
+	Executions   int64  `json:"executions"`     // 执行次数（含失败）
+	Successful   int64  `json:"successful"`     // 成功次数
+	TotalProfit  string `json:"total_profit"`   // 成功执行的实际利润合计
+	TotalGasCost string `json:"total_gas_cost"` // Gas 成本合计（wei，含失败的执行）
+}
+
+// ProfitByVault 按金库汇总 since 之后完成的执行（多金库记账）
+func (r *ExecutionRepository) ProfitByVault(since time.Time) ([]VaultProfit, error) {
+	var results []VaultProfit
+	err := r.db.Model(&models.ArbitrageExecution{}).
+		Select(`vault_address, token_in_id,
+			COUNT(*) AS executions,
+			COUNT(*) FILTER (WHERE status = ?) AS successful,
+			COALESCE(SUM(CASE WHEN status = ? THEN NULLIF(actual_profit, '')::numeric END), 0)::text AS total_profit,
+			COALESCE(SUM(gas_used::numeric * NULLIF(gas_price, '')::numeric), 0)::text AS total_gas_cost`,
+			ExecutionStatusSuccess, ExecutionStatusSuccess).
+		Where("status IN ? AND timestamp >= ?", []string{ExecutionStatusSuccess, ExecutionStatusFailed}, since).
+		Group("vault_address, token_in_id").
+		Order("vault_address, token_in_id").
+		Scan(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("按金库统计利润失败: %w", err)
+	}
+	return results, nil
+}
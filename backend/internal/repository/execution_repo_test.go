@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/defi-bot/backend/internal/models"
+)
+
+// newTestExecution 构造一条待发送的执行记录
+func newTestExecution(opp *models.ArbitrageOpportunity, nonce uint64) *models.ArbitrageExecution {
+	return &models.ArbitrageExecution{
+		OpportunityID: opp.ID,
+		Nonce:         nonce,
+		TokenInID:     opp.TokenInID,
+		TokenOutID:    opp.TokenOutID,
+		AmountIn:      "1000",
+		AmountOut:     "0",
+		ActualProfit:  "0",
+		SwapPath:      opp.SwapPath,
+		DexPath:       opp.DexPath,
+		GasPrice:      "1000000000",
+		Timestamp:     time.Now(),
+	}
+}
+
+func TestBeginAttemptIdempotent(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewExecutionRepository(db)
+
+	token := createTestToken(t, db, "WETH", 18, 2000)
+	opp := createTestOpportunity(t, db, token)
+
+	first, err := repo.BeginAttempt(newTestExecution(opp, 7))
+	if err != nil {
+		t.Fatalf("第一次登记失败: %v", err)
+	}
+
+	// 重试：同一 (opportunity_id, nonce) 复用记录并刷新发送参数
+	retry := newTestExecution(opp, 7)
+	retry.GasPrice = "2000000000"
+	second, err := repo.BeginAttempt(retry)
+	if err != nil {
+		t.Fatalf("重试登记失败: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("重试应复用记录 %d，实际为 %d", first.ID, second.ID)
+	}
+	if second.GasPrice != "2000000000" {
+		t.Errorf("重试应刷新 gas_price，实际为 %s", second.GasPrice)
+	}
+
+	// 已完成的记录不会被后续登记覆盖
+	if err := db.Model(&models.ArbitrageExecution{}).Where("id = ?", first.ID).
+		Update("status", ExecutionStatusFailed).Error; err != nil {
+		t.Fatalf("更新状态失败: %v", err)
+	}
+	third, err := repo.BeginAttempt(newTestExecution(opp, 7))
+	if err != nil {
+		t.Fatalf("完成后再次登记失败: %v", err)
+	}
+	if third.ID != first.ID || third.Status != ExecutionStatusFailed {
+		t.Errorf("已完成的记录被覆盖: id=%d status=%s", third.ID, third.Status)
+	}
+
+	// 不同 nonce 是新的尝试
+	other, err := repo.BeginAttempt(newTestExecution(opp, 8))
+	if err != nil {
+		t.Fatalf("登记新 nonce 失败: %v", err)
+	}
+	if other.ID == first.ID {
+		t.Error("不同 nonce 应新增记录")
+	}
+
+	var count int64
+	db.Model(&models.ArbitrageExecution{}).Count(&count)
+	if count != 2 {
+		t.Errorf("执行记录数 = %d, 期望 2", count)
+	}
+}
+
+func TestBeginAttemptWithoutKey(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewExecutionRepository(db)
+
+	token := createTestToken(t, db, "WETH", 18, 2000)
+	opp := createTestOpportunity(t, db, token)
+
+	// nonce 为 0（未分配 / 历史数据）不参与部分唯一索引，每次都新增
+	for i := 0; i < 2; i++ {
+		if _, err := repo.BeginAttempt(newTestExecution(opp, 0)); err != nil {
+			t.Fatalf("第 %d 次登记失败: %v", i+1, err)
+		}
+	}
+
+	var count int64
+	db.Model(&models.ArbitrageExecution{}).Count(&count)
+	if count != 2 {
+		t.Errorf("执行记录数 = %d, 期望 2", count)
+	}
+}
+
+func TestRecordDriftSameBlock(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewExecutionRepository(db)
+
+	weth := createTestToken(t, db, "WETH", 18, 2000)
+	opp := createTestOpportunity(t, db, weth)
+
+	discoveredAt := time.Now().Add(-3 * time.Second)
+	db.Model(opp).Updates(map[string]interface{}{"discovery_block": 100, "discovered_at": discoveredAt})
+
+	exec, err := repo.BeginAttempt(newTestExecution(opp, 1))
+	if err != nil {
+		t.Fatalf("登记执行记录失败: %v", err)
+	}
+
+	// 重复发现刷新 updated_at，不应影响延迟
+	db.Model(opp).Update("expected_profit", "11")
+
+	if err := repo.recordDrift(exec.ID, 100, time.Now()); err != nil {
+		t.Fatalf("recordDrift 失败: %v", err)
+	}
+
+	var stored models.ArbitrageExecution
+	db.First(&stored, exec.ID)
+	if stored.BlockDrift == nil || *stored.BlockDrift != 0 {
+		t.Fatalf("同块上链的区块漂移应为 0，实际为 %v", stored.BlockDrift)
+	}
+	if stored.LatencyMs == nil || *stored.LatencyMs < 3000 {
+		t.Errorf("延迟应从首次发现算起（>= 3000ms），实际为 %v", stored.LatencyMs)
+	}
+
+	buckets, err := repo.BlockDriftHistogram(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("BlockDriftHistogram 失败: %v", err)
+	}
+	if len(buckets) != 1 || buckets[0].BlockDrift != 0 || buckets[0].Executions != 1 {
+		t.Errorf("直方图应包含漂移为 0 的桶: %+v", buckets)
+	}
+
+	mean, err := NewOpportunityRepository(db, OpportunityPersistPolicy{}).MeanBlockDrift(opp.PathSignature, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("MeanBlockDrift 失败: %v", err)
+	}
+	if mean != 0 {
+		t.Errorf("平均漂移 = %v, 期望 0", mean)
+	}
+}
+
+func TestRecordNetProfit(t *testing.T) {
+	db := openTestDB(t)
+
+	weth := createTestToken(t, db, "WETH", 18, 2000)
+	wbnb := createTestToken(t, db, "WBNB", 18, 0)
+	opp := createTestOpportunity(t, db, weth)
+
+	exec, err := NewExecutionRepository(db).BeginAttempt(newTestExecution(opp, 1))
+	if err != nil {
+		t.Fatalf("登记执行记录失败: %v", err)
+	}
+	db.Model(exec).Updates(map[string]interface{}{"status": ExecutionStatusSuccess, "actual_profit": "1000", "gas_used": 1})
+
+	t.Run("价格已知", func(t *testing.T) {
+		stored, err := NewExecutionRepository(db).recordNetProfit(exec.ID)
+		if err != nil {
+			t.Fatalf("recordNetProfit 失败: %v", err)
+		}
+		// Gas 1 × 1 gwei，原生币与输入代币同价：净利润 = 1000 - 1e9
+		if stored.NetProfit == nil || *stored.NetProfit != "-999999000" {
+			t.Errorf("NetProfit = %v, 期望 -999999000", stored.NetProfit)
+		}
+	})
+
+	t.Run("原生币价格未知时写入 NULL", func(t *testing.T) {
+		repo := NewExecutionRepository(db)
+		repo.SetNativeSymbol(wbnb.Symbol)
+		if _, err := repo.recordNetProfit(exec.ID); err != nil {
+			t.Fatalf("recordNetProfit 失败: %v", err)
+		}
+
+		var reloaded models.ArbitrageExecution
+		db.First(&reloaded, exec.ID)
+		if reloaded.NetProfit != nil || reloaded.NetProfitUSD != nil || reloaded.ROI != nil {
+			t.Errorf("价格未知时应为 NULL: net=%v usd=%v roi=%v", reloaded.NetProfit, reloaded.NetProfitUSD, reloaded.ROI)
+		}
+	})
+
+	t.Run("原生币不存在时报错", func(t *testing.T) {
+		repo := NewExecutionRepository(db)
+		repo.SetNativeSymbol("MATIC")
+		if _, err := repo.recordNetProfit(exec.ID); err == nil {
+			t.Error("Gas 计价代币不存在时应返回错误")
+		}
+	})
+}
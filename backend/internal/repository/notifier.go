@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"sync/atomic"
+
+	"github.com/defi-bot/backend/internal/models"
+)
+
+// ExecutionNotifier 执行完成后的通知（实现方不能阻塞调用方）
+type ExecutionNotifier interface {
+	NotifyExecution(exec *models.ArbitrageExecution)
+}
+
+// OpportunityNotifier 新套利机会入库后的通知（实现方不能阻塞调用方）
+type OpportunityNotifier interface {
+	NotifyOpportunity(opp *models.ArbitrageOpportunity)
+}
+
+// 全局通知器：仓储在各处按需创建，通知器在启动时设置一次，对所有实例生效
+var (
+	executionNotifier   atomic.Value // ExecutionNotifier
+	opportunityNotifier atomic.Value // OpportunityNotifier
+)
+
+// SetExecutionNotifier 设置执行完成通知器（传 nil 取消）
+func SetExecutionNotifier(n ExecutionNotifier) {
+	executionNotifier.Store(&n)
+}
+
+// SetOpportunityNotifier 设置新套利机会通知器（传 nil 取消）
+func SetOpportunityNotifier(n OpportunityNotifier) {
+	opportunityNotifier.Store(&n)
+}
+
+// notifyExecution 通知执行完成（未设置通知器时跳过）
+func notifyExecution(exec *models.ArbitrageExecution) {
+	if n, ok := executionNotifier.Load().(*ExecutionNotifier); ok && *n != nil {
+		(*n).NotifyExecution(exec)
+	}
+}
+
+// notifyOpportunity 通知新套利机会（未设置通知器时跳过）
+func notifyOpportunity(opp *models.ArbitrageOpportunity) {
+	if n, ok := opportunityNotifier.Load().(*OpportunityNotifier); ok && *n != nil {
+		(*n).NotifyOpportunity(opp)
+	}
+}
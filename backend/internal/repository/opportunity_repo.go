@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/defi-bot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// OpportunityPersistPolicy 套利机会入库策略
+// 每个分析周期都会重复发现同一批机会，全部写入会让 arbitrage_opportunities 表无限膨胀
+type OpportunityPersistPolicy struct {
+	MinProfitRate float64       // 只保存利润率不低于该值的机会（百分比，0 表示不过滤）
+	DedupWindow   time.Duration // 相同路径签名的 pending 机会在窗口内只更新已有记录（0 表示不去重）
+	SampleRate    uint64        // 每 N 条新机会保存 1 条（0 或 1 表示全部保存）
+
+	// MaxMeanDrift 相同路径历史平均区块漂移超过该值时降低优先级（0 表示不降级）
+	// 漂移大说明这类机会等交易上链时往往已被别人吃掉
+	MaxMeanDrift float64
+}
+
+// driftLookback 计算路径历史区块漂移时回看的时间窗口
+const driftLookback = 24 * time.Hour
+
+// OpportunityRepository 套利机会仓储
+type OpportunityRepository struct {
+	db     *gorm.DB
+	policy OpportunityPersistPolicy
+	count  atomic.Uint64 // 新机会计数（用于采样）
+}
+
+// NewOpportunityRepository 创建套利机会仓储
+func NewOpportunityRepository(db *gorm.DB, policy OpportunityPersistPolicy) *OpportunityRepository {
+	return &OpportunityRepository{db: db, policy: policy}
+}
+
+// Save 按入库策略保存套利机会
+// 返回实际保存（或合并到）的记录；被阈值或采样过滤时返回 nil。非闭环路径返回 ErrNonCyclicPath
+func (r *OpportunityRepository) Save(opp *models.ArbitrageOpportunity) (*models.ArbitrageOpportunity, error) {
+	if err := opp.CheckCyclic(); err != nil {
+		return nil, err
+	}
+
+	if r.policy.MinProfitRate > 0 && opp.ProfitRate < r.policy.MinProfitRate {
+		return nil, nil
+	}
+
+	opp.PathSignature = opp.ComputePathSignature()
+
+	if r.policy.MaxMeanDrift > 0 {
+		drift, err := r.MeanBlockDrift(opp.PathSignature, time.Now().Add(-driftLookback))
+		if err != nil {
+			return nil, err
+		}
+		// 超出阈值的部分按区块数扣减优先级
+		if drift > r.policy.MaxMeanDrift {
+			opp.Priority -= int(drift - r.policy.MaxMeanDrift + 1)
+		}
+	}
+
+	// 窗口内已有相同路径的 pending 记录：刷新利润和有效期，不新增行
+	if r.policy.DedupWindow > 0 {
+		existing, err := r.findRecent(opp.PathSignature)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			err := r.db.Model(existing).Updates(map[string]interface{}{
+				"amount_in":         opp.AmountIn,
+				"expected_profit":   opp.ExpectedProfit,
+				"min_profit":        opp.MinProfit,
+				"profit_rate":       opp.ProfitRate,
+				"expected_slippage": opp.ExpectedSlippage,
+				"gas_estimate":      opp.GasEstimate,
+				"expires_at":        opp.ExpiresAt,
+				"valid_until_block": opp.ValidUntilBlock,
+				"discovery_block":   opp.DiscoveryBlock,
+				"priority":          opp.Priority,
+			}).Error
+			if err != nil {
+				return nil, fmt.Errorf("更新套利机会 %d 失败: %w", existing.ID, err)
+			}
+			return existing, nil
+		}
+	}
+
+	if rate := r.policy.SampleRate; rate > 1 && (r.count.Add(1)-1)%rate != 0 {
+		return nil, nil
+	}
+
+	if opp.DiscoveredAt.IsZero() {
+		opp.DiscoveredAt = time.Now()
+	}
+
+	if err := r.db.Create(opp).Error; err != nil {
+		return nil, fmt.Errorf("保存套利机会失败: %w", err)
+	}
+	notifyOpportunity(opp)
+	return opp, nil
+}
+
+// findRecent 查找去重窗口内相同路径签名的 pending 机会
+func (r *OpportunityRepository) findRecent(signature string) (*models.ArbitrageOpportunity, error) {
+	var opp models.ArbitrageOpportunity
+	err := r.db.Where("path_signature = ? AND status = ? AND updated_at >= ?",
+		signature, "pending", time.Now().Add(-r.policy.DedupWindow)).
+		Order("updated_at DESC").
+		First(&opp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询重复套利机会失败: %w", err)
+	}
+	return &opp, nil
+}
+
+// MeanBlockDrift 相同路径签名的机会在 since 之后执行的平均区块漂移（同块上链的 0 也计入；没有记录时返回 0）
+func (r *OpportunityRepository) MeanBlockDrift(signature string, since time.Time) (float64, error) {
+	var mean float64
+	err := r.db.Model(&models.ArbitrageExecution{}).
+		Select("COALESCE(AVG(arbitrage_executions.block_drift), 0)").
+		Joins("JOIN arbitrage_opportunities ON arbitrage_opportunities.id = arbitrage_executions.opportunity_id").
+		Where("arbitrage_opportunities.path_signature = ?", signature).
+		Where("arbitrage_executions.block_drift IS NOT NULL AND arbitrage_executions.timestamp >= ?", since).
+		Scan(&mean).Error
+	if err != nil {
+		return 0, fmt.Errorf("查询路径区块漂移失败: %w", err)
+	}
+	return mean, nil
+}
+
+// BestOpportunityFilter 每个代币对最佳机会的查询条件
+type BestOpportunityFilter struct {
+	ArbitrageType string  // 只看指定套利类型（空表示全部）
+	MinProfitRate float64 // 利润率下限（百分比，0 表示不过滤）
+	Limit         int     // 最多返回的代币对数量（<= 0 表示不限制）
+}
+
+// BestPerPair 每个代币对（token_in, token_out）当前利润率最高的一条未过期 pending 机会，按利润率降序
+// 窗口函数在 idx_opp_pending_best 部分索引上完成分组排序，不需要扫描整张表
+func (r *OpportunityRepository) BestPerPair(filter BestOpportunityFilter) ([]models.ArbitrageOpportunity, error) {
+	ranked := r.db.Model(&models.ArbitrageOpportunity{}).
+		Select("arbitrage_opportunities.*, ROW_NUMBER() OVER (PARTITION BY token_in_id, token_out_id ORDER BY profit_rate DESC, id DESC) AS rn").
+		Where("status = ? AND expires_at > ?", "pending", time.Now())
+	if filter.ArbitrageType != "" {
+		ranked = ranked.Where("arbitrage_type = ?", filter.ArbitrageType)
+	}
+	if filter.MinProfitRate > 0 {
+		ranked = ranked.Where("profit_rate >= ?", filter.MinProfitRate)
+	}
+
+	query := r.db.Table("(?) AS ranked", ranked).
+		Preload("TokenIn").
+		Preload("TokenOut").
+		Where("rn = 1").
+		Order("profit_rate DESC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var opportunities []models.ArbitrageOpportunity
+	if err := query.Find(&opportunities).Error; err != nil {
+		return nil, fmt.Errorf("查询代币对最佳套利机会失败: %w", err)
+	}
+	return opportunities, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/defi-bot/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PairLatestRepository 交易对最新状态仓储
+type PairLatestRepository struct {
+	db *gorm.DB
+}
+
+// NewPairLatestRepository 创建交易对最新状态仓储
+func NewPairLatestRepository(db *gorm.DB) *PairLatestRepository {
+	return &PairLatestRepository{db: db}
+}
+
+// Upsert 批量写入最新状态，只覆盖区块号不更旧的记录（乱序写入时不会回退）
+func (r *PairLatestRepository) Upsert(rows []models.PairLatest) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "pair_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"reserve0", "reserve1", "price", "inverse_price",
+			"sqrt_price_x96", "tick", "liquidity",
+			"block_number", "block_hash", "timestamp", "updated_at",
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "pair_latest.block_number <= excluded.block_number"},
+		}},
+	}).CreateInBatches(rows, 1000).Error
+	if err != nil {
+		return fmt.Errorf("更新交易对最新状态失败: %w", err)
+	}
+	return nil
+}
+
+// Get 读取单个交易对的最新状态，不存在时 ok 为 false
+func (r *PairLatestRepository) Get(pairID uint) (*models.PairLatest, bool, error) {
+	var latest models.PairLatest
+	err := r.db.Where("pair_id = ?", pairID).First(&latest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("查询交易对 %d 最新状态失败: %w", pairID, err)
+	}
+	return &latest, true, nil
+}
+
+// List 读取所有交易对的最新状态（预加载交易对、代币和 DEX）
+func (r *PairLatestRepository) List() ([]models.PairLatest, error) {
+	var rows []models.PairLatest
+	err := r.db.Preload("Pair").
+		Preload("Pair.Token0").
+		Preload("Pair.Token1").
+		Preload("Pair.Dex").
+		Order("pair_id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询交易对最新状态失败: %w", err)
+	}
+	return rows, nil
+}
+
+// Rebuild 从 price_records 历史重建最新状态表，返回写入的交易对数量
+// 价格记录包含储备量和价格的完整快照，每个交易对取最新一条
+func (r *PairLatestRepository) Rebuild() (int64, error) {
+	var count int64
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM pair_latest").Error; err != nil {
+			return fmt.Errorf("清空交易对最新状态失败: %w", err)
+		}
+
+		result := tx.Exec(`
+			INSERT INTO pair_latest (
+				pair_id, reserve0, reserve1, price, inverse_price,
+				sqrt_price_x96, tick, liquidity,
+				block_number, block_hash, timestamp, updated_at
+			)
+			SELECT DISTINCT ON (pair_id)
+				pair_id, reserve0, reserve1, price, inverse_price,
+				sqrt_price_x96, tick, liquidity,
+				block_number, block_hash, timestamp, NOW()
+			FROM price_records
+			ORDER BY pair_id, block_number DESC, id DESC`)
+		if result.Error != nil {
+			return fmt.Errorf("重建交易对最新状态失败: %w", result.Error)
+		}
+		count = result.RowsAffected
+		return nil
+	})
+
+	return count, err
+}
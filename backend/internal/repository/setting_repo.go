@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/defi-bot/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SettingRepository 运行时设置仓储
+type SettingRepository struct {
+	db *gorm.DB
+}
+
+// NewSettingRepository 创建运行时设置仓储
+func NewSettingRepository(db *gorm.DB) *SettingRepository {
+	return &SettingRepository{db: db}
+}
+
+// Get 读取设置项，不存在时 ok 为 false
+func (r *SettingRepository) Get(key string) (*models.Setting, bool, error) {
+	var setting models.Setting
+	err := r.db.Where("key = ?", key).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("查询设置 %s 失败: %w", key, err)
+	}
+	return &setting, true, nil
+}
+
+// Set 写入设置项（不存在时创建）
+func (r *SettingRepository) Set(key, value string) error {
+	setting := models.Setting{Key: key, Value: value, UpdatedAt: time.Now()}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&setting).Error
+	if err != nil {
+		return fmt.Errorf("保存设置 %s 失败: %w", key, err)
+	}
+	return nil
+}
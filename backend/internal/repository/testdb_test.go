@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/internal/testutil/pgtest"
+	"gorm.io/gorm"
+)
+
+// openTestDB 在一次性 schema 中建好仓储测试用到的表（未设置 TEST_DATABASE_DSN 时跳过）
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := pgtest.Open(t)
+	err := db.AutoMigrate(
+		&models.Token{},
+		&models.Dex{},
+		&models.TradingPair{},
+		&models.ArbitrageOpportunity{},
+		&models.ArbitrageExecution{},
+	)
+	if err != nil {
+		t.Fatalf("迁移测试表失败: %v", err)
+	}
+	return db
+}
+
+// createTestToken 写入一个测试代币
+func createTestToken(t *testing.T, db *gorm.DB, symbol string, decimals int, priceUSD float64) *models.Token {
+	t.Helper()
+
+	token := &models.Token{
+		Address:  fmt.Sprintf("0x%040x", time.Now().UnixNano()),
+		Symbol:   symbol,
+		Decimals: decimals,
+		ChainID:  1,
+		PriceUSD: priceUSD,
+	}
+	if err := db.Create(token).Error; err != nil {
+		t.Fatalf("创建代币 %s 失败: %v", symbol, err)
+	}
+	return token
+}
+
+// createTestOpportunity 写入一条闭环的 pending 套利机会
+func createTestOpportunity(t *testing.T, db *gorm.DB, token *models.Token) *models.ArbitrageOpportunity {
+	t.Helper()
+
+	opp := &models.ArbitrageOpportunity{
+		TokenInID:      token.ID,
+		TokenOutID:     token.ID,
+		AmountIn:       "1000",
+		ExpectedProfit: "10",
+		MinProfit:      "5",
+		ProfitRate:     1,
+		SwapPath:       fmt.Sprintf(`["%s","%s"]`, token.Address, token.Address),
+		DexPath:        `["a","b"]`,
+		DexRouters:     `["0x1","0x2"]`,
+		GasEstimate:    200000,
+		ExpiresAt:      time.Now().Add(time.Minute),
+	}
+	opp.PathSignature = opp.ComputePathSignature()
+	if err := db.Create(opp).Error; err != nil {
+		t.Fatalf("创建套利机会失败: %v", err)
+	}
+	return opp
+}
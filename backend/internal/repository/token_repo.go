@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/defi-bot/backend/internal/models"
+	"github.com/defi-bot/backend/pkg/web3"
+	"gorm.io/gorm"
+)
+
+// TokenRepository 代币仓储
+// 集中代币查询逻辑，并使用内存缓存避免重复的按地址查询
+type TokenRepository struct {
+	db *gorm.DB
+
+	mu     sync.RWMutex
+	byAddr map[string]*models.Token // key: 小写地址
+	byID   map[uint]*models.Token
+}
+
+// NewTokenRepository 创建代币仓储
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{
+		db:     db,
+		byAddr: make(map[string]*models.Token),
+		byID:   make(map[uint]*models.Token),
+	}
+}
+
+// GetByAddress 根据合约地址获取代币（不区分大小写，与缓存键一致）
+// 库中地址可能是配置里的校验和格式，也可能是小写；未找到时返回 gorm.ErrRecordNotFound
+func (r *TokenRepository) GetByAddress(address string) (*models.Token, error) {
+	key := addressKey(address)
+	if token, ok := r.getCached(key); ok {
+		return token, nil
+	}
+
+	var token models.Token
+	if err := r.db.Where("LOWER(address) = ?", key).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	r.put(&token)
+	return copyToken(&token), nil
+}
+
+// GetByID 根据 ID 获取代币
+// 未找到时返回 gorm.ErrRecordNotFound
+func (r *TokenRepository) GetByID(id uint) (*models.Token, error) {
+	r.mu.RLock()
+	cached, ok := r.byID[id]
+	r.mu.RUnlock()
+	if ok {
+		return copyToken(cached), nil
+	}
+
+	var token models.Token
+	if err := r.db.First(&token, id).Error; err != nil {
+		return nil, err
+	}
+
+	r.put(&token)
+	return copyToken(&token), nil
+}
+
+// GetBySymbol 根据符号获取代币（不区分大小写，优先返回启用的代币）
+// 未找到时返回 gorm.ErrRecordNotFound
+func (r *TokenRepository) GetBySymbol(symbol string) (*models.Token, error) {
+	var token models.Token
+	err := r.db.Where("UPPER(symbol) = ?", strings.ToUpper(symbol)).
+		Order("is_active DESC, id ASC").
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+
+	r.put(&token)
+	return copyToken(&token), nil
+}
+
+// ListActive 获取所有启用的代币
+// 每次都查询数据库，并用结果刷新缓存
+func (r *TokenRepository) ListActive() ([]models.Token, error) {
+	var tokens []models.Token
+	if err := r.db.Where("is_active = ?", true).Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("查询代币失败: %w", err)
+	}
+
+	for i := range tokens {
+		r.put(&tokens[i])
+	}
+
+	return tokens, nil
+}
+
+// Create 创建代币记录
+func (r *TokenRepository) Create(token *models.Token) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return err
+	}
+
+	r.put(token)
+	return nil
+}
+
+// UpdateHoneypot 写入代币的蜜罐检测结果（is_honeypot、honeypot_reason、honeypot_checked_at）并刷新缓存
+func (r *TokenRepository) UpdateHoneypot(ctx context.Context, token *models.Token) error {
+	err := r.db.WithContext(ctx).Model(&models.Token{}).Where("id = ?", token.ID).Updates(map[string]interface{}{
+		"is_honeypot":         token.IsHoneypot,
+		"honeypot_reason":     token.HoneypotReason,
+		"honeypot_checked_at": token.HoneypotCheckedAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	r.Invalidate(token.Address)
+	return nil
+}
+
+// Save 保存代币记录（更新所有字段）
+func (r *TokenRepository) Save(token *models.Token) error {
+	if err := r.db.Save(token).Error; err != nil {
+		return err
+	}
+
+	r.put(token)
+	return nil
+}
+
+// UpsertFromChain 从链上读取代币元数据并写入数据库
+// 已存在的代币只刷新精度/名称，不会覆盖配置中指定的符号
+func (r *TokenRepository) UpsertFromChain(client *web3.Client, address string, chainID int64) (*models.Token, error) {
+	metadata, err := client.GetTokenMetadata(address)
+	if err != nil {
+		return nil, fmt.Errorf("读取代币元数据失败: %w", err)
+	}
+
+	token, err := r.GetByAddress(metadata.Address)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("查询代币失败: %w", err)
+	}
+
+	if token == nil {
+		// 代币不存在，创建新记录
+		symbol := metadata.Symbol
+		if symbol == "" {
+			symbol = metadata.Address[:10]
+		}
+		name := metadata.Name
+		if name == "" {
+			name = symbol
+		}
+
+		token = &models.Token{
+			Address:  metadata.Address,
+			Symbol:   symbol,
+			Name:     name,
+			Decimals: metadata.Decimals,
+			ChainID:  chainID,
+			IsActive: true,
+		}
+		if err := r.Create(token); err != nil {
+			return nil, fmt.Errorf("创建代币 %s 失败: %w", symbol, err)
+		}
+		return copyToken(token), nil
+	}
+
+	// 代币已存在，刷新链上元数据
+	token.Decimals = metadata.Decimals
+	if metadata.Name != "" {
+		token.Name = metadata.Name
+	}
+	if err := r.Save(token); err != nil {
+		return nil, fmt.Errorf("更新代币 %s 失败: %w", token.Symbol, err)
+	}
+
+	return copyToken(token), nil
+}
+
+// Invalidate 使单个代币的缓存失效
+func (r *TokenRepository) Invalidate(address string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := addressKey(address)
+	if token, ok := r.byAddr[key]; ok {
+		delete(r.byID, token.ID)
+	}
+	delete(r.byAddr, key)
+}
+
+// InvalidateAll 清空所有缓存
+func (r *TokenRepository) InvalidateAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byAddr = make(map[string]*models.Token)
+	r.byID = make(map[uint]*models.Token)
+}
+
+// getCached 从缓存中按地址获取代币
+func (r *TokenRepository) getCached(key string) (*models.Token, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, ok := r.byAddr[key]
+	if !ok {
+		return nil, false
+	}
+	return copyToken(token), true
+}
+
+// put 写入缓存（存储副本，避免调用方修改缓存内容）
+func (r *TokenRepository) put(token *models.Token) {
+	cached := copyToken(token)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byAddr[addressKey(cached.Address)] = cached
+	r.byID[cached.ID] = cached
+}
+
+// addressKey 生成缓存键（地址不区分大小写）
+func addressKey(address string) string {
+	return strings.ToLower(address)
+}
+
+// copyToken 复制代币记录（不复制关联数据）
+func copyToken(token *models.Token) *models.Token {
+	copied := *token
+	copied.TradingPairs = nil
+	return &copied
+}
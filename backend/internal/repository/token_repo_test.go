@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/defi-bot/backend/internal/models"
+)
+
+func TestTokenGetByAddressCaseInsensitive(t *testing.T) {
+	db := openTestDB(t)
+
+	const checksum = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	token := &models.Token{Address: checksum, Symbol: "USDC", Decimals: 6, ChainID: 1}
+	if err := db.Create(token).Error; err != nil {
+		t.Fatalf("创建代币失败: %v", err)
+	}
+
+	for _, address := range []string{checksum, strings.ToLower(checksum), "0x" + strings.ToUpper(checksum[2:])} {
+		// 每次使用新的仓储，确保走数据库查询而不是缓存
+		got, err := NewTokenRepository(db).GetByAddress(address)
+		if err != nil {
+			t.Fatalf("GetByAddress(%s) 返回错误: %v", address, err)
+		}
+		if got.ID != token.ID {
+			t.Errorf("GetByAddress(%s) = 代币 %d, 期望 %d", address, got.ID, token.ID)
+		}
+	}
+}
+
+func TestTokenGetByAddressCached(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewTokenRepository(db)
+
+	token := createTestToken(t, db, "WETH", 18, 3000)
+	if _, err := repo.GetByAddress(token.Address); err != nil {
+		t.Fatalf("GetByAddress 返回错误: %v", err)
+	}
+
+	// 库中记录删除后，大小写不同的地址仍命中缓存
+	if err := db.Delete(&models.Token{}, token.ID).Error; err != nil {
+		t.Fatalf("删除代币失败: %v", err)
+	}
+	got, err := repo.GetByAddress(strings.ToUpper(token.Address))
+	if err != nil {
+		t.Fatalf("缓存未命中: %v", err)
+	}
+	if got.ID != token.ID {
+		t.Errorf("GetByAddress = 代币 %d, 期望 %d", got.ID, token.ID)
+	}
+}
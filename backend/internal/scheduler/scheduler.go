@@ -1,31 +1,63 @@
 package scheduler
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/defi-bot/backend/internal/analytics"
 	"github.com/defi-bot/backend/internal/collector"
 	"github.com/defi-bot/backend/internal/config"
+	"github.com/defi-bot/backend/internal/trading"
 	"github.com/robfig/cron/v3"
 )
 
+// stopTimeout Stop 等待进行中的任务退出的最长时间
+const stopTimeout = 30 * time.Second
+
 // Scheduler 定时任务调度器
+// 所有任务共用调度器的 ctx，Stop 时先取消 ctx，进行中的采集在下一个循环边界退出
 type Scheduler struct {
 	cron      *cron.Cron
 	collector *collector.Collector
+	analyzer  *analytics.PerformanceAnalyzer
 	config    *config.SchedulerConfig
+	trading   *trading.Control   // 交易开关（为空时始终允许）
+	lossGuard *trading.LossGuard // 亏损熔断器（为空时不检查）
+
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // NewScheduler 创建新的调度器
 func NewScheduler(collector *collector.Collector, cfg *config.SchedulerConfig) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Scheduler{
 		cron:      cron.New(cron.WithSeconds()),
 		collector: collector,
+		analyzer:  analytics.NewPerformanceAnalyzer(),
 		config:    cfg,
+		ctx:       ctx,
+		cancel:    cancel,
 	}
 }
 
+// SetTradingControl 设置交易开关，暂停时跳过套利分析（数据采集不受影响）
+func (s *Scheduler) SetTradingControl(control *trading.Control) {
+	s.trading = control
+}
+
+// SetNativeSymbol 设置策略表现统计使用的 Gas 计价代币（默认 WETH）
+func (s *Scheduler) SetNativeSymbol(symbol string) {
+	s.analyzer.SetNativeSymbol(symbol)
+}
+
+// SetLossGuard 设置亏损熔断器，每次分析前检查最近的执行结果
+func (s *Scheduler) SetLossGuard(guard *trading.LossGuard) {
+	s.lossGuard = guard
+}
+
 // Start 启动调度器
 func (s *Scheduler) Start() error {
 	log.Println("启动定时任务调度器...")
@@ -39,7 +71,7 @@ func (s *Scheduler) Start() error {
 	collectSpec := fmt.Sprintf("@every %ds", collectInterval)
 	_, err := s.cron.AddFunc(collectSpec, func() {
 		log.Println("执行定时任务: 采集价格数据")
-		if err := s.collector.CollectAllData(); err != nil {
+		if err := s.collector.CollectAllData(s.ctx); err != nil {
 			log.Printf("采集数据失败: %v", err)
 		}
 	})
@@ -56,6 +88,14 @@ func (s *Scheduler) Start() error {
 
 	analyzeSpec := fmt.Sprintf("@every %ds", analyzeInterval)
 	_, err = s.cron.AddFunc(analyzeSpec, func() {
+		if s.lossGuard != nil {
+			if _, err := s.lossGuard.Check(); err != nil {
+				log.Printf("⚠️  亏损熔断检查失败: %v", err)
+			}
+		}
+		if s.trading != nil && !s.trading.Enabled() {
+			return
+		}
 		log.Println("执行定时任务: 分析套利机会")
 		// TODO: 实现套利分析逻辑
 		// if err := s.analyzer.AnalyzeOpportunities(); err != nil {
@@ -71,7 +111,7 @@ func (s *Scheduler) Start() error {
 	gasSpec := "@every 30s"
 	_, err = s.cron.AddFunc(gasSpec, func() {
 		log.Println("执行定时任务: 采集 Gas 价格")
-		if err := s.collector.CollectGasData(); err != nil {
+		if err := s.collector.CollectGasData(s.ctx); err != nil {
 			log.Printf("采集 Gas 价格失败: %v", err)
 		}
 	})
@@ -90,7 +130,7 @@ func (s *Scheduler) Start() error {
 	_, err = s.cron.AddFunc(cleanupSpec, func() {
 		log.Println("执行定时任务: 清理过期数据")
 		// 保留最近 7 天的数据
-		if err := s.collector.CleanupOldData(7); err != nil {
+		if err := s.collector.CleanupOldData(s.ctx, 7); err != nil {
 			log.Printf("清理过期数据失败: %v", err)
 		}
 	})
@@ -99,6 +139,97 @@ func (s *Scheduler) Start() error {
 	}
 	log.Printf("已添加清理任务: 每 %d 小时执行一次", cleanupInterval)
 
+	// 5. 策略表现统计任务（预期利润 vs 实际利润）
+	performanceInterval := s.config.PerformanceInterval
+	if performanceInterval <= 0 {
+		performanceInterval = 60 // 默认 60 分钟
+	}
+	performanceWindow := s.config.PerformanceWindow
+	if performanceWindow <= 0 {
+		performanceWindow = 24 // 默认统计最近 24 小时
+	}
+
+	performanceSpec := fmt.Sprintf("@every %dm", performanceInterval)
+	_, err = s.cron.AddFunc(performanceSpec, func() {
+		log.Println("执行定时任务: 统计策略表现")
+		if _, err := s.analyzer.Compute(time.Duration(performanceWindow) * time.Hour); err != nil {
+			log.Printf("统计策略表现失败: %v", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("添加策略表现统计任务失败: %w", err)
+	}
+	log.Printf("已添加策略表现统计任务: 每 %d 分钟统计最近 %d 小时", performanceInterval, performanceWindow)
+
+	// 6. 成交量采集任务（扫描 Swap 事件，开销较大，默认每小时一次）
+	volumeInterval := s.config.VolumeInterval
+	if volumeInterval <= 0 {
+		volumeInterval = 60 // 默认 60 分钟
+	}
+
+	volumeSpec := fmt.Sprintf("@every %dm", volumeInterval)
+	_, err = s.cron.AddFunc(volumeSpec, func() {
+		log.Println("执行定时任务: 采集成交量")
+		if err := s.collector.CollectVolumes(s.ctx); err != nil {
+			log.Printf("采集成交量失败: %v", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("添加成交量采集任务失败: %w", err)
+	}
+	log.Printf("已添加成交量采集任务: 每 %d 分钟执行一次", volumeInterval)
+
+	// 7. V3 快速价格采集任务（只读 slot0 + liquidity，可比 collect_interval 更频繁）
+	if v3PriceInterval := s.config.V3PriceInterval; v3PriceInterval > 0 {
+		v3PriceSpec := fmt.Sprintf("@every %ds", v3PriceInterval)
+		_, err = s.cron.AddFunc(v3PriceSpec, func() {
+			log.Println("执行定时任务: 采集 V3 价格")
+			if err := s.collector.CollectV3Prices(s.ctx); err != nil {
+				log.Printf("采集 V3 价格失败: %v", err)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("添加 V3 价格采集任务失败: %w", err)
+		}
+		log.Printf("已添加 V3 价格采集任务: 每 %d 秒执行一次", v3PriceInterval)
+	}
+
+	// 8. V3 深度采集任务（QuoterV2 多金额探测，开销较大）
+	depthInterval := s.config.DepthInterval
+	if depthInterval <= 0 {
+		depthInterval = 300 // 默认 5 分钟
+	}
+
+	depthSpec := fmt.Sprintf("@every %ds", depthInterval)
+	_, err = s.cron.AddFunc(depthSpec, func() {
+		log.Println("执行定时任务: 采集 V3 深度")
+		if err := s.collector.CollectV3Depths(s.ctx); err != nil {
+			log.Printf("采集V3深度数据失败: %v", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("添加深度采集任务失败: %w", err)
+	}
+	log.Printf("已添加深度采集任务: 每 %d 秒执行一次", depthInterval)
+
+	// 9. 代币供应量 / 市值采集任务（供应量变化缓慢，默认每小时一次）
+	supplyInterval := s.config.SupplyInterval
+	if supplyInterval <= 0 {
+		supplyInterval = 60 // 默认 60 分钟
+	}
+
+	supplySpec := fmt.Sprintf("@every %dm", supplyInterval)
+	_, err = s.cron.AddFunc(supplySpec, func() {
+		log.Println("执行定时任务: 采集代币供应量")
+		if err := s.collector.CollectTokenSupply(s.ctx); err != nil {
+			log.Printf("采集代币供应量失败: %v", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("添加供应量采集任务失败: %w", err)
+	}
+	log.Printf("已添加供应量采集任务: 每 %d 分钟执行一次", supplyInterval)
+
 	// 启动 cron
 	s.cron.Start()
 	log.Println("定时任务调度器已启动")
@@ -106,16 +237,22 @@ func (s *Scheduler) Start() error {
 	return nil
 }
 
-// Stop 停止调度器
+// Context 调度器的 ctx（Stop 时取消），供启动时的一次性任务复用
+func (s *Scheduler) Context() context.Context {
+	return s.ctx
+}
+
+// Stop 停止调度器：取消进行中的任务并等待其退出（最多 stopTimeout）
 func (s *Scheduler) Stop() {
 	if s.cron != nil {
 		log.Println("停止定时任务调度器...")
+		s.cancel()
 		ctx := s.cron.Stop()
 		// 等待所有任务完成
 		select {
 		case <-ctx.Done():
 			log.Println("定时任务调度器已停止")
-		case <-time.After(30 * time.Second):
+		case <-time.After(stopTimeout):
 			log.Println("定时任务调度器停止超时")
 		}
 	}
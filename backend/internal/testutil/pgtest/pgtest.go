@@ -0,0 +1,82 @@
+// Package pgtest 为需要 PostgreSQL 的测试提供一次性 schema
+// 连接串来自 TEST_DATABASE_DSN（CI 中由 postgres 服务容器提供），未设置时跳过测试
+package pgtest
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DSNEnv 测试用 PostgreSQL 连接串的环境变量
+const DSNEnv = "TEST_DATABASE_DSN"
+
+// Open 创建一次性 schema 并返回 search_path 指向它的连接，测试结束后删除该 schema
+// 未设置 TEST_DATABASE_DSN 时跳过当前测试
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		t.Skipf("未设置 %s，跳过需要数据库的测试", DSNEnv)
+	}
+
+	gormConfig := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	admin, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	if err != nil {
+		t.Fatalf("连接测试数据库失败: %v", err)
+	}
+
+	schema := fmt.Sprintf("pgtest_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("创建临时 schema 失败: %v", err)
+	}
+
+	scopedDSN, err := withSearchPath(dsn, schema)
+	if err != nil {
+		t.Fatalf("解析 %s 失败: %v", DSNEnv, err)
+	}
+
+	scoped, err := gorm.Open(postgres.Open(scopedDSN), gormConfig)
+	if err != nil {
+		t.Fatalf("连接临时 schema 失败: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := scoped.DB(); err == nil {
+			sqlDB.Close()
+		}
+		if err := admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Logf("删除临时 schema %s 失败: %v", schema, err)
+		}
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return scoped
+}
+
+// withSearchPath 给连接串加上 search_path 参数（支持 URL 和 key=value 两种格式）
+func withSearchPath(dsn, schema string) (string, error) {
+	if !strings.Contains(dsn, "://") {
+		return dsn + " search_path=" + schema, nil
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
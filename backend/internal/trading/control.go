@@ -0,0 +1,118 @@
+package trading
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/defi-bot/backend/internal/repository"
+)
+
+// 交易开关在 settings 表中的键
+const (
+	settingKey       = "trading_enabled"
+	reasonSettingKey = "trading_pause_reason"
+)
+
+// Control 交易开关
+// 行情异常时可在运行时暂停套利分析/执行，数据采集不受影响；状态持久化到 settings 表，重启后保持
+type Control struct {
+	enabled atomic.Bool
+	repo    *repository.SettingRepository
+
+	mu        sync.Mutex // 串行化状态变更与持久化
+	updatedAt time.Time
+	reason    string // 暂停原因
+}
+
+// State 交易开关状态
+type State struct {
+	TradingEnabled bool      `json:"trading_enabled"`
+	Reason         string    `json:"reason,omitempty"` // 暂停原因（手动暂停 / 亏损熔断）
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// NewControl 创建交易开关并加载上次保存的状态（无记录时默认启用）
+func NewControl(repo *repository.SettingRepository) (*Control, error) {
+	c := &Control{repo: repo}
+	c.enabled.Store(true)
+
+	setting, ok, err := repo.Get(settingKey)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		enabled, err := strconv.ParseBool(setting.Value)
+		if err != nil {
+			return nil, fmt.Errorf("解析交易开关状态失败: %w", err)
+		}
+		c.enabled.Store(enabled)
+		c.updatedAt = setting.UpdatedAt
+	}
+
+	reason, ok, err := repo.Get(reasonSettingKey)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		c.reason = reason.Value
+	}
+
+	if !c.Enabled() {
+		log.Printf("⚠️  交易处于暂停状态（上次暂停于 %s，原因: %s）", c.updatedAt.Format("2006-01-02 15:04:05"), c.reason)
+	}
+
+	return c, nil
+}
+
+// Enabled 是否允许交易
+func (c *Control) Enabled() bool {
+	return c.enabled.Load()
+}
+
+// SetEnabled 修改交易开关并持久化，reason 为暂停原因（恢复时忽略）
+func (c *Control) SetEnabled(enabled bool, reason string) (State, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if enabled {
+		reason = ""
+	}
+	if err := c.repo.Set(reasonSettingKey, reason); err != nil {
+		return c.state(), err
+	}
+	if err := c.repo.Set(settingKey, strconv.FormatBool(enabled)); err != nil {
+		return c.state(), err
+	}
+
+	c.enabled.Store(enabled)
+	c.updatedAt = time.Now()
+	c.reason = reason
+
+	if enabled {
+		log.Println("✅ 交易已恢复")
+	} else {
+		log.Printf("⚠️  交易已暂停（数据采集继续运行），原因: %s", reason)
+	}
+
+	return c.state(), nil
+}
+
+// State 获取当前状态
+func (c *Control) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state()
+}
+
+// state 获取当前状态（调用方需持有锁）
+func (c *Control) state() State {
+	return State{
+		TradingEnabled: c.enabled.Load(),
+		Reason:         c.reason,
+		UpdatedAt:      c.updatedAt,
+	}
+}
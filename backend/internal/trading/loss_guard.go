@@ -0,0 +1,166 @@
+package trading
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/defi-bot/backend/internal/database"
+	"github.com/defi-bot/backend/internal/models"
+)
+
+// LossGuardConfig 亏损熔断配置
+type LossGuardConfig struct {
+	MaxConsecutiveLosses int           // 连续亏损次数上限（0 表示不检查）
+	MaxDrawdownUSD       float64       // 窗口内累计亏损上限（美元，0 表示不检查）
+	Window               time.Duration // 累计亏损统计窗口
+	NativeSymbol         string        // Gas 计价代币的符号（用于把 Gas 成本换算为美元），如 WETH
+	AlertWebhook         string        // 熔断告警 Webhook（POST {"text": ...}，为空只记录日志）
+}
+
+// LossGuard 亏损熔断器
+// 根据已完成的执行记录计算含 Gas 的实际盈亏，连续亏损或累计亏损超限时自动暂停交易，
+// 需要通过 POST /admin/resume 手动恢复
+type LossGuard struct {
+	control       *Control
+	config        LossGuardConfig
+	nativeTokenID uint // Gas 计价代币 ID（创建时解析）
+	client        *http.Client
+}
+
+// NewLossGuard 创建亏损熔断器
+// 启用了熔断阈值时在创建时解析 Gas 计价代币，代币不存在则返回错误：
+// 否则每次检查都会失败，熔断器永远不会触发
+func NewLossGuard(control *Control, cfg LossGuardConfig) (*LossGuard, error) {
+	if cfg.Window <= 0 {
+		cfg.Window = 24 * time.Hour
+	}
+	if cfg.NativeSymbol == "" {
+		cfg.NativeSymbol = "WETH"
+	}
+
+	guard := &LossGuard{
+		control: control,
+		config:  cfg,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+	if !guard.Enabled() {
+		return guard, nil
+	}
+
+	var native models.Token
+	if err := database.GetDB().Where("symbol = ?", cfg.NativeSymbol).First(&native).Error; err != nil {
+		return nil, fmt.Errorf("查询 Gas 计价代币 %s 失败（检查 risk.native_symbol）: %w", cfg.NativeSymbol, err)
+	}
+	guard.nativeTokenID = native.ID
+
+	return guard, nil
+}
+
+// Enabled 是否配置了熔断阈值
+func (g *LossGuard) Enabled() bool {
+	return g.config.MaxConsecutiveLosses > 0 || g.config.MaxDrawdownUSD > 0
+}
+
+// Check 检查最近的执行结果，触发熔断时暂停交易并返回 true
+// 只统计上次恢复交易之后的执行记录，避免手动恢复后立即被同一批亏损再次触发
+func (g *LossGuard) Check() (bool, error) {
+	if !g.Enabled() || !g.control.Enabled() {
+		return false, nil
+	}
+
+	since := time.Now().Add(-g.config.Window)
+	if resumedAt := g.control.State().UpdatedAt; resumedAt.After(since) {
+		since = resumedAt
+	}
+
+	db := database.GetDB()
+
+	var executions []models.ArbitrageExecution
+	err := db.Preload("TokenIn").
+		Where("timestamp >= ? AND status IN ?", since, []string{"success", "failed"}).
+		Order("timestamp DESC").
+		Find(&executions).Error
+	if err != nil {
+		return false, fmt.Errorf("查询执行记录失败: %w", err)
+	}
+	if len(executions) == 0 {
+		return false, nil
+	}
+
+	// 价格随采集更新，每次检查按 ID 读取最新价格
+	var native models.Token
+	if err := db.First(&native, g.nativeTokenID).Error; err != nil {
+		return false, fmt.Errorf("查询 Gas 计价代币 %s 失败: %w", g.config.NativeSymbol, err)
+	}
+
+	consecutiveLosses := 0
+	countingStreak := true
+	netUSD := 0.0
+	unpriced := 0
+
+	for i := range executions {
+		pnl, ok := executions[i].NetProfitUSDAt(native.PriceUSD)
+		if !ok {
+			unpriced++ // 缺少价格无法折算美元，不计入也不打断连续亏损
+			continue
+		}
+		netUSD += pnl
+
+		if countingStreak {
+			if pnl < 0 {
+				consecutiveLosses++
+			} else {
+				countingStreak = false
+			}
+		}
+	}
+
+	if unpriced > 0 {
+		log.Printf("⚠️  亏损熔断: %d 条执行记录缺少价格（%s 或输入代币），未计入统计", unpriced, g.config.NativeSymbol)
+	}
+
+	reason := ""
+	switch {
+	case g.config.MaxConsecutiveLosses > 0 && consecutiveLosses >= g.config.MaxConsecutiveLosses:
+		reason = fmt.Sprintf("亏损熔断: 连续 %d 次执行亏损", consecutiveLosses)
+	case g.config.MaxDrawdownUSD > 0 && -netUSD > g.config.MaxDrawdownUSD:
+		reason = fmt.Sprintf("亏损熔断: 累计亏损 $%.2f 超过上限 $%.2f", -netUSD, g.config.MaxDrawdownUSD)
+	default:
+		return false, nil
+	}
+
+	if _, err := g.control.SetEnabled(false, reason); err != nil {
+		return false, fmt.Errorf("暂停交易失败: %w", err)
+	}
+
+	log.Printf("❌ %s，交易已暂停，需手动恢复（POST /admin/resume）", reason)
+	g.alert(reason)
+	return true, nil
+}
+
+// alert 发送熔断告警
+func (g *LossGuard) alert(message string) {
+	if g.config.AlertWebhook == "" {
+		return
+	}
+
+	body, err := json.Marshal(map[string]string{"text": message})
+	if err != nil {
+		return
+	}
+
+	resp, err := g.client.Post(g.config.AlertWebhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("⚠️  发送熔断告警失败: %v", err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Printf("⚠️  发送熔断告警失败: 状态码 %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,82 @@
+package amm
+
+import (
+	"errors"
+	"math/big"
+)
+
+// PriceMoveDenominator 价格变动容忍度的精度（基点），如 50 表示 0.5%
+const PriceMoveDenominator = 10000
+
+// ErrInvalidPriceMove 价格变动容忍度无效（为 0，或向下变动不小于 100%）
+var ErrInvalidPriceMove = errors.New("amm: 价格变动容忍度无效")
+
+// UsableDepth 在价格变动容忍度内可以成交的数量
+type UsableDepth struct {
+	AmountIn          *big.Int // 最大输入（含手续费）
+	AmountOut         *big.Int // 对应的输出
+	SqrtPriceLimitX96 *big.Int // 容忍度对应的价格边界（可作为 sqrtPriceLimitX96 传给合约）
+}
+
+// EstimateUsableDepth 估算 V3 池子在价格变动不超过 maxPriceMoveBps 时可以成交的数量
+// 只使用当前活跃流动性 liquidity（slot0 所在 tick 区间），假设价格移动范围内不跨越已初始化的 tick；
+// 容忍度较小（通常在一个 tick 区间内）时结果与 Quoter 一致，跨 tick 时为近似值。
+// 与按全区间折算的虚拟储备量相比，不会把集中在窄区间内的流动性当作全价格范围可用的深度。
+// zeroForOne 为 true 时输入 token0（价格 token1/token0 下降），feePips 为池子费率（精度 1e6）
+func EstimateUsableDepth(sqrtPriceX96, liquidity *big.Int, zeroForOne bool, maxPriceMoveBps, feePips uint32) (*UsableDepth, error) {
+	if sqrtPriceX96.Sign() <= 0 || liquidity.Sign() <= 0 {
+		return nil, ErrInsufficientLiquidity
+	}
+	if maxPriceMoveBps == 0 || (zeroForOne && maxPriceMoveBps >= PriceMoveDenominator) {
+		return nil, ErrInvalidPriceMove
+	}
+	if feePips >= FeePipsDenominator {
+		return nil, ErrInvalidFee
+	}
+
+	// 价格 = sqrtPrice²，目标 sqrtPrice = sqrt(sqrtPrice² × (1 ± move))
+	// 向下移动时向上取整、向上移动时向下取整，保证不超出容忍度
+	factor := big.NewInt(PriceMoveDenominator)
+	if zeroForOne {
+		factor.Sub(factor, big.NewInt(int64(maxPriceMoveBps)))
+	} else {
+		factor.Add(factor, big.NewInt(int64(maxPriceMoveBps)))
+	}
+	squared := new(big.Int).Mul(sqrtPriceX96, sqrtPriceX96)
+	squared.Mul(squared, factor)
+
+	var target *big.Int
+	if zeroForOne {
+		target = sqrtRoundingUp(divRoundingUp(squared, big.NewInt(PriceMoveDenominator)))
+	} else {
+		target = new(big.Int).Sqrt(squared.Quo(squared, big.NewInt(PriceMoveDenominator)))
+	}
+
+	var amountIn, amountOut *big.Int
+	if zeroForOne {
+		amountIn = GetAmount0Delta(target, sqrtPriceX96, liquidity, true)
+		amountOut = GetAmount1Delta(target, sqrtPriceX96, liquidity, false)
+	} else {
+		amountIn = GetAmount1Delta(sqrtPriceX96, target, liquidity, true)
+		amountOut = GetAmount0Delta(sqrtPriceX96, target, liquidity, false)
+	}
+
+	// 合约先扣手续费再交换：到达边界所需的总输入 = amountIn × 1e6 / (1e6 - fee)
+	feeComplement := big.NewInt(int64(FeePipsDenominator - feePips))
+	amountIn = mulDivRoundingUp(amountIn, feePipsDenominator, feeComplement)
+
+	return &UsableDepth{
+		AmountIn:          amountIn,
+		AmountOut:         amountOut,
+		SqrtPriceLimitX96: target,
+	}, nil
+}
+
+// sqrtRoundingUp 整数平方根，向上取整
+func sqrtRoundingUp(x *big.Int) *big.Int {
+	root := new(big.Int).Sqrt(x)
+	if new(big.Int).Mul(root, root).Cmp(x) < 0 {
+		root.Add(root, big.NewInt(1))
+	}
+	return root
+}
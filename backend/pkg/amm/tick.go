@@ -0,0 +1,115 @@
+package amm
+
+import (
+	"errors"
+	"math"
+	"math/big"
+)
+
+// V3 tick 的取值范围（TickMath.MIN_TICK / MAX_TICK）
+const (
+	MinTick = -887272
+	MaxTick = 887272
+)
+
+var (
+	// ErrTickOutOfRange tick 超出 [MinTick, MaxTick]
+	ErrTickOutOfRange = errors.New("amm: tick 超出范围")
+
+	// ErrInvalidPeriod TWAP 时间窗口为 0
+	ErrInvalidPeriod = errors.New("amm: TWAP 时间窗口无效")
+)
+
+// tickRatios TickMath.getSqrtRatioAtTick 中按 |tick| 各二进制位预先计算的 1/sqrt(1.0001^(2^i))（Q128）
+var tickRatios = []*big.Int{
+	hexBig("fffcb933bd6fad37aa2d162d1a594001"),
+	hexBig("fff97272373d413259a46990580e213a"),
+	hexBig("fff2e50f5f656932ef12357cf3c7fdcc"),
+	hexBig("ffe5caca7e10e4e61c3624eaa0941cd0"),
+	hexBig("ffcb9843d60f6159c9db58835c926644"),
+	hexBig("ff973b41fa98c081472e6896dfb254c0"),
+	hexBig("ff2ea16466c96a3843ec78b326b52861"),
+	hexBig("fe5dee046a99a2a811c461f1969c3053"),
+	hexBig("fcbe86c7900a88aedcffc83b479aa3a4"),
+	hexBig("f987a7253ac413176f2b074cf7815e54"),
+	hexBig("f3392b0822b70005940c7a398e4b70f3"),
+	hexBig("e7159475a2c29b7443b29c7fa6e889d9"),
+	hexBig("d097f3bdfd2022b8845ad8f792aa5825"),
+	hexBig("a9f746462d870fdf8a65dc1f90e061e5"),
+	hexBig("70d869a156d2a1b890bb3df62baf32f7"),
+	hexBig("31be135f97d08fd981231505542fcfa6"),
+	hexBig("9aa508b5b7a84e1c677de54f3e99bc9"),
+	hexBig("5d6af8dedb81196699c329225ee604"),
+	hexBig("2216e584f5fa1ea926041bedfe98"),
+	hexBig("48a170391f7dc42444e8fa2"),
+}
+
+// GetSqrtRatioAtTick 计算 tick 对应的 sqrtPriceX96 = sqrt(1.0001^tick) × 2^96（TickMath.getSqrtRatioAtTick）
+// 与合约逐位一致，结果可直接与链上 slot0.sqrtPriceX96 比较
+func GetSqrtRatioAtTick(tick int32) (*big.Int, error) {
+	if tick < MinTick || tick > MaxTick {
+		return nil, ErrTickOutOfRange
+	}
+
+	absTick := tick
+	if absTick < 0 {
+		absTick = -absTick
+	}
+
+	ratio := new(big.Int).Lsh(big.NewInt(1), 128)
+	for i, factor := range tickRatios {
+		if absTick&(1<<i) != 0 {
+			ratio.Mul(ratio, factor)
+			ratio.Rsh(ratio, 128)
+		}
+	}
+
+	if tick > 0 {
+		ratio.Quo(maxUint256, ratio)
+	}
+
+	// Q128.128 → Q64.96，向上取整
+	return divRoundingUp(ratio, new(big.Int).Lsh(big.NewInt(1), 32)), nil
+}
+
+// MeanTick 由两次 observe 的 tickCumulative 计算区间内的时间加权平均 tick（OracleLibrary.consult）
+// 结果向负无穷取整，与合约一致
+func MeanTick(tickCumulativeStart, tickCumulativeEnd *big.Int, seconds uint32) (int32, error) {
+	if seconds == 0 {
+		return 0, ErrInvalidPeriod
+	}
+
+	delta := new(big.Int).Sub(tickCumulativeEnd, tickCumulativeStart)
+	period := big.NewInt(int64(seconds))
+
+	mean, remainder := new(big.Int).QuoRem(delta, period, new(big.Int))
+	if delta.Sign() < 0 && remainder.Sign() != 0 {
+		mean.Sub(mean, big.NewInt(1))
+	}
+
+	if !mean.IsInt64() || mean.Int64() < MinTick || mean.Int64() > MaxTick {
+		return 0, ErrTickOutOfRange
+	}
+	return int32(mean.Int64()), nil
+}
+
+// SqrtPriceDivergence 两个 sqrtPriceX96 对应价格的相对偏离 |p1/p2 - 1|（如现货价与 TWAP 的偏离）
+func SqrtPriceDivergence(sqrtPriceX96, referenceSqrtPriceX96 *big.Int) float64 {
+	if referenceSqrtPriceX96.Sign() <= 0 {
+		return math.Inf(1)
+	}
+
+	ratio := new(big.Rat).SetFrac(sqrtPriceX96, referenceSqrtPriceX96)
+	ratio.Mul(ratio, ratio)
+	value, _ := ratio.Float64()
+	return math.Abs(value - 1)
+}
+
+// hexBig 解析十六进制常量
+func hexBig(s string) *big.Int {
+	value, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("amm: 无效的十六进制常量 " + s)
+	}
+	return value
+}
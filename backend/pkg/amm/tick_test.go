@@ -0,0 +1,95 @@
+package amm
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestGetSqrtRatioAtTick(t *testing.T) {
+	tests := []struct {
+		name string
+		tick int32
+		want string
+	}{
+		// TickMath.MIN_SQRT_RATIO / MAX_SQRT_RATIO
+		{name: "最小 tick", tick: MinTick, want: "4295128739"},
+		{name: "最大 tick", tick: MaxTick, want: "1461446703485210103287273052203988822378723970342"},
+		{name: "tick 0 为 2^96", tick: 0, want: "79228162514264337593543950336"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetSqrtRatioAtTick(tt.tick)
+			if err != nil {
+				t.Fatalf("GetSqrtRatioAtTick(%d) 返回错误: %v", tt.tick, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("GetSqrtRatioAtTick(%d) = %s, 期望 %s", tt.tick, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSqrtRatioAtTickOutOfRange(t *testing.T) {
+	for _, tick := range []int32{MinTick - 1, MaxTick + 1} {
+		if _, err := GetSqrtRatioAtTick(tick); !errors.Is(err, ErrTickOutOfRange) {
+			t.Errorf("GetSqrtRatioAtTick(%d) 错误 = %v, 期望 ErrTickOutOfRange", tick, err)
+		}
+	}
+}
+
+func TestGetSqrtRatioAtTickPrice(t *testing.T) {
+	// 相邻 tick 的价格之比为 1.0001，且随 tick 严格递增
+	for _, tick := range []int32{-200000, -1, 0, 1, 200000} {
+		got, err := GetSqrtRatioAtTick(tick)
+		if err != nil {
+			t.Fatalf("GetSqrtRatioAtTick(%d) 返回错误: %v", tick, err)
+		}
+		price, _ := SqrtPriceX96ToPrice(got).Float64()
+		want := math.Pow(1.0001, float64(tick))
+		if math.Abs(price/want-1) > 1e-9 {
+			t.Errorf("tick %d 的价格 = %g, 期望 %g", tick, price, want)
+		}
+
+		next, _ := GetSqrtRatioAtTick(tick + 1)
+		if next.Cmp(got) <= 0 {
+			t.Errorf("GetSqrtRatioAtTick(%d) = %s 不大于 tick %d 的 %s", tick+1, next, tick, got)
+		}
+	}
+}
+
+func TestMeanTick(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   int64
+		end     int64
+		seconds uint32
+		want    int32
+		wantErr error
+	}{
+		{name: "整除", start: 0, end: 600, seconds: 60, want: 10},
+		{name: "正数向下取整", start: 0, end: 601, seconds: 60, want: 10},
+		{name: "负数整除", start: 0, end: -600, seconds: 60, want: -10},
+		{name: "负数向负无穷取整", start: 0, end: -601, seconds: 60, want: -11},
+		{name: "起点为负", start: -1000, end: -400, seconds: 60, want: 10},
+		{name: "最大 tick", start: 0, end: MaxTick * 60, seconds: 60, want: MaxTick},
+		{name: "最小 tick", start: 0, end: MinTick * 60, seconds: 60, want: MinTick},
+		{name: "超出最大 tick", start: 0, end: (MaxTick + 1) * 60, seconds: 60, wantErr: ErrTickOutOfRange},
+		{name: "超出最小 tick", start: 0, end: MinTick*60 - 1, seconds: 60, wantErr: ErrTickOutOfRange},
+		{name: "时间窗口为 0", start: 0, end: 600, seconds: 0, wantErr: ErrInvalidPeriod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MeanTick(big.NewInt(tt.start), big.NewInt(tt.end), tt.seconds)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MeanTick 错误 = %v, 期望 %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("MeanTick = %d, 期望 %d", got, tt.want)
+			}
+		})
+	}
+}
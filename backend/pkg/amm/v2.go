@@ -0,0 +1,101 @@
+// Package amm 提供 AMM 定价公式的纯函数实现
+// 全部使用 big.Int 整数运算，舍入方式与 Uniswap 合约（UniswapV2Library / SqrtPriceMath / SwapMath）一致，
+// 链下计算的结果可以和链上逐 wei 对齐
+package amm
+
+import (
+	"errors"
+	"math/big"
+)
+
+// FeeDenominator V2 手续费的精度（基点），如 fee=30 表示 0.3%
+const FeeDenominator = 10000
+
+var (
+	ErrInsufficientInputAmount  = errors.New("amm: 输入数量不足")
+	ErrInsufficientOutputAmount = errors.New("amm: 输出数量不足")
+	ErrInsufficientLiquidity    = errors.New("amm: 流动性不足")
+	ErrInsufficientAmount       = errors.New("amm: 数量不足")
+	ErrInvalidFee               = errors.New("amm: 手续费无效")
+)
+
+var feeDenominator = big.NewInt(FeeDenominator)
+
+// GetAmountOut 恒定乘积池的输出数量（UniswapV2Library.getAmountOut）
+// amountOut = amountIn × (10000 - fee) × reserveOut / (reserveIn × 10000 + amountIn × (10000 - fee))，向下取整
+// fee=30 时与合约中的 997/1000 结果完全相同
+func GetAmountOut(amountIn, reserveIn, reserveOut *big.Int, feeBps uint32) (*big.Int, error) {
+	if amountIn.Sign() <= 0 {
+		return nil, ErrInsufficientInputAmount
+	}
+	if reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 {
+		return nil, ErrInsufficientLiquidity
+	}
+	if feeBps >= FeeDenominator {
+		return nil, ErrInvalidFee
+	}
+
+	amountInWithFee := new(big.Int).Mul(amountIn, big.NewInt(int64(FeeDenominator-feeBps)))
+	numerator := new(big.Int).Mul(amountInWithFee, reserveOut)
+	denominator := new(big.Int).Mul(reserveIn, feeDenominator)
+	denominator.Add(denominator, amountInWithFee)
+
+	return numerator.Quo(numerator, denominator), nil
+}
+
+// GetAmountIn 获得指定输出所需的最少输入数量（UniswapV2Library.getAmountIn）
+// amountIn = reserveIn × amountOut × 10000 / ((reserveOut - amountOut) × (10000 - fee)) + 1
+// 合约在整除后固定加 1，保证输入足够
+func GetAmountIn(amountOut, reserveIn, reserveOut *big.Int, feeBps uint32) (*big.Int, error) {
+	if amountOut.Sign() <= 0 {
+		return nil, ErrInsufficientOutputAmount
+	}
+	if reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 || amountOut.Cmp(reserveOut) >= 0 {
+		return nil, ErrInsufficientLiquidity
+	}
+	if feeBps >= FeeDenominator {
+		return nil, ErrInvalidFee
+	}
+
+	numerator := new(big.Int).Mul(reserveIn, amountOut)
+	numerator.Mul(numerator, feeDenominator)
+	denominator := new(big.Int).Sub(reserveOut, amountOut)
+	denominator.Mul(denominator, big.NewInt(int64(FeeDenominator-feeBps)))
+
+	amountIn := numerator.Quo(numerator, denominator)
+	return amountIn.Add(amountIn, big.NewInt(1)), nil
+}
+
+// Quote 按储备量比例换算等值数量，不含手续费和价格影响（UniswapV2Library.quote）
+// amountB = amountA × reserveB / reserveA，向下取整
+func Quote(amountA, reserveA, reserveB *big.Int) (*big.Int, error) {
+	if amountA.Sign() <= 0 {
+		return nil, ErrInsufficientAmount
+	}
+	if reserveA.Sign() <= 0 || reserveB.Sign() <= 0 {
+		return nil, ErrInsufficientLiquidity
+	}
+
+	amountB := new(big.Int).Mul(amountA, reserveB)
+	return amountB.Quo(amountB, reserveA), nil
+}
+
+// GetAmountsOut 沿多跳路径逐跳计算输出数量（UniswapV2Library.getAmountsOut）
+// reserves[i] 为第 i 跳的 [reserveIn, reserveOut]，fees[i] 为第 i 跳的手续费（基点）
+// 返回值 amounts[0] 为输入，amounts[i+1] 为第 i 跳的输出
+func GetAmountsOut(amountIn *big.Int, reserves [][2]*big.Int, fees []uint32) ([]*big.Int, error) {
+	if len(reserves) != len(fees) {
+		return nil, ErrInvalidFee
+	}
+
+	amounts := make([]*big.Int, len(reserves)+1)
+	amounts[0] = new(big.Int).Set(amountIn)
+	for i, reserve := range reserves {
+		out, err := GetAmountOut(amounts[i], reserve[0], reserve[1], fees[i])
+		if err != nil {
+			return nil, err
+		}
+		amounts[i+1] = out
+	}
+	return amounts, nil
+}
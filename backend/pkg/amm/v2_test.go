@@ -0,0 +1,199 @@
+package amm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestGetAmountOut(t *testing.T) {
+	pow2 := func(n uint) *big.Int { return new(big.Int).Lsh(big.NewInt(1), n) }
+
+	tests := []struct {
+		name       string
+		amountIn   *big.Int
+		reserveIn  *big.Int
+		reserveOut *big.Int
+		fee        uint32
+		want       *big.Int
+		wantErr    error
+	}{
+		{
+			// 9970000 × 1000 / 19970000 = 499.24… → 499
+			name: "0.3% 手续费向下取整", amountIn: big.NewInt(1000), reserveIn: big.NewInt(1000),
+			reserveOut: big.NewInt(1000), fee: 30, want: big.NewInt(499),
+		},
+		{
+			name: "与 997/1000 一致", amountIn: big.NewInt(1e18), reserveIn: big.NewInt(5e18),
+			reserveOut: big.NewInt(1e10), fee: 30,
+			want: func() *big.Int {
+				in := new(big.Int).Mul(big.NewInt(1e18), big.NewInt(997))
+				num := new(big.Int).Mul(in, big.NewInt(1e10))
+				den := new(big.Int).Mul(big.NewInt(5e18), big.NewInt(1000))
+				return num.Quo(num, den.Add(den, in))
+			}(),
+		},
+		{
+			// 9970 × 1000 / 10009970 < 1
+			name: "输出不足 1 wei 时为 0", amountIn: big.NewInt(1), reserveIn: big.NewInt(1000),
+			reserveOut: big.NewInt(1000), fee: 30, want: big.NewInt(0),
+		},
+		{
+			// 中间乘积超过 2^256，big.Int 不会溢出：2^255 × 2^255 / 2^256 = 2^254
+			name: "256 位储备量", amountIn: pow2(255), reserveIn: pow2(255),
+			reserveOut: pow2(255), fee: 0, want: pow2(254),
+		},
+		{
+			name: "输入为 0", amountIn: big.NewInt(0), reserveIn: big.NewInt(1000),
+			reserveOut: big.NewInt(1000), fee: 30, wantErr: ErrInsufficientInputAmount,
+		},
+		{
+			name: "储备量为 0", amountIn: big.NewInt(1), reserveIn: big.NewInt(0),
+			reserveOut: big.NewInt(1000), fee: 30, wantErr: ErrInsufficientLiquidity,
+		},
+		{
+			name: "手续费 100%", amountIn: big.NewInt(1), reserveIn: big.NewInt(1000),
+			reserveOut: big.NewInt(1000), fee: FeeDenominator, wantErr: ErrInvalidFee,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetAmountOut(tt.amountIn, tt.reserveIn, tt.reserveOut, tt.fee)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetAmountOut 错误 = %v, 期望 %v", err, tt.wantErr)
+			}
+			if err == nil && got.Cmp(tt.want) != 0 {
+				t.Errorf("GetAmountOut = %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAmountIn(t *testing.T) {
+	pow2 := func(n uint) *big.Int { return new(big.Int).Lsh(big.NewInt(1), n) }
+
+	tests := []struct {
+		name       string
+		amountOut  *big.Int
+		reserveIn  *big.Int
+		reserveOut *big.Int
+		fee        uint32
+		want       *big.Int
+		wantErr    error
+	}{
+		{
+			// 4990000000 / 4994970 = 999.0… → 999 + 1
+			name: "0.3% 手续费", amountOut: big.NewInt(499), reserveIn: big.NewInt(1000),
+			reserveOut: big.NewInt(1000), fee: 30, want: big.NewInt(1000),
+		},
+		{
+			// 1000 × 500 / 500 恰好整除，合约仍固定加 1
+			name: "整除时仍加 1", amountOut: big.NewInt(500), reserveIn: big.NewInt(1000),
+			reserveOut: big.NewInt(1000), fee: 0, want: big.NewInt(1001),
+		},
+		{
+			// 2^255 × 2^254 / 2^254 + 1
+			name: "256 位储备量", amountOut: pow2(254), reserveIn: pow2(255),
+			reserveOut: pow2(255), fee: 0, want: new(big.Int).Add(pow2(255), big.NewInt(1)),
+		},
+		{
+			name: "输出等于储备量", amountOut: big.NewInt(1000), reserveIn: big.NewInt(1000),
+			reserveOut: big.NewInt(1000), fee: 30, wantErr: ErrInsufficientLiquidity,
+		},
+		{
+			name: "输出为 0", amountOut: big.NewInt(0), reserveIn: big.NewInt(1000),
+			reserveOut: big.NewInt(1000), fee: 30, wantErr: ErrInsufficientOutputAmount,
+		},
+		{
+			name: "手续费 100%", amountOut: big.NewInt(1), reserveIn: big.NewInt(1000),
+			reserveOut: big.NewInt(1000), fee: FeeDenominator, wantErr: ErrInvalidFee,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetAmountIn(tt.amountOut, tt.reserveIn, tt.reserveOut, tt.fee)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetAmountIn 错误 = %v, 期望 %v", err, tt.wantErr)
+			}
+			if err == nil && got.Cmp(tt.want) != 0 {
+				t.Errorf("GetAmountIn = %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAmountInCoversAmountOut(t *testing.T) {
+	// GetAmountIn 向上取整：按它给出的输入换出的数量不少于目标输出
+	reserveIn, reserveOut := big.NewInt(123456789), big.NewInt(987654321)
+	for _, out := range []int64{1, 7, 1000, 999999, 500000000} {
+		amountIn, err := GetAmountIn(big.NewInt(out), reserveIn, reserveOut, 30)
+		if err != nil {
+			t.Fatalf("GetAmountIn(%d) 返回错误: %v", out, err)
+		}
+		got, err := GetAmountOut(amountIn, reserveIn, reserveOut, 30)
+		if err != nil {
+			t.Fatalf("GetAmountOut(%s) 返回错误: %v", amountIn, err)
+		}
+		if got.Cmp(big.NewInt(out)) < 0 {
+			t.Errorf("输入 %s 只换出 %s, 少于目标 %d", amountIn, got, out)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		name     string
+		amountA  *big.Int
+		reserveA *big.Int
+		reserveB *big.Int
+		want     *big.Int
+		wantErr  error
+	}{
+		{name: "等比例", amountA: big.NewInt(10), reserveA: big.NewInt(100), reserveB: big.NewInt(200), want: big.NewInt(20)},
+		{name: "向下取整", amountA: big.NewInt(3), reserveA: big.NewInt(4), reserveB: big.NewInt(10), want: big.NewInt(7)},
+		{name: "不足 1 时为 0", amountA: big.NewInt(1), reserveA: big.NewInt(1000), reserveB: big.NewInt(999), want: big.NewInt(0)},
+		{name: "数量为 0", amountA: big.NewInt(0), reserveA: big.NewInt(1), reserveB: big.NewInt(1), wantErr: ErrInsufficientAmount},
+		{name: "储备量为 0", amountA: big.NewInt(1), reserveA: big.NewInt(1), reserveB: big.NewInt(0), wantErr: ErrInsufficientLiquidity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Quote(tt.amountA, tt.reserveA, tt.reserveB)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Quote 错误 = %v, 期望 %v", err, tt.wantErr)
+			}
+			if err == nil && got.Cmp(tt.want) != 0 {
+				t.Errorf("Quote = %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAmountsOut(t *testing.T) {
+	reserves := [][2]*big.Int{
+		{big.NewInt(1000), big.NewInt(1000)},
+		{big.NewInt(2000), big.NewInt(4000)},
+	}
+
+	amounts, err := GetAmountsOut(big.NewInt(1000), reserves, []uint32{30, 30})
+	if err != nil {
+		t.Fatalf("GetAmountsOut 返回错误: %v", err)
+	}
+
+	// 第一跳 1000 → 499；第二跳 499 × 9970 × 4000 / (2000 × 10000 + 499 × 9970) = 796.7… → 796
+	want := []int64{1000, 499, 796}
+	if len(amounts) != len(want) {
+		t.Fatalf("GetAmountsOut 返回 %d 个数量, 期望 %d", len(amounts), len(want))
+	}
+	for i, w := range want {
+		if amounts[i].Cmp(big.NewInt(w)) != 0 {
+			t.Errorf("amounts[%d] = %s, 期望 %d", i, amounts[i], w)
+		}
+	}
+
+	if _, err := GetAmountsOut(big.NewInt(1000), reserves, []uint32{30}); !errors.Is(err, ErrInvalidFee) {
+		t.Errorf("手续费数量不匹配时错误 = %v, 期望 ErrInvalidFee", err)
+	}
+}
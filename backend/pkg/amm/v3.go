@@ -0,0 +1,203 @@
+package amm
+
+import (
+	"errors"
+	"math/big"
+)
+
+// FeePipsDenominator V3 手续费的精度（百万分之一），如 3000 表示 0.3%
+const FeePipsDenominator = 1000000
+
+var (
+	// Q96 2^96，sqrtPriceX96 的定点精度
+	Q96 = new(big.Int).Lsh(big.NewInt(1), 96)
+
+	// maxUint256 uint256 最大值，用于模拟合约中的溢出判断
+	maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	feePipsDenominator = big.NewInt(FeePipsDenominator)
+)
+
+// ErrPriceOverflow 计算出的价格超出合约可表示的范围
+var ErrPriceOverflow = errors.New("amm: 价格溢出")
+
+// SqrtPriceX96ToPrice 将 sqrtPriceX96 转换为精确价格（token1/token0，链上最小单位之比）
+// price = sqrtPriceX96² / 2^192
+func SqrtPriceX96ToPrice(sqrtPriceX96 *big.Int) *big.Rat {
+	numerator := new(big.Int).Mul(sqrtPriceX96, sqrtPriceX96)
+	denominator := new(big.Int).Lsh(big.NewInt(1), 192)
+	return new(big.Rat).SetFrac(numerator, denominator)
+}
+
+// GetAmount0Delta 价格在 [sqrtA, sqrtB] 区间内移动时 token0 的数量变化（SqrtPriceMath.getAmount0Delta）
+// amount0 = L × 2^96 × (sqrtB - sqrtA) / sqrtB / sqrtA
+func GetAmount0Delta(sqrtA, sqrtB, liquidity *big.Int, roundUp bool) *big.Int {
+	if sqrtA.Cmp(sqrtB) > 0 {
+		sqrtA, sqrtB = sqrtB, sqrtA
+	}
+	if sqrtA.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+
+	numerator1 := new(big.Int).Lsh(liquidity, 96)
+	numerator2 := new(big.Int).Sub(sqrtB, sqrtA)
+
+	if roundUp {
+		return divRoundingUp(mulDivRoundingUp(numerator1, numerator2, sqrtB), sqrtA)
+	}
+	result := mulDiv(numerator1, numerator2, sqrtB)
+	return result.Quo(result, sqrtA)
+}
+
+// GetAmount1Delta 价格在 [sqrtA, sqrtB] 区间内移动时 token1 的数量变化（SqrtPriceMath.getAmount1Delta）
+// amount1 = L × (sqrtB - sqrtA) / 2^96
+func GetAmount1Delta(sqrtA, sqrtB, liquidity *big.Int, roundUp bool) *big.Int {
+	if sqrtA.Cmp(sqrtB) > 0 {
+		sqrtA, sqrtB = sqrtB, sqrtA
+	}
+
+	diff := new(big.Int).Sub(sqrtB, sqrtA)
+	if roundUp {
+		return mulDivRoundingUp(liquidity, diff, Q96)
+	}
+	return mulDiv(liquidity, diff, Q96)
+}
+
+// GetNextSqrtPriceFromInput 输入指定数量后的下一个价格（SqrtPriceMath.getNextSqrtPriceFromInput）
+// 舍入方向保证不会越过目标价格：输入 token0 时向上取整，输入 token1 时向下取整
+func GetNextSqrtPriceFromInput(sqrtPriceX96, liquidity, amountIn *big.Int, zeroForOne bool) (*big.Int, error) {
+	if sqrtPriceX96.Sign() <= 0 || liquidity.Sign() <= 0 {
+		return nil, ErrInsufficientLiquidity
+	}
+
+	if zeroForOne {
+		return nextSqrtPriceFromAmount0RoundingUp(sqrtPriceX96, liquidity, amountIn), nil
+	}
+	return nextSqrtPriceFromAmount1RoundingDown(sqrtPriceX96, liquidity, amountIn)
+}
+
+// nextSqrtPriceFromAmount0RoundingUp 输入 token0 后的价格
+// 首选 L × 2^96 × sqrtP / (L × 2^96 + amount × sqrtP)；中间乘积超过 uint256 时
+// 合约退化为 L × 2^96 / (L × 2^96 / sqrtP + amount)，这里保持相同的分支以得到一致的舍入
+func nextSqrtPriceFromAmount0RoundingUp(sqrtPriceX96, liquidity, amount *big.Int) *big.Int {
+	if amount.Sign() == 0 {
+		return new(big.Int).Set(sqrtPriceX96)
+	}
+
+	numerator1 := new(big.Int).Lsh(liquidity, 96)
+	product := new(big.Int).Mul(amount, sqrtPriceX96)
+	if product.Cmp(maxUint256) <= 0 {
+		denominator := new(big.Int).Add(numerator1, product)
+		if denominator.Cmp(maxUint256) <= 0 {
+			return mulDivRoundingUp(numerator1, sqrtPriceX96, denominator)
+		}
+	}
+
+	denominator := new(big.Int).Quo(numerator1, sqrtPriceX96)
+	denominator.Add(denominator, amount)
+	return divRoundingUp(numerator1, denominator)
+}
+
+// nextSqrtPriceFromAmount1RoundingDown 输入 token1 后的价格：sqrtP + amount × 2^96 / L（向下取整）
+func nextSqrtPriceFromAmount1RoundingDown(sqrtPriceX96, liquidity, amount *big.Int) (*big.Int, error) {
+	quotient := mulDiv(amount, Q96, liquidity)
+	next := quotient.Add(quotient, sqrtPriceX96)
+	if next.BitLen() > 160 {
+		return nil, ErrPriceOverflow
+	}
+	return next, nil
+}
+
+// SwapStep 单个 tick 区间内的交换结果
+type SwapStep struct {
+	SqrtPriceNextX96 *big.Int // 交换后的价格
+	AmountIn         *big.Int // 实际消耗的输入（不含手续费）
+	AmountOut        *big.Int // 输出数量
+	FeeAmount        *big.Int // 手续费
+}
+
+// ComputeSwapStepExactIn 在当前 tick 区间内按精确输入交换（SwapMath.computeSwapStep，amountRemaining > 0 的分支）
+// sqrtTargetX96 为区间边界（下一个已初始化 tick 的价格或价格限制），feePips 精度为 1e6；
+// 输入在到达边界前用完时 SqrtPriceNextX96 停在区间内，否则等于 sqrtTargetX96，剩余输入需跨 tick 继续交换
+func ComputeSwapStepExactIn(sqrtCurrentX96, sqrtTargetX96, liquidity, amountRemaining *big.Int, feePips uint32) (*SwapStep, error) {
+	if amountRemaining.Sign() <= 0 {
+		return nil, ErrInsufficientInputAmount
+	}
+	if feePips >= FeePipsDenominator {
+		return nil, ErrInvalidFee
+	}
+
+	zeroForOne := sqrtCurrentX96.Cmp(sqrtTargetX96) >= 0
+	feeComplement := big.NewInt(int64(FeePipsDenominator - feePips))
+	amountRemainingLessFee := mulDiv(amountRemaining, feeComplement, feePipsDenominator)
+
+	var amountIn *big.Int
+	if zeroForOne {
+		amountIn = GetAmount0Delta(sqrtTargetX96, sqrtCurrentX96, liquidity, true)
+	} else {
+		amountIn = GetAmount1Delta(sqrtCurrentX96, sqrtTargetX96, liquidity, true)
+	}
+
+	sqrtNext := new(big.Int).Set(sqrtTargetX96)
+	if amountRemainingLessFee.Cmp(amountIn) < 0 {
+		next, err := GetNextSqrtPriceFromInput(sqrtCurrentX96, liquidity, amountRemainingLessFee, zeroForOne)
+		if err != nil {
+			return nil, err
+		}
+		sqrtNext = next
+	}
+	reachedTarget := sqrtNext.Cmp(sqrtTargetX96) == 0
+
+	var amountOut *big.Int
+	if zeroForOne {
+		if !reachedTarget {
+			amountIn = GetAmount0Delta(sqrtNext, sqrtCurrentX96, liquidity, true)
+		}
+		amountOut = GetAmount1Delta(sqrtNext, sqrtCurrentX96, liquidity, false)
+	} else {
+		if !reachedTarget {
+			amountIn = GetAmount1Delta(sqrtCurrentX96, sqrtNext, liquidity, true)
+		}
+		amountOut = GetAmount0Delta(sqrtCurrentX96, sqrtNext, liquidity, false)
+	}
+
+	// 没有到达边界时，剩余输入全部计为手续费（与合约一致）
+	var feeAmount *big.Int
+	if !reachedTarget {
+		feeAmount = new(big.Int).Sub(amountRemaining, amountIn)
+	} else {
+		feeAmount = mulDivRoundingUp(amountIn, big.NewInt(int64(feePips)), feeComplement)
+	}
+
+	return &SwapStep{
+		SqrtPriceNextX96: sqrtNext,
+		AmountIn:         amountIn,
+		AmountOut:        amountOut,
+		FeeAmount:        feeAmount,
+	}, nil
+}
+
+// mulDiv a × b / denominator，向下取整（FullMath.mulDiv）
+func mulDiv(a, b, denominator *big.Int) *big.Int {
+	result := new(big.Int).Mul(a, b)
+	return result.Quo(result, denominator)
+}
+
+// mulDivRoundingUp a × b / denominator，向上取整（FullMath.mulDivRoundingUp）
+func mulDivRoundingUp(a, b, denominator *big.Int) *big.Int {
+	product := new(big.Int).Mul(a, b)
+	result, remainder := new(big.Int).QuoRem(product, denominator, new(big.Int))
+	if remainder.Sign() > 0 {
+		result.Add(result, big.NewInt(1))
+	}
+	return result
+}
+
+// divRoundingUp a / b，向上取整（UnsafeMath.divRoundingUp）
+func divRoundingUp(a, b *big.Int) *big.Int {
+	result, remainder := new(big.Int).QuoRem(a, b, new(big.Int))
+	if remainder.Sign() > 0 {
+		result.Add(result, big.NewInt(1))
+	}
+	return result
+}
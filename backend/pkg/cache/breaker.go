@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// circuitBreaker 缓存熔断器
+// 连续失败达到阈值后熔断（跳过所有缓存操作），冷却期结束后放行一次探测请求，
+// 探测成功则恢复，失败则继续熔断
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int           // 连续失败阈值
+	cooldown  time.Duration // 熔断冷却时间
+
+	failures  int       // 当前连续失败次数
+	open      bool      // 是否处于熔断状态
+	openUntil time.Time // 熔断截止时间（到期后允许一次探测）
+}
+
+// newCircuitBreaker 创建熔断器
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow 判断当前是否允许执行缓存操作
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return true
+	}
+
+	now := time.Now()
+	if now.Before(b.openUntil) {
+		return false
+	}
+
+	// 冷却期结束：放行本次请求作为探测，同时推迟下一次探测，避免并发请求同时涌入
+	b.openUntil = now.Add(b.cooldown)
+	return true
+}
+
+// available 熔断器是否处于闭合状态（不消耗探测机会）
+func (b *circuitBreaker) available() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.open || !time.Now().Before(b.openUntil)
+}
+
+// success 记录一次成功
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.open {
+		log.Println("✅ Redis 已恢复，重新启用缓存")
+	}
+	b.open = false
+	b.failures = 0
+}
+
+// failure 记录一次失败
+func (b *circuitBreaker) failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.open || b.failures < b.threshold {
+		return
+	}
+
+	// 只在进入熔断时记录一次日志
+	b.open = true
+	b.openUntil = time.Now().Add(b.cooldown)
+	log.Printf("⚠️  Redis 连续失败 %d 次，暂停使用缓存 %v（最后错误: %v）", b.failures, b.cooldown, err)
+}
@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -10,10 +11,18 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+var (
+	// ErrCacheMiss 缓存不存在
+	ErrCacheMiss = errors.New("缓存不存在")
+	// ErrCacheUnavailable 缓存已熔断，暂不可用
+	ErrCacheUnavailable = errors.New("缓存暂不可用")
+)
+
 // RedisCache Redis 缓存客户端
 type RedisCache struct {
-	client *redis.Client
-	ctx    context.Context
+	client  *redis.Client
+	ctx     context.Context
+	breaker *circuitBreaker
 }
 
 // RedisConfig Redis 配置
@@ -23,6 +32,10 @@ type RedisConfig struct {
 	Password string
 	DB       int
 	TTL      time.Duration // 默认过期时间
+
+	// === 熔断配置 ===
+	FailureThreshold int           // 连续失败多少次后熔断（默认 5）
+	Cooldown         time.Duration // 熔断冷却时间，到期后探测恢复（默认 30 秒）
 }
 
 // NewRedisCache 创建 Redis 缓存客户端
@@ -42,11 +55,35 @@ func NewRedisCache(config *RedisConfig) (*RedisCache, error) {
 
 	log.Println("✅ Redis 连接成功")
 	return &RedisCache{
-		client: client,
-		ctx:    ctx,
+		client:  client,
+		ctx:     ctx,
+		breaker: newCircuitBreaker(config.FailureThreshold, config.Cooldown),
 	}, nil
 }
 
+// Available 缓存当前是否可用（未熔断）
+// 调用方可据此在 Redis 故障期间完全跳过缓存逻辑
+func (c *RedisCache) Available() bool {
+	return c.breaker.available()
+}
+
+// do 在熔断器保护下执行缓存操作
+// 缓存未命中（redis.Nil）视为成功，不计入失败次数
+func (c *RedisCache) do(op func() error) error {
+	if !c.breaker.allow() {
+		return ErrCacheUnavailable
+	}
+
+	err := op()
+	if err == nil || errors.Is(err, redis.Nil) {
+		c.breaker.success()
+		return err
+	}
+
+	c.breaker.failure(err)
+	return err
+}
+
 // Set 设置缓存
 func (c *RedisCache) Set(key string, value interface{}, ttl time.Duration) error {
 	// 序列化为 JSON
@@ -56,16 +93,26 @@ func (c *RedisCache) Set(key string, value interface{}, ttl time.Duration) error
 	}
 
 	// 设置缓存
-	return c.client.Set(c.ctx, key, data, ttl).Err()
+	return c.do(func() error {
+		return c.client.Set(c.ctx, key, data, ttl).Err()
+	})
 }
 
 // Get 获取缓存
 func (c *RedisCache) Get(key string, dest interface{}) error {
 	// 获取缓存
-	data, err := c.client.Get(c.ctx, key).Bytes()
+	var data []byte
+	err := c.do(func() error {
+		var err error
+		data, err = c.client.Get(c.ctx, key).Bytes()
+		return err
+	})
 	if err != nil {
-		if err == redis.Nil {
-			return fmt.Errorf("缓存不存在")
+		if errors.Is(err, redis.Nil) {
+			return ErrCacheMiss
+		}
+		if errors.Is(err, ErrCacheUnavailable) {
+			return err
 		}
 		return fmt.Errorf("获取缓存失败: %w", err)
 	}
@@ -80,12 +127,19 @@ func (c *RedisCache) Get(key string, dest interface{}) error {
 
 // Delete 删除缓存
 func (c *RedisCache) Delete(key string) error {
-	return c.client.Del(c.ctx, key).Err()
+	return c.do(func() error {
+		return c.client.Del(c.ctx, key).Err()
+	})
 }
 
 // Exists 检查缓存是否存在
 func (c *RedisCache) Exists(key string) (bool, error) {
-	result, err := c.client.Exists(c.ctx, key).Result()
+	var result int64
+	err := c.do(func() error {
+		var err error
+		result, err = c.client.Exists(c.ctx, key).Result()
+		return err
+	})
 	if err != nil {
 		return false, err
 	}
@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/defi-bot/backend/pkg/units"
 	"github.com/defi-bot/backend/pkg/web3"
 )
 
@@ -14,6 +15,7 @@ type AggregatorProtocol struct {
 	web3Client     *web3.Client
 	protocolName   string
 	aggregatorType string // "1inch", "0x", "paraswap"
+	oracleAddress  string // 链上报价合约地址（1inch: OffchainOracle）
 }
 
 // NewAggregatorProtocol 创建聚合器协议适配器
@@ -25,6 +27,11 @@ func NewAggregatorProtocol(web3Client *web3.Client, protocolName string) *Aggreg
 	}
 }
 
+// SetOracleAddress 设置链上报价合约地址（1inch OffchainOracle 在每条链上的部署地址不同）
+func (p *AggregatorProtocol) SetOracleAddress(address string) {
+	p.oracleAddress = address
+}
+
 // GetProtocolName 获取协议名称
 func (p *AggregatorProtocol) GetProtocolName() string {
 	return p.protocolName
@@ -71,12 +78,49 @@ func (p *AggregatorProtocol) GetLiquidity(routerAddress string) (*LiquidityInfo,
 // === 私有方法：不同聚合器的价格查询 ===
 
 // get1inchPrice 获取 1inch 的价格
+// 聚合器没有池子地址，只凭路由合约地址无法确定代币对，按代币查询请使用 GetRate
 func (p *AggregatorProtocol) get1inchPrice(routerAddress string) (*PriceInfo, error) {
-	// TODO: 实现 1inch 价格查询
-	// 方式1：调用 1inch API (https://api.1inch.dev/swap/v6.0/1/quote)
-	// 方式2：调用链上 AggregationRouterV6 的 getRate() 方法
+	return nil, fmt.Errorf("%w: 1inch 没有池子地址，请使用 GetRate 按代币查询", ErrNotImplemented)
+}
+
+// GetRate 查询聚合器给出的现货价格（不依赖链下 API）
+// Price 为 1 个 tokenIn 可换的 tokenOut 数量（已按精度换算），InversePrice 为其倒数；
+// 目前只支持 1inch（链上 OffchainOracle）
+func (p *AggregatorProtocol) GetRate(tokenIn, tokenOut string, decimalsIn, decimalsOut int) (*PriceInfo, error) {
+	switch p.aggregatorType {
+	case "1inch":
+		return p.get1inchRate(tokenIn, tokenOut, decimalsIn, decimalsOut)
+	default:
+		return nil, fmt.Errorf("%w: 聚合器 %s 的链上汇率查询", ErrNotImplemented, p.aggregatorType)
+	}
+}
+
+// get1inchRate 调用 1inch OffchainOracle.getRate 获取现货价格
+// weightedRate = tokenOut 最小单位 / tokenIn 最小单位 × 1e18，
+// 换算为代币单位：price = weightedRate / 1e18 × 10^decimalsIn / 10^decimalsOut
+func (p *AggregatorProtocol) get1inchRate(tokenIn, tokenOut string, decimalsIn, decimalsOut int) (*PriceInfo, error) {
+	if p.oracleAddress == "" {
+		return nil, fmt.Errorf("%w: 未配置 1inch OffchainOracle 地址", ErrInvalidParams)
+	}
+
+	rate, err := p.web3Client.GetOffchainOracleRate(p.oracleAddress, tokenIn, tokenOut, true)
+	if err != nil {
+		return nil, err
+	}
+	if rate.Sign() == 0 {
+		return nil, ErrNoLiquidity
+	}
 
-	return nil, fmt.Errorf("1inch 价格查询未实现，建议使用 API 或链上 Quoter")
+	// rate × 10^decimalsIn / 10^(18 + decimalsOut)
+	numerator := new(big.Int).Mul(rate, units.Pow10(decimalsIn))
+	denominator := units.Pow10(18 + decimalsOut)
+	price := new(big.Float).Quo(new(big.Float).SetInt(numerator), new(big.Float).SetInt(denominator))
+
+	return &PriceInfo{
+		Price:        price,
+		InversePrice: new(big.Float).Quo(big.NewFloat(1), price),
+		Timestamp:    time.Now(),
+	}, nil
 }
 
 // get0xPrice 获取 0x Protocol 的价格
@@ -84,7 +128,7 @@ func (p *AggregatorProtocol) get0xPrice(routerAddress string) (*PriceInfo, error
 	// TODO: 实现 0x 价格查询
 	// 调用 0x API (https://api.0x.org/swap/v1/price)
 
-	return nil, fmt.Errorf("0x Protocol 价格查询未实现，建议使用 API")
+	return nil, fmt.Errorf("%w: 0x Protocol 价格查询未实现，建议使用 API", ErrNotImplemented)
 }
 
 // getParaSwapPrice 获取 ParaSwap 的价格
@@ -92,14 +136,14 @@ func (p *AggregatorProtocol) getParaSwapPrice(routerAddress string) (*PriceInfo,
 	// TODO: 实现 ParaSwap 价格查询
 	// 调用 ParaSwap API
 
-	return nil, fmt.Errorf("ParaSwap 价格查询未实现，建议使用 API")
+	return nil, fmt.Errorf("%w: ParaSwap 价格查询未实现，建议使用 API", ErrNotImplemented)
 }
 
 // QuoteSwap 聚合器专用：获取精确报价
 // 这是聚合器最重要的功能，返回最优路由和价格
 func (p *AggregatorProtocol) QuoteSwap(tokenIn, tokenOut string, amountIn *big.Int) (*AggregatorQuote, error) {
 	// TODO: 实现聚合器报价查询
-	return nil, fmt.Errorf("聚合器报价功能开发中")
+	return nil, fmt.Errorf("%w: 聚合器报价功能开发中", ErrNotImplemented)
 }
 
 // AggregatorQuote 聚合器报价结构
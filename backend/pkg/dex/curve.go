@@ -31,7 +31,7 @@ func (p *CurveProtocol) GetPairAddress(factory, token0, token1 string, params ..
 	// 或者直接在配置中指定
 
 	// TODO: 实现 Curve Registry 查询
-	return "", fmt.Errorf("Curve 池地址查询未实现")
+	return "", fmt.Errorf("%w: Curve 池地址查询未实现", ErrNotImplemented)
 }
 
 // GetPrice 获取 Curve 池的价格信息
@@ -45,7 +45,7 @@ func (p *CurveProtocol) GetPrice(poolAddress string) (*PriceInfo, error) {
 	// - balances(i) - 获取每个代币的余额
 	// - get_dy(i, j, 1e18) - 计算价格
 
-	return nil, fmt.Errorf("Curve 价格查询未实现")
+	return nil, fmt.Errorf("%w: Curve 价格查询未实现", ErrNotImplemented)
 }
 
 // GetLiquidity 获取 Curve 池的流动性
@@ -64,11 +64,11 @@ func (p *CurveProtocol) GetLiquidity(poolAddress string) (*LiquidityInfo, error)
 // i: 输入代币索引, j: 输出代币索引, dx: 输入金额
 func (p *CurveProtocol) GetDy(poolAddress string, i, j int, dx *big.Int) (*big.Int, error) {
 	// TODO: 调用 Curve 池合约的 get_dy(i, j, dx) 方法
-	return nil, fmt.Errorf("Curve get_dy 未实现")
+	return nil, fmt.Errorf("%w: Curve get_dy 未实现", ErrNotImplemented)
 }
 
 // GetVirtualPrice 获取虚拟价格（Curve 专用）
 func (p *CurveProtocol) GetVirtualPrice(poolAddress string) (*big.Int, error) {
 	// TODO: 调用 Curve 池合约的 get_virtual_price() 方法
-	return nil, fmt.Errorf("Curve get_virtual_price 未实现")
+	return nil, fmt.Errorf("%w: Curve get_virtual_price 未实现", ErrNotImplemented)
 }
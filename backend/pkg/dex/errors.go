@@ -0,0 +1,17 @@
+package dex
+
+import (
+	"errors"
+)
+
+// 协议适配器的错误分类，调用方可用 errors.Is 判断
+var (
+	// ErrNoLiquidity 池子没有流动性（储备量或活跃流动性为 0）
+	ErrNoLiquidity = errors.New("无流动性")
+	// ErrInvalidPrice 池子价格无效（如 V3 池已创建但未初始化）
+	ErrInvalidPrice = errors.New("无效的价格")
+	// ErrInvalidParams 协议需要的参数缺失或类型错误（如 V3 的 fee）
+	ErrInvalidParams = errors.New("协议参数错误")
+	// ErrNotImplemented 协议或功能尚未实现
+	ErrNotImplemented = errors.New("功能尚未实现")
+)
@@ -1,63 +1,141 @@
 package dex
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/defi-bot/backend/pkg/web3"
 )
 
+// ErrProtocolDisabled 协议已被配置禁用
+var ErrProtocolDisabled = errors.New("协议已禁用")
+
 // ProtocolFactory 协议工厂
 // 根据协议名称创建对应的协议适配器
 type ProtocolFactory struct {
 	web3Client *web3.Client
+	allowed    map[string]bool // 白名单（为空表示全部允许）
+	denied     map[string]bool // 黑名单（优先于白名单）
+}
+
+// ProtocolPolicy 协议启用策略
+// 用于按部署禁用在某条链上已知有问题的协议，而无需修改代码
+type ProtocolPolicy struct {
+	Allowed []string // 白名单：非空时只允许列表中的协议
+	Denied  []string // 黑名单：列表中的协议即使已实现也不会创建
 }
 
-// NewProtocolFactory 创建协议工厂
+// NewProtocolFactory 创建协议工厂（允许所有协议）
 func NewProtocolFactory(web3Client *web3.Client) *ProtocolFactory {
+	return NewProtocolFactoryWithPolicy(web3Client, ProtocolPolicy{})
+}
+
+// NewProtocolFactoryWithPolicy 创建带启用策略的协议工厂
+func NewProtocolFactoryWithPolicy(web3Client *web3.Client, policy ProtocolPolicy) *ProtocolFactory {
 	return &ProtocolFactory{
 		web3Client: web3Client,
+		allowed:    toProtocolSet(policy.Allowed),
+		denied:     toProtocolSet(policy.Denied),
+	}
+}
+
+// IsProtocolEnabled 判断协议是否被策略允许
+func (f *ProtocolFactory) IsProtocolEnabled(protocolName string) bool {
+	if protocolName == "" {
+		protocolName = "uniswap_v2" // 空字符串默认为 V2
+	}
+	if f.denied[protocolName] {
+		return false
 	}
+	return len(f.allowed) == 0 || f.allowed[protocolName]
+}
+
+// toProtocolSet 将协议列表转换为集合
+func toProtocolSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// ProtocolOptions 创建协议适配器时的 DEX 级别选项
+type ProtocolOptions struct {
+	ReserveStyle string // V2 储备量读取方式：combined, separate
+	PoolManager  string // V4 PoolManager 地址（V4 没有独立的池子合约）
 }
 
 // CreateProtocol 创建协议适配器
 func (f *ProtocolFactory) CreateProtocol(protocolName string) (Protocol, error) {
+	return f.CreateProtocolWithOptions(protocolName, ProtocolOptions{})
+}
+
+// CreateProtocolWithOptions 使用 DEX 级别选项创建协议适配器
+func (f *ProtocolFactory) CreateProtocolWithOptions(protocolName string, opts ProtocolOptions) (Protocol, error) {
+	if !f.IsProtocolEnabled(protocolName) {
+		return nil, fmt.Errorf("%w: %s", ErrProtocolDisabled, protocolName)
+	}
+
 	switch protocolName {
 	// === V2 兼容协议（AMM） ===
 	case "uniswap_v2", "sushiswap", "pancakeswap_v2", "shibaswap", "biswap", "":
 		// 空字符串默认为 V2（向后兼容）
-		return NewUniswapV2Protocol(f.web3Client), nil
+		return NewUniswapV2ProtocolWithStyle(f.web3Client, opts.ReserveStyle), nil
 
 	// === V3 协议（集中流动性 AMM） ===
 	case "uniswap_v3", "pancakeswap_v3":
 		return NewUniswapV3Protocol(f.web3Client), nil
 
+	// === V4 协议（单例 PoolManager） ===
+	case "uniswap_v4":
+		return NewUniswapV4Protocol(f.web3Client, opts.PoolManager), nil
+
+	// === Kyber 放大储备量 AMM（动态费率） ===
+	case "kyberswap", "kyber_classic":
+		return NewKyberClassicProtocol(f.web3Client), nil
+
+	// === Kyber 集中流动性 AMM ===
+	case "kyber_elastic":
+		return NewKyberElasticProtocol(f.web3Client), nil
+
 	// === StableSwap 协议（稳定币交换） ===
 	case "curve", "ellipsis":
 		// TODO: 实现 Curve 适配器
-		return nil, fmt.Errorf("Curve 协议适配器开发中")
+		return nil, fmt.Errorf("%w: Curve 协议适配器开发中", ErrNotImplemented)
 
 	// === 聚合器协议 ===
 	case "1inch", "0x", "paraswap", "matcha":
 		// TODO: 实现聚合器适配器
-		return nil, fmt.Errorf("聚合器协议适配器开发中")
+		return nil, fmt.Errorf("%w: 聚合器协议适配器开发中", ErrNotImplemented)
 
 	// === 订单簿协议 ===
 	case "dydx", "serum":
 		// TODO: 实现订单簿适配器
-		return nil, fmt.Errorf("订单簿协议适配器开发中")
+		return nil, fmt.Errorf("%w: 订单簿协议适配器开发中", ErrNotImplemented)
 
 	// === 混合型协议 ===
 	case "balancer":
 		// TODO: 实现 Balancer 适配器
-		return nil, fmt.Errorf("Balancer 协议适配器开发中")
+		return nil, fmt.Errorf("%w: Balancer 协议适配器开发中", ErrNotImplemented)
 
 	default:
 		return nil, fmt.Errorf("不支持的协议: %s", protocolName)
 	}
 }
 
-// GetSupportedProtocols 获取支持的协议列表
+// GetSupportedProtocols 获取支持的协议列表（已按启用策略过滤）
 func (f *ProtocolFactory) GetSupportedProtocols() []string {
+	supported := make([]string, 0)
+	for _, name := range allProtocols() {
+		if f.IsProtocolEnabled(name) {
+			supported = append(supported, name)
+		}
+	}
+	return supported
+}
+
+// allProtocols 所有已知协议
+func allProtocols() []string {
 	return []string{
 		// AMM - V2类型
 		"uniswap_v2",
@@ -70,6 +148,14 @@ func (f *ProtocolFactory) GetSupportedProtocols() []string {
 		"uniswap_v3",
 		"pancakeswap_v3",
 
+		// AMM - V4类型
+		"uniswap_v4",
+
+		// AMM - Kyber
+		"kyberswap",
+		"kyber_classic",
+		"kyber_elastic",
+
 		// StableSwap
 		"curve",
 		"ellipsis",
@@ -89,11 +175,13 @@ func (f *ProtocolFactory) GetSupportedProtocols() []string {
 	}
 }
 
-// GetProtocolType 获取协议类型（v2 或 v3）
+// GetProtocolType 获取协议类型（v2、v3 或 v4）
 func (f *ProtocolFactory) GetProtocolType(protocolName string) string {
 	switch protocolName {
-	case "uniswap_v3", "pancakeswap_v3":
+	case "uniswap_v3", "pancakeswap_v3", "kyber_elastic":
 		return "v3"
+	case "uniswap_v4":
+		return "v4"
 	case "curve", "ellipsis":
 		return "stableswap"
 	case "1inch", "0x", "paraswap", "matcha":
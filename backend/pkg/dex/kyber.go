@@ -0,0 +1,270 @@
+package dex
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/defi-bot/backend/pkg/units"
+	"github.com/defi-bot/backend/pkg/web3"
+)
+
+// kyberFeePrecision Kyber Classic 动态费率的精度
+var kyberFeePrecision = units.Pow10(18)
+
+// KyberClassicProtocol KyberSwap Classic (DMM) 协议适配器
+// 池子使用放大系数（amp）把实际储备量放大为虚拟储备量，在虚拟储备量上做恒定乘积，
+// 手续费随市场波动动态调整；直接套用 V2 公式会高估价格影响并用错费率
+type KyberClassicProtocol struct {
+	web3Client *web3.Client
+}
+
+// NewKyberClassicProtocol 创建 KyberSwap Classic 协议适配器
+func NewKyberClassicProtocol(web3Client *web3.Client) *KyberClassicProtocol {
+	return &KyberClassicProtocol{
+		web3Client: web3Client,
+	}
+}
+
+// GetProtocolName 获取协议名称
+func (p *KyberClassicProtocol) GetProtocolName() string {
+	return "kyber_classic"
+}
+
+// GetPairAddress 获取池子地址
+// 同一代币对可能有多个不同放大系数的池子，选择实际储备量最大的一个；没有池子时返回空字符串
+func (p *KyberClassicProtocol) GetPairAddress(factory, token0, token1 string, params ...interface{}) (string, error) {
+	pools, err := p.web3Client.GetKyberClassicPools(factory, token0, token1)
+	if err != nil {
+		return "", fmt.Errorf("获取Kyber池子列表失败: %w", err)
+	}
+
+	best := ""
+	bestDepth := big.NewInt(0)
+	for _, pool := range pools {
+		info, err := p.web3Client.GetKyberTradeInfo(pool)
+		if err != nil {
+			continue
+		}
+		depth := new(big.Int).Mul(info.Reserve0, info.Reserve1)
+		if depth.Cmp(bestDepth) > 0 {
+			best, bestDepth = pool, depth
+		}
+	}
+
+	return best, nil
+}
+
+// GetPrice 获取价格信息（按虚拟储备量计算，与池子实际报价一致）
+// Reserve0/Reserve1 返回实际储备量，虚拟储备量放在 VirtualReserve0/VirtualReserve1 供调用方定价
+func (p *KyberClassicProtocol) GetPrice(pairAddress string) (*PriceInfo, error) {
+	info, err := p.web3Client.GetKyberTradeInfo(pairAddress)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易参数失败: %w", err)
+	}
+
+	if info.Reserve0.Sign() == 0 || info.Reserve1.Sign() == 0 {
+		return nil, ErrNoLiquidity
+	}
+
+	v0, v1 := kyberVirtualReserves(info)
+	r0 := new(big.Float).SetInt(v0)
+	r1 := new(big.Float).SetInt(v1)
+
+	liquidity := new(big.Int).Sqrt(new(big.Int).Mul(info.Reserve0, info.Reserve1))
+
+	return &PriceInfo{
+		Price:        new(big.Float).Quo(r1, r0), // token1/token0
+		InversePrice: new(big.Float).Quo(r0, r1), // token0/token1
+		Reserve0:     info.Reserve0,
+		Reserve1:     info.Reserve1,
+		Liquidity:    liquidity,
+
+		VirtualReserve0: v0,
+		VirtualReserve1: v1,
+
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// GetLiquidity 获取流动性信息（实际储备量）
+func (p *KyberClassicProtocol) GetLiquidity(pairAddress string) (*LiquidityInfo, error) {
+	info, err := p.web3Client.GetKyberTradeInfo(pairAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LiquidityInfo{
+		Liquidity: new(big.Int).Sqrt(new(big.Int).Mul(info.Reserve0, info.Reserve1)),
+		Reserve0:  info.Reserve0,
+		Reserve1:  info.Reserve1,
+	}, nil
+}
+
+// GetAmountOut 按池子当前状态计算输出数量
+// zeroForOne 为 true 表示 token0 → token1
+func (p *KyberClassicProtocol) GetAmountOut(pairAddress string, amountIn *big.Int, zeroForOne bool) (*big.Int, error) {
+	info, err := p.web3Client.GetKyberTradeInfo(pairAddress)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易参数失败: %w", err)
+	}
+
+	v0, v1 := kyberVirtualReserves(info)
+	if zeroForOne {
+		return KyberClassicAmountOut(amountIn, v0, v1, info.Reserve1, info.FeeInPrecision), nil
+	}
+	return KyberClassicAmountOut(amountIn, v1, v0, info.Reserve0, info.FeeInPrecision), nil
+}
+
+// KyberClassicAmountOut Kyber Classic 输出数量公式（与 DMMLibrary.getAmountOut 一致）
+// amountInWithFee = amountIn × (1e18 - fee) / 1e18
+// amountOut = amountInWithFee × vReserveOut / (vReserveIn + amountInWithFee)
+// 虚拟储备量可以远大于实际储备量，而池子实际付出的数量必须小于实际储备量 reserveOut，
+// 公式结果超出时截断为 reserveOut - 1
+func KyberClassicAmountOut(amountIn, vReserveIn, vReserveOut, reserveOut, feeInPrecision *big.Int) *big.Int {
+	if amountIn.Sign() <= 0 || vReserveIn.Sign() <= 0 || vReserveOut.Sign() <= 0 || reserveOut.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+
+	amountInWithFee := new(big.Int).Sub(kyberFeePrecision, feeInPrecision)
+	amountInWithFee.Mul(amountInWithFee, amountIn)
+	amountInWithFee.Quo(amountInWithFee, kyberFeePrecision)
+
+	numerator := new(big.Int).Mul(amountInWithFee, vReserveOut)
+	denominator := new(big.Int).Add(vReserveIn, amountInWithFee)
+	amountOut := numerator.Quo(numerator, denominator)
+
+	// 合约要求 amountOut < reserveOut（DMMPool: INSUFFICIENT_LIQUIDITY）
+	if amountOut.Cmp(reserveOut) >= 0 {
+		return amountOut.Sub(reserveOut, big.NewInt(1))
+	}
+	return amountOut
+}
+
+// kyberVirtualReserves 返回参与定价的储备量（未放大的池子 vReserve 为 0，使用实际储备量）
+func kyberVirtualReserves(info *web3.KyberTradeInfo) (*big.Int, *big.Int) {
+	if info.VReserve0.Sign() == 0 || info.VReserve1.Sign() == 0 {
+		return info.Reserve0, info.Reserve1
+	}
+	return info.VReserve0, info.VReserve1
+}
+
+// KyberElasticProtocol KyberSwap Elastic 协议适配器
+// 集中流动性 AMM，价格语义与 V3 相同（sqrtP 为 sqrtPriceX96），流动性包含复投的手续费
+type KyberElasticProtocol struct {
+	web3Client *web3.Client
+}
+
+// NewKyberElasticProtocol 创建 KyberSwap Elastic 协议适配器
+func NewKyberElasticProtocol(web3Client *web3.Client) *KyberElasticProtocol {
+	return &KyberElasticProtocol{
+		web3Client: web3Client,
+	}
+}
+
+// GetProtocolName 获取协议名称
+func (p *KyberElasticProtocol) GetProtocolName() string {
+	return "kyber_elastic"
+}
+
+// GetPairAddress 获取池子地址
+// params[0] 为 swapFeeUnits (uint32，精度 1e5，如 8, 10, 40, 300, 1000)；池子未初始化时返回空字符串
+func (p *KyberElasticProtocol) GetPairAddress(factory, token0, token1 string, params ...interface{}) (string, error) {
+	if len(params) == 0 {
+		return "", fmt.Errorf("%w: Kyber Elastic需要指定fee参数", ErrInvalidParams)
+	}
+
+	fee, ok := params[0].(uint32)
+	if !ok {
+		// 尝试从int转换
+		if feeInt, ok := params[0].(int); ok {
+			fee = uint32(feeInt)
+		} else {
+			return "", fmt.Errorf("%w: fee参数类型错误，应该是uint32", ErrInvalidParams)
+		}
+	}
+
+	poolAddress, err := p.web3Client.GetKyberElasticPool(factory, token0, token1, fee)
+	if err != nil {
+		return "", fmt.Errorf("获取Kyber Elastic池子地址失败: %w", err)
+	}
+	if poolAddress == "" {
+		return "", nil
+	}
+
+	state, err := p.web3Client.GetKyberElasticPoolState(poolAddress)
+	if err != nil {
+		return "", fmt.Errorf("获取Kyber Elastic池子状态失败: %w", err)
+	}
+	if state.SqrtP.Sign() == 0 {
+		return "", nil
+	}
+
+	return poolAddress, nil
+}
+
+// GetPrice 获取价格信息
+func (p *KyberElasticProtocol) GetPrice(pairAddress string) (*PriceInfo, error) {
+	state, liquidity, err := p.readState(pairAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.SqrtP.Sign() == 0 {
+		return nil, ErrInvalidPrice
+	}
+
+	if liquidity.Sign() == 0 {
+		return nil, ErrNoLiquidity
+	}
+
+	// 价格和虚拟储备量的计算与 V3 相同
+	v3 := &UniswapV3Protocol{web3Client: p.web3Client}
+	price := v3.sqrtPriceX96ToPrice(state.SqrtP)
+	inversePrice := new(big.Float).Quo(big.NewFloat(1.0), price)
+	reserve0, reserve1 := v3.CalculateVirtualReserves(liquidity, state.SqrtP)
+
+	return &PriceInfo{
+		Price:        price,
+		InversePrice: inversePrice,
+		Reserve0:     reserve0,
+		Reserve1:     reserve1,
+		Liquidity:    liquidity,
+
+		SqrtPriceX96:     state.SqrtP,
+		Tick:             state.CurrentTick,
+		FeeGrowthGlobal0: big.NewInt(0),
+		FeeGrowthGlobal1: big.NewInt(0),
+
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// GetLiquidity 获取详细的流动性信息
+func (p *KyberElasticProtocol) GetLiquidity(pairAddress string) (*LiquidityInfo, error) {
+	state, liquidity, err := p.readState(pairAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LiquidityInfo{
+		Liquidity:    liquidity,
+		Tick:         state.CurrentTick,
+		SqrtPriceX96: state.SqrtP,
+	}, nil
+}
+
+// readState 读取池子的价格状态和流动性
+func (p *KyberElasticProtocol) readState(pairAddress string) (*web3.KyberElasticPoolState, *big.Int, error) {
+	state, err := p.web3Client.GetKyberElasticPoolState(pairAddress)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取池子状态失败: %w", err)
+	}
+
+	liquidity, err := p.web3Client.GetKyberElasticLiquidity(pairAddress)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取流动性失败: %w", err)
+	}
+
+	return state, liquidity, nil
+}
@@ -0,0 +1,62 @@
+package dex
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestKyberClassicAmountOut(t *testing.T) {
+	e18 := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), e18) }
+	fee := new(big.Int).Div(e18, big.NewInt(1000)) // 0.1%
+
+	tests := []struct {
+		name        string
+		amountIn    *big.Int
+		vReserveIn  *big.Int
+		vReserveOut *big.Int
+		reserveOut  *big.Int
+		fee         *big.Int
+		want        *big.Int
+	}{
+		{
+			// 未放大（虚拟储备量 = 实际储备量）：1000 × 1000 / (1000 + 1000) = 500
+			name: "未放大池", amountIn: big.NewInt(1000), vReserveIn: big.NewInt(1000),
+			vReserveOut: big.NewInt(1000), reserveOut: big.NewInt(1000), fee: big.NewInt(0),
+			want: big.NewInt(500),
+		},
+		{
+			// 扣除手续费后 amountInWithFee = 999 × 10^15
+			name: "动态费率", amountIn: ether(1), vReserveIn: ether(1000), vReserveOut: ether(1000),
+			reserveOut: ether(1000), fee: fee,
+			want: func() *big.Int {
+				in := new(big.Int).Mul(big.NewInt(999), new(big.Int).Exp(big.NewInt(10), big.NewInt(15), nil))
+				num := new(big.Int).Mul(in, ether(1000))
+				return num.Quo(num, new(big.Int).Add(ether(1000), in))
+			}(),
+		},
+		{
+			// 放大 100 倍的池子，公式输出 ≈ 9.9 远超实际储备量 5，截断为 reserveOut - 1
+			name: "超出实际储备量时截断", amountIn: ether(10), vReserveIn: ether(1000), vReserveOut: ether(1000),
+			reserveOut: ether(5), fee: big.NewInt(0),
+			want: new(big.Int).Sub(ether(5), big.NewInt(1)),
+		},
+		{
+			name: "输入为 0", amountIn: big.NewInt(0), vReserveIn: ether(1), vReserveOut: ether(1),
+			reserveOut: ether(1), fee: big.NewInt(0), want: big.NewInt(0),
+		},
+		{
+			name: "实际储备量为 0", amountIn: ether(1), vReserveIn: ether(1), vReserveOut: ether(1),
+			reserveOut: big.NewInt(0), fee: big.NewInt(0), want: big.NewInt(0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := KyberClassicAmountOut(tt.amountIn, tt.vReserveIn, tt.vReserveOut, tt.reserveOut, tt.fee)
+			if got.Cmp(tt.want) != 0 {
+				t.Errorf("KyberClassicAmountOut = %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -23,6 +23,21 @@ type Protocol interface {
 	GetProtocolName() string
 }
 
+// BlockPriceReader 可选能力：读取指定区块的价格
+// 实现该接口的协议把区块号传入底层 eth_call，用于把多个池子的读取固定在同一区块
+type BlockPriceReader interface {
+	GetPriceAtBlock(pairAddress string, block uint64) (*PriceInfo, error)
+}
+
+// GetPriceAtBlock 读取指定区块的价格
+// 协议未实现 BlockPriceReader 时忽略区块号，读取最新状态（兼容旧的适配器）
+func GetPriceAtBlock(p Protocol, pairAddress string, block uint64) (*PriceInfo, error) {
+	if reader, ok := p.(BlockPriceReader); ok {
+		return reader.GetPriceAtBlock(pairAddress, block)
+	}
+	return p.GetPrice(pairAddress)
+}
+
 // PriceInfo 价格信息
 type PriceInfo struct {
 	Price        *big.Float // token1/token0 的价格
@@ -31,11 +46,17 @@ type PriceInfo struct {
 	Reserve1     *big.Int   // token1 储备量
 	Liquidity    *big.Int   // 流动性（V2: sqrt(reserve0*reserve1), V3: 实际流动性）
 
+	// 放大池（Kyber Classic）参与定价的虚拟储备量，Reserve0/Reserve1 仍为实际储备量
+	// 为 nil 时按 Reserve0/Reserve1 定价
+	VirtualReserve0 *big.Int
+	VirtualReserve1 *big.Int
+
 	// === V3 专用字段 ===
 	SqrtPriceX96     *big.Int // V3 的 sqrtPriceX96
 	Tick             int32    // V3 的 tick
 	FeeGrowthGlobal0 *big.Int // V3 手续费增长0
 	FeeGrowthGlobal1 *big.Int // V3 手续费增长1
+	Fee              uint32   // 池子当前费率（动态费率池从链上状态读取；0 表示未读取，使用池子的费率层级）
 
 	Timestamp time.Time // 时间戳
 }
@@ -8,16 +8,32 @@ import (
 	"github.com/defi-bot/backend/pkg/web3"
 )
 
+// 储备量读取方式
+const (
+	ReserveStyleCombined = "combined" // 调用 getReserves()
+	ReserveStyleSeparate = "separate" // getReserves() 失败时回退到 reserve0() / reserve1()
+)
+
 // UniswapV2Protocol Uniswap V2 协议适配器
 // 也兼容 SushiSwap, PancakeSwap V2 等所有 V2 分叉
 type UniswapV2Protocol struct {
-	web3Client *web3.Client
+	web3Client   *web3.Client
+	reserveStyle string
 }
 
 // NewUniswapV2Protocol 创建 Uniswap V2 协议适配器
 func NewUniswapV2Protocol(web3Client *web3.Client) *UniswapV2Protocol {
+	return NewUniswapV2ProtocolWithStyle(web3Client, ReserveStyleCombined)
+}
+
+// NewUniswapV2ProtocolWithStyle 创建指定储备量读取方式的 V2 协议适配器
+func NewUniswapV2ProtocolWithStyle(web3Client *web3.Client, reserveStyle string) *UniswapV2Protocol {
+	if reserveStyle == "" {
+		reserveStyle = ReserveStyleCombined
+	}
 	return &UniswapV2Protocol{
-		web3Client: web3Client,
+		web3Client:   web3Client,
+		reserveStyle: reserveStyle,
 	}
 }
 
@@ -35,17 +51,23 @@ func (p *UniswapV2Protocol) GetPairAddress(factory, token0, token1 string, param
 	return pairAddress, nil
 }
 
+// GetPriceAtBlock 获取指定区块的价格信息
+func (p *UniswapV2Protocol) GetPriceAtBlock(pairAddress string, block uint64) (*PriceInfo, error) {
+	pinned := &UniswapV2Protocol{web3Client: p.web3Client.AtBlock(block), reserveStyle: p.reserveStyle}
+	return pinned.GetPrice(pairAddress)
+}
+
 // GetPrice 获取价格信息
 func (p *UniswapV2Protocol) GetPrice(pairAddress string) (*PriceInfo, error) {
 	// 获取储备量
-	reserves, err := p.web3Client.GetPairReserves(pairAddress)
+	reserves, err := p.getReserves(pairAddress)
 	if err != nil {
 		return nil, fmt.Errorf("获取储备量失败: %w", err)
 	}
 
 	// 检查流动性
 	if reserves.Reserve0.Sign() == 0 || reserves.Reserve1.Sign() == 0 {
-		return nil, fmt.Errorf("无流动性")
+		return nil, ErrNoLiquidity
 	}
 
 	// 计算价格
@@ -71,7 +93,7 @@ func (p *UniswapV2Protocol) GetPrice(pairAddress string) (*PriceInfo, error) {
 
 // GetLiquidity 获取流动性信息
 func (p *UniswapV2Protocol) GetLiquidity(pairAddress string) (*LiquidityInfo, error) {
-	reserves, err := p.web3Client.GetPairReserves(pairAddress)
+	reserves, err := p.getReserves(pairAddress)
 	if err != nil {
 		return nil, err
 	}
@@ -85,3 +107,18 @@ func (p *UniswapV2Protocol) GetLiquidity(pairAddress string) (*LiquidityInfo, er
 		Reserve1:  reserves.Reserve1,
 	}, nil
 }
+
+// getReserves 按配置的读取方式获取储备量
+func (p *UniswapV2Protocol) getReserves(pairAddress string) (*web3.PairReserves, error) {
+	reserves, err := p.web3Client.GetPairReserves(pairAddress)
+	if err == nil || p.reserveStyle != ReserveStyleSeparate {
+		return reserves, err
+	}
+
+	// getReserves 调用失败（回滚或返回格式不兼容），回退到单独的 getter
+	separate, sepErr := p.web3Client.GetPairReservesSeparate(pairAddress)
+	if sepErr != nil {
+		return nil, fmt.Errorf("getReserves 失败: %v; reserve0/reserve1 回退也失败: %w", err, sepErr)
+	}
+	return separate, nil
+}
@@ -0,0 +1,137 @@
+package dex
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/defi-bot/backend/pkg/web3"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// mockPair 模拟的 V2 交易对：按方法名返回 uint256 列表，未注册的方法一律回滚
+type mockPair map[string][]*big.Int
+
+// newMockPairRPC 启动只响应 eth_chainId / eth_call 的 JSON-RPC 节点
+func newMockPairRPC(t *testing.T, pair mockPair) *web3.Client {
+	t.Helper()
+
+	selectors := make(map[string]string, len(pair))
+	for method := range pair {
+		selectors[hexutil.Encode(crypto.Keccak256([]byte(method + "()"))[:4])] = method
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "eth_chainId":
+			resp["result"] = "0x1"
+		case "eth_call":
+			var call struct {
+				Input hexutil.Bytes `json:"input"`
+				Data  hexutil.Bytes `json:"data"`
+			}
+			_ = json.Unmarshal(req.Params[0], &call)
+			input := call.Input
+			if len(input) == 0 {
+				input = call.Data
+			}
+
+			method, ok := selectors[hexutil.Encode(input[:4])]
+			if !ok {
+				resp["error"] = map[string]interface{}{"code": 3, "message": "execution reverted"}
+				break
+			}
+			var out []byte
+			for _, v := range pair[method] {
+				out = append(out, common.LeftPadBytes(v.Bytes(), 32)...)
+			}
+			resp["result"] = hexutil.Encode(out)
+		default:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := web3.NewClient(server.URL, 1, 5)
+	if err != nil {
+		t.Fatalf("连接模拟节点失败: %v", err)
+	}
+	return client
+}
+
+func TestUniswapV2GetPriceReserveStyle(t *testing.T) {
+	const pairAddress = "0x0000000000000000000000000000000000000001"
+
+	combinedPair := mockPair{
+		"getReserves": {big.NewInt(1000), big.NewInt(4000), big.NewInt(1700000000)},
+	}
+	// 部分 BSC 分叉没有 getReserves()，只提供 reserve0() / reserve1()
+	separatePair := mockPair{
+		"reserve0": {big.NewInt(2000)},
+		"reserve1": {big.NewInt(500)},
+	}
+
+	tests := []struct {
+		name     string
+		pair     mockPair
+		style    string
+		reserve0 int64
+		reserve1 int64
+		wantErr  bool
+	}{
+		{name: "combined 读取 getReserves", pair: combinedPair, style: ReserveStyleCombined, reserve0: 1000, reserve1: 4000},
+		{name: "separate 优先使用 getReserves", pair: combinedPair, style: ReserveStyleSeparate, reserve0: 1000, reserve1: 4000},
+		{name: "separate 回退到单独 getter", pair: separatePair, style: ReserveStyleSeparate, reserve0: 2000, reserve1: 500},
+		{name: "combined 不回退", pair: separatePair, style: ReserveStyleCombined, wantErr: true},
+		{name: "两种方式都失败", pair: mockPair{}, style: ReserveStyleSeparate, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protocol := NewUniswapV2ProtocolWithStyle(newMockPairRPC(t, tt.pair), tt.style)
+
+			info, err := protocol.GetPrice(pairAddress)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("期望返回错误")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPrice 返回错误: %v", err)
+			}
+
+			if info.Reserve0.Int64() != tt.reserve0 || info.Reserve1.Int64() != tt.reserve1 {
+				t.Errorf("储备量 = (%s, %s), 期望 (%d, %d)", info.Reserve0, info.Reserve1, tt.reserve0, tt.reserve1)
+			}
+			wantPrice := float64(tt.reserve1) / float64(tt.reserve0)
+			if price, _ := info.Price.Float64(); price != wantPrice {
+				t.Errorf("价格 = %g, 期望 %g", price, wantPrice)
+			}
+		})
+	}
+}
+
+func TestNewUniswapV2ProtocolDefaultStyle(t *testing.T) {
+	if got := NewUniswapV2ProtocolWithStyle(nil, "").reserveStyle; got != ReserveStyleCombined {
+		t.Errorf("默认储备量读取方式 = %q, 期望 %q", got, ReserveStyleCombined)
+	}
+}
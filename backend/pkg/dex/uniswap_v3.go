@@ -5,6 +5,8 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/defi-bot/backend/pkg/amm"
+	"github.com/defi-bot/backend/pkg/units"
 	"github.com/defi-bot/backend/pkg/web3"
 )
 
@@ -29,7 +31,7 @@ func (p *UniswapV3Protocol) GetProtocolName() string {
 // params[0] 应该是 fee (uint32): 500, 3000, 10000
 func (p *UniswapV3Protocol) GetPairAddress(factory, token0, token1 string, params ...interface{}) (string, error) {
 	if len(params) == 0 {
-		return "", fmt.Errorf("V3需要指定fee参数")
+		return "", fmt.Errorf("%w: V3需要指定fee参数", ErrInvalidParams)
 	}
 
 	fee, ok := params[0].(uint32)
@@ -38,7 +40,7 @@ func (p *UniswapV3Protocol) GetPairAddress(factory, token0, token1 string, param
 		if feeInt, ok := params[0].(int); ok {
 			fee = uint32(feeInt)
 		} else {
-			return "", fmt.Errorf("fee参数类型错误，应该是uint32")
+			return "", fmt.Errorf("%w: fee参数类型错误，应该是uint32", ErrInvalidParams)
 		}
 	}
 
@@ -50,6 +52,12 @@ func (p *UniswapV3Protocol) GetPairAddress(factory, token0, token1 string, param
 	return poolAddress, nil
 }
 
+// GetPriceAtBlock 获取指定区块的 V3 Pool 价格信息
+func (p *UniswapV3Protocol) GetPriceAtBlock(pairAddress string, block uint64) (*PriceInfo, error) {
+	pinned := &UniswapV3Protocol{web3Client: p.web3Client.AtBlock(block)}
+	return pinned.GetPrice(pairAddress)
+}
+
 // GetPrice 获取 V3 Pool 的价格信息
 func (p *UniswapV3Protocol) GetPrice(pairAddress string) (*PriceInfo, error) {
 	// 获取 slot0（包含当前价格）
@@ -66,11 +74,11 @@ func (p *UniswapV3Protocol) GetPrice(pairAddress string) (*PriceInfo, error) {
 
 	// 检查价格和流动性
 	if slot0.SqrtPriceX96 == nil || slot0.SqrtPriceX96.Sign() == 0 {
-		return nil, fmt.Errorf("无效的价格")
+		return nil, ErrInvalidPrice
 	}
 
 	if liquidity.Sign() == 0 {
-		return nil, fmt.Errorf("无流动性")
+		return nil, ErrNoLiquidity
 	}
 
 	// 转换 sqrtPriceX96 为实际价格
@@ -86,14 +94,14 @@ func (p *UniswapV3Protocol) GetPrice(pairAddress string) (*PriceInfo, error) {
 		Reserve0:     reserve0,
 		Reserve1:     reserve1,
 		Liquidity:    liquidity,
-		
+
 		// === ✅ V3 专用数据 ===
 		SqrtPriceX96:     slot0.SqrtPriceX96,
 		Tick:             slot0.Tick,
 		FeeGrowthGlobal0: big.NewInt(0), // TODO: 从合约获取
 		FeeGrowthGlobal1: big.NewInt(0), // TODO: 从合约获取
-		
-		Timestamp:    time.Now(),
+
+		Timestamp: time.Now(),
 	}, nil
 }
 
@@ -134,8 +142,37 @@ func (p *UniswapV3Protocol) sqrtPriceX96ToPrice(sqrtPriceX96 *big.Int) *big.Floa
 	return price
 }
 
-// CalculateVirtualReserves 根据流动性和价格计算虚拟储备量
-// 这是一个估算，用于与V2保持接口一致
+// SqrtPriceX96ToPriceAdjusted 将 sqrtPriceX96 转换为按精度调整后的价格（token1/token0）
+// 原始价格是链上最小单位之比，需乘以 10^(decimals0-decimals1) 才能与 V2 价格或展示价格比较
+// 例如 USDC(6)/WETH(18) 池子的原始价格与实际价格相差 10^12
+func SqrtPriceX96ToPriceAdjusted(sqrtPriceX96 *big.Int, decimals0, decimals1 int) *big.Float {
+	// price = sqrtPriceX96^2 / 2^192，使用有理数避免中间步骤丢失精度
+	price := amm.SqrtPriceX96ToPrice(sqrtPriceX96)
+
+	// 精度调整：乘以 10^(decimals0-decimals1)
+	diff := decimals0 - decimals1
+	if diff > 0 {
+		price.Mul(price, new(big.Rat).SetInt(units.Pow10(diff)))
+	} else if diff < 0 {
+		price.Quo(price, new(big.Rat).SetInt(units.Pow10(-diff)))
+	}
+
+	return new(big.Float).SetPrec(256).SetRat(price)
+}
+
+// InvertSqrtPriceX96 交换 token0 / token1 后的 sqrtPriceX96：2^192 / sqrtPriceX96（向下取整），0 返回 0
+// 用于把链上顺序的 V3 价格换算到与链上顺序相反的记录顺序
+func InvertSqrtPriceX96(sqrtPriceX96 *big.Int) *big.Int {
+	if sqrtPriceX96.Sign() == 0 {
+		return new(big.Int)
+	}
+	q192 := new(big.Int).Lsh(big.NewInt(1), 192)
+	return q192.Quo(q192, sqrtPriceX96)
+}
+
+// CalculateVirtualReserves 根据流动性和价格计算虚拟储备量（按全区间 V2 池折算的近似值）
+// 只用于与 V2 保持 PriceInfo 接口一致：集中流动性只在当前 tick 区间附近有效，
+// 这里把它当作全价格范围可用，会严重高估可成交深度。评估可成交规模使用 amm.EstimateUsableDepth
 func (p *UniswapV3Protocol) CalculateVirtualReserves(liquidity *big.Int, sqrtPriceX96 *big.Int) (*big.Int, *big.Int) {
 	// 简化计算：
 	// reserve0 ≈ liquidity / sqrtPrice
@@ -0,0 +1,88 @@
+package dex
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+// q96 2^96，sqrtPriceX96 = 2^96 时价格为 1
+var q96 = new(big.Int).Lsh(big.NewInt(1), 96)
+
+func TestSqrtPriceX96ToPriceAdjusted(t *testing.T) {
+	// sqrtPriceX96 = 20000 × 2^96，原始价格 = 20000² = 4×10^8
+	usdcWeth := new(big.Int).Mul(big.NewInt(20000), q96)
+	// sqrtPriceX96 = 2^96 / 20000，原始价格 = 1 / (4×10^8)
+	wethUsdt := new(big.Int).Quo(q96, big.NewInt(20000))
+
+	tests := []struct {
+		name      string
+		sqrtPrice *big.Int
+		decimals0 int
+		decimals1 int
+		want      float64
+	}{
+		{
+			// USDC(6)/WETH(18)：原始价格 4×10^8 ÷ 10^12 = 0.0004 WETH/USDC，即 2500 USDC/WETH
+			name: "USDC/WETH 除以 10^12", sqrtPrice: usdcWeth, decimals0: 6, decimals1: 18, want: 0.0004,
+		},
+		{
+			// WETH(18)/USDT(6)：原始价格 2.5×10^-9 × 10^12 = 2500 USDT/WETH
+			name: "WETH/USDT 乘以 10^12", sqrtPrice: wethUsdt, decimals0: 18, decimals1: 6, want: 2500,
+		},
+		{
+			name: "精度相同不调整", sqrtPrice: usdcWeth, decimals0: 18, decimals1: 18, want: 4e8,
+		},
+		{
+			name: "tick 0 的价格为 1", sqrtPrice: q96, decimals0: 8, decimals1: 8, want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := SqrtPriceX96ToPriceAdjusted(tt.sqrtPrice, tt.decimals0, tt.decimals1).Float64()
+			if math.Abs(got/tt.want-1) > 1e-12 {
+				t.Errorf("SqrtPriceX96ToPriceAdjusted = %g, 期望 %g", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSqrtPriceX96ToPriceAdjustedExact(t *testing.T) {
+	// 缩放在有理数上完成：USDC/WETH 的结果应恰好为 1/2500，而不是浮点近似值
+	sqrtPrice := new(big.Int).Mul(big.NewInt(20000), q96)
+	got := SqrtPriceX96ToPriceAdjusted(sqrtPrice, 6, 18)
+
+	want := new(big.Float).SetPrec(256).SetRat(big.NewRat(1, 2500))
+	if got.Cmp(want) != 0 {
+		t.Errorf("SqrtPriceX96ToPriceAdjusted = %s, 期望 %s", got.Text('g', 40), want.Text('g', 40))
+	}
+}
+
+func TestInvertSqrtPriceX96(t *testing.T) {
+	tests := []struct {
+		name      string
+		sqrtPrice *big.Int
+		want      *big.Int
+	}{
+		{name: "价格为 1", sqrtPrice: q96, want: q96},
+		{name: "取倒数", sqrtPrice: new(big.Int).Mul(big.NewInt(20000), q96), want: new(big.Int).Quo(q96, big.NewInt(20000))},
+		{name: "再次取倒数还原", sqrtPrice: new(big.Int).Quo(q96, big.NewInt(4)), want: new(big.Int).Mul(big.NewInt(4), q96)},
+		{name: "未初始化", sqrtPrice: new(big.Int), want: new(big.Int)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InvertSqrtPriceX96(tt.sqrtPrice); got.Cmp(tt.want) != 0 {
+				t.Errorf("InvertSqrtPriceX96 = %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+
+	// 倒数后按交换后的精度换算，得到原价格的倒数
+	sqrtPrice := new(big.Int).Mul(big.NewInt(20000), q96)
+	price, _ := SqrtPriceX96ToPriceAdjusted(sqrtPrice, 6, 18).Float64()
+	inverse, _ := SqrtPriceX96ToPriceAdjusted(InvertSqrtPriceX96(sqrtPrice), 18, 6).Float64()
+	if math.Abs(price*inverse-1) > 1e-12 {
+		t.Errorf("价格 %g 与倒数价格 %g 的乘积不为 1", price, inverse)
+	}
+}
@@ -0,0 +1,171 @@
+package dex
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/defi-bot/backend/pkg/web3"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// UniswapV4Protocol Uniswap V4 协议适配器
+// V4 所有池子都在 PoolManager 单例合约中，没有独立的池子合约；
+// 交易对地址使用 poolId（32 字节十六进制），价格通过 PoolManager.extsload 读取
+type UniswapV4Protocol struct {
+	web3Client  *web3.Client
+	poolManager string // PoolManager 合约地址
+}
+
+// NewUniswapV4Protocol 创建 Uniswap V4 协议适配器
+func NewUniswapV4Protocol(web3Client *web3.Client, poolManager string) *UniswapV4Protocol {
+	return &UniswapV4Protocol{
+		web3Client:  web3Client,
+		poolManager: poolManager,
+	}
+}
+
+// GetProtocolName 获取协议名称
+func (p *UniswapV4Protocol) GetProtocolName() string {
+	return "uniswap_v4"
+}
+
+// GetPairAddress 计算 poolId 并确认池子已初始化
+// factory 为 PoolManager 地址（为空时使用创建适配器时的地址）
+// params[0] fee (uint32)，params[1] tickSpacing (int32，可选，默认按标准费率层级)，params[2] hooks 地址 (string，可选)
+// 池子未初始化时返回空字符串
+func (p *UniswapV4Protocol) GetPairAddress(factory, token0, token1 string, params ...interface{}) (string, error) {
+	if len(params) == 0 {
+		return "", fmt.Errorf("%w: V4需要指定fee参数", ErrInvalidParams)
+	}
+
+	fee, ok := params[0].(uint32)
+	if !ok {
+		// 尝试从int转换
+		if feeInt, ok := params[0].(int); ok {
+			fee = uint32(feeInt)
+		} else {
+			return "", fmt.Errorf("%w: fee参数类型错误，应该是uint32", ErrInvalidParams)
+		}
+	}
+
+	tickSpacing := web3.DefaultTickSpacing(fee)
+	if len(params) > 1 {
+		switch v := params[1].(type) {
+		case int32:
+			tickSpacing = v
+		case int:
+			tickSpacing = int32(v)
+		default:
+			return "", fmt.Errorf("%w: tickSpacing参数类型错误，应该是int32", ErrInvalidParams)
+		}
+	}
+	if tickSpacing == 0 {
+		return "", fmt.Errorf("%w: 非标准费率 %d 需要指定tickSpacing", ErrInvalidParams, fee)
+	}
+
+	hooks := ""
+	if len(params) > 2 {
+		if hooks, ok = params[2].(string); !ok {
+			return "", fmt.Errorf("%w: hooks参数类型错误，应该是string", ErrInvalidParams)
+		}
+	}
+
+	poolManager := factory
+	if poolManager == "" {
+		poolManager = p.poolManager
+	}
+
+	poolId := web3.NewV4PoolKey(token0, token1, fee, tickSpacing, hooks).ID()
+
+	// sqrtPriceX96 为 0 表示池子未初始化
+	slot0, err := p.web3Client.GetV4Slot0(poolManager, poolId)
+	if err != nil {
+		return "", fmt.Errorf("获取V4 Pool状态失败: %w", err)
+	}
+	if slot0.SqrtPriceX96.Sign() == 0 {
+		return "", nil
+	}
+
+	return common.Hash(poolId).Hex(), nil
+}
+
+// GetPriceAtBlock 获取指定区块的 V4 池子价格信息
+func (p *UniswapV4Protocol) GetPriceAtBlock(pairAddress string, block uint64) (*PriceInfo, error) {
+	pinned := &UniswapV4Protocol{web3Client: p.web3Client.AtBlock(block), poolManager: p.poolManager}
+	return pinned.GetPrice(pairAddress)
+}
+
+// GetPrice 获取 V4 池子的价格信息（pairAddress 为 poolId）
+func (p *UniswapV4Protocol) GetPrice(pairAddress string) (*PriceInfo, error) {
+	slot0, liquidity, err := p.readState(pairAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查价格和流动性
+	if slot0.SqrtPriceX96.Sign() == 0 {
+		return nil, ErrInvalidPrice
+	}
+
+	if liquidity.Sign() == 0 {
+		return nil, ErrNoLiquidity
+	}
+
+	// V4 的价格和流动性语义与 V3 相同
+	v3 := &UniswapV3Protocol{web3Client: p.web3Client}
+	price := v3.sqrtPriceX96ToPrice(slot0.SqrtPriceX96)
+	inversePrice := new(big.Float).Quo(big.NewFloat(1.0), price)
+	reserve0, reserve1 := v3.CalculateVirtualReserves(liquidity, slot0.SqrtPriceX96)
+
+	return &PriceInfo{
+		Price:        price,
+		InversePrice: inversePrice,
+		Reserve0:     reserve0,
+		Reserve1:     reserve1,
+		Liquidity:    liquidity,
+
+		SqrtPriceX96:     slot0.SqrtPriceX96,
+		Tick:             slot0.Tick,
+		Fee:              slot0.LPFee,   // 动态费率池由 hook 更新 slot0 中的 lpFee
+		FeeGrowthGlobal0: big.NewInt(0), // TODO: 从 extsload 读取
+		FeeGrowthGlobal1: big.NewInt(0), // TODO: 从 extsload 读取
+
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// GetLiquidity 获取详细的流动性信息
+func (p *UniswapV4Protocol) GetLiquidity(pairAddress string) (*LiquidityInfo, error) {
+	slot0, liquidity, err := p.readState(pairAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LiquidityInfo{
+		Liquidity:    liquidity,
+		Tick:         slot0.Tick,
+		SqrtPriceX96: slot0.SqrtPriceX96,
+	}, nil
+}
+
+// readState 读取池子的 slot0 和流动性
+func (p *UniswapV4Protocol) readState(pairAddress string) (*web3.V4Slot0, *big.Int, error) {
+	if p.poolManager == "" {
+		return nil, nil, fmt.Errorf("%w: 未配置V4 PoolManager地址", ErrInvalidParams)
+	}
+
+	poolId := common.HexToHash(pairAddress)
+
+	slot0, err := p.web3Client.GetV4Slot0(p.poolManager, poolId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取slot0失败: %w", err)
+	}
+
+	liquidity, err := p.web3Client.GetV4Liquidity(p.poolManager, poolId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取流动性失败: %w", err)
+	}
+
+	return slot0, liquidity, nil
+}
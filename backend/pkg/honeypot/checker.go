@@ -0,0 +1,101 @@
+package honeypot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultAPIURL honeypot.is 检测接口
+const DefaultAPIURL = "https://api.honeypot.is/v2/IsHoneypot"
+
+// Result 检测结果
+type Result struct {
+	IsHoneypot bool   // 是否为蜜罐（可以买入但无法卖出）
+	Reason     string // 判定原因
+}
+
+// Checker 蜜罐代币检测器
+type Checker interface {
+	Check(ctx context.Context, address string) (*Result, error)
+}
+
+// APIChecker 通过外部检测接口（honeypot.is 兼容）判断代币是否为蜜罐
+// 接口会在分叉环境中模拟买入 + 卖出，卖出失败或税率异常时判定为蜜罐
+type APIChecker struct {
+	apiURL  string
+	chainID int64
+	client  *http.Client
+}
+
+// NewAPIChecker 创建蜜罐检测器，apiURL 为空时使用 honeypot.is
+func NewAPIChecker(apiURL string, chainID int64, timeout time.Duration) *APIChecker {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &APIChecker{
+		apiURL:  apiURL,
+		chainID: chainID,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// apiResponse honeypot.is 接口返回（只解析需要的字段）
+type apiResponse struct {
+	SimulationSuccess bool   `json:"simulationSuccess"`
+	SimulationError   string `json:"simulationError"`
+	HoneypotResult    struct {
+		IsHoneypot     bool   `json:"isHoneypot"`
+		HoneypotReason string `json:"honeypotReason"`
+	} `json:"honeypotResult"`
+}
+
+// Check 检测代币是否为蜜罐
+func (c *APIChecker) Check(ctx context.Context, address string) (*Result, error) {
+	query := url.Values{}
+	query.Set("address", address)
+	query.Set("chainID", strconv.FormatInt(c.chainID, 10))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建蜜罐检测请求失败: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求蜜罐检测接口失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("蜜罐检测接口返回状态码 %d", resp.StatusCode)
+	}
+
+	var body apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("解析蜜罐检测结果失败: %w", err)
+	}
+
+	if body.HoneypotResult.IsHoneypot {
+		return &Result{IsHoneypot: true, Reason: body.HoneypotResult.HoneypotReason}, nil
+	}
+
+	// 无法完成买卖模拟（如没有流动性）时保守地视为蜜罐
+	if !body.SimulationSuccess {
+		reason := body.SimulationError
+		if reason == "" {
+			reason = "买卖模拟失败"
+		}
+		return &Result{IsHoneypot: true, Reason: reason}, nil
+	}
+
+	return &Result{}, nil
+}
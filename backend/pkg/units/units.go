@@ -0,0 +1,131 @@
+// Package units 提供精确的代币单位换算（wei / gwei / ether 及任意精度代币）
+// 所有计算使用 big.Int / big.Rat，不经过 float64，避免超过 15 位有效数字时丢失精度
+package units
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// 常用精度
+const (
+	GweiDecimals  = 9
+	EtherDecimals = 18
+)
+
+// Pow10 计算 10^n
+func Pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// ToRat 将链上整数金额按精度换算为有理数
+func ToRat(amount *big.Int, decimals int) *big.Rat {
+	return new(big.Rat).SetFrac(amount, Pow10(decimals))
+}
+
+// ToFloat 将链上整数金额按精度换算为浮点数（有精度损失，用于统计和估算）
+func ToFloat(amount *big.Int, decimals int) float64 {
+	value, _ := ToRat(amount, decimals).Float64()
+	return value
+}
+
+// FromFloat 将代币单位的浮点数换算为链上最小单位（向下取整）
+func FromFloat(amount float64, decimals int) *big.Int {
+	value := new(big.Float).SetPrec(256).SetFloat64(amount)
+	value.Mul(value, new(big.Float).SetInt(Pow10(decimals)))
+	result, _ := value.Int(nil)
+	return result
+}
+
+// FormatUnits 将链上整数金额格式化为十进制字符串（精确，去掉末尾多余的 0）
+// 例如 FormatUnits(1500000, 6) = "1.5"
+func FormatUnits(amount *big.Int, decimals int) string {
+	if amount == nil {
+		return "0"
+	}
+	return FormatRat(ToRat(amount, decimals), decimals)
+}
+
+// FormatUnitsFixed 按固定小数位格式化（四舍五入），用于日志展示
+// 例如 FormatUnitsFixed(23417562313, 9, 2) = "23.42"
+func FormatUnitsFixed(amount *big.Int, decimals, precision int) string {
+	if amount == nil {
+		amount = big.NewInt(0)
+	}
+	return ToRat(amount, decimals).FloatString(precision)
+}
+
+// FormatRat 将有理数格式化为最多 precision 位小数的字符串，去掉末尾多余的 0
+func FormatRat(value *big.Rat, precision int) string {
+	s := value.FloatString(precision)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// ParseUnits 将十进制字符串按精度解析为链上整数金额
+// 小数位数超过精度时返回错误（不做静默截断）
+func ParseUnits(value string, decimals int) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("金额不能为空")
+	}
+
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("金额格式无效: %s", value)
+	}
+
+	rat.Mul(rat, new(big.Rat).SetInt(Pow10(decimals)))
+	if !rat.IsInt() {
+		return nil, fmt.Errorf("金额 %s 超过 %d 位小数精度", value, decimals)
+	}
+
+	return new(big.Int).Set(rat.Num()), nil
+}
+
+// MustParseUnits 同 ParseUnits，解析失败时 panic（仅用于常量）
+func MustParseUnits(value string, decimals int) *big.Int {
+	amount, err := ParseUnits(value, decimals)
+	if err != nil {
+		panic(err)
+	}
+	return amount
+}
+
+// WeiToEther 将 wei 转换为 ether 字符串
+func WeiToEther(wei *big.Int) string {
+	return FormatUnits(wei, EtherDecimals)
+}
+
+// EtherToWei 将 ether 字符串转换为 wei
+func EtherToWei(ether string) (*big.Int, error) {
+	return ParseUnits(ether, EtherDecimals)
+}
+
+// WeiToGwei 将 wei 转换为 gwei 字符串
+func WeiToGwei(wei *big.Int) string {
+	return FormatUnits(wei, GweiDecimals)
+}
+
+// GweiToWei 将 gwei 字符串转换为 wei
+func GweiToWei(gwei string) (*big.Int, error) {
+	return ParseUnits(gwei, GweiDecimals)
+}
+
+// Price 计算成交价格 amountOut / amountIn（均按各自精度换算后）
+// amountIn 为 0 时返回 "0"
+func Price(amountIn, amountOut *big.Int, decimalsIn, decimalsOut int) string {
+	if amountIn == nil || amountIn.Sign() == 0 || amountOut == nil {
+		return "0"
+	}
+
+	price := new(big.Rat).Quo(ToRat(amountOut, decimalsOut), ToRat(amountIn, decimalsIn))
+	return FormatRat(price, EtherDecimals)
+}
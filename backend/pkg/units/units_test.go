@@ -0,0 +1,181 @@
+package units
+
+import (
+	"math/big"
+	"testing"
+)
+
+func mustBig(t *testing.T, s string) *big.Int {
+	t.Helper()
+	value, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("无效的整数: %s", s)
+	}
+	return value
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		decimals int
+		want     string
+	}{
+		{name: "18 位 1 ether", amount: "1000000000000000000", decimals: 18, want: "1"},
+		{name: "18 位 1 wei", amount: "1", decimals: 18, want: "0.000000000000000001"},
+		// 超过 float64 的 15 位有效数字，仍需逐位精确
+		{name: "18 位大额", amount: "123456789012345678901234567", decimals: 18, want: "123456789.012345678901234567"},
+		{name: "8 位 WBTC", amount: "150000000", decimals: 8, want: "1.5"},
+		{name: "8 位 1 satoshi", amount: "1", decimals: 8, want: "0.00000001"},
+		{name: "6 位 USDC", amount: "1500000", decimals: 6, want: "1.5"},
+		{name: "6 位最小单位", amount: "1", decimals: 6, want: "0.000001"},
+		{name: "6 位整数", amount: "42000000", decimals: 6, want: "42"},
+		{name: "负数", amount: "-2500000", decimals: 6, want: "-2.5"},
+		{name: "零", amount: "0", decimals: 18, want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatUnits(mustBig(t, tt.amount), tt.decimals); got != tt.want {
+				t.Errorf("FormatUnits(%s, %d) = %q, 期望 %q", tt.amount, tt.decimals, got, tt.want)
+			}
+		})
+	}
+
+	if got := FormatUnits(nil, 18); got != "0" {
+		t.Errorf("FormatUnits(nil) = %q, 期望 \"0\"", got)
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		decimals int
+		want     string
+		wantErr  bool
+	}{
+		{name: "18 位", value: "1.5", decimals: 18, want: "1500000000000000000"},
+		{name: "18 位最小单位", value: "0.000000000000000001", decimals: 18, want: "1"},
+		{name: "18 位大额", value: "123456789.012345678901234567", decimals: 18, want: "123456789012345678901234567"},
+		{name: "8 位", value: "0.00000001", decimals: 8, want: "1"},
+		{name: "8 位整数", value: "21", decimals: 8, want: "2100000000"},
+		{name: "6 位", value: "1.5", decimals: 6, want: "1500000"},
+		{name: "6 位带空格", value: " 100 ", decimals: 6, want: "100000000"},
+		{name: "6 位超出精度", value: "0.0000001", decimals: 6, wantErr: true},
+		{name: "8 位超出精度", value: "1.000000001", decimals: 8, wantErr: true},
+		{name: "空字符串", value: "", decimals: 18, wantErr: true},
+		{name: "格式无效", value: "1.2.3", decimals: 18, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUnits(tt.value, tt.decimals)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseUnits(%q, %d) = %s, 期望返回错误", tt.value, tt.decimals, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseUnits(%q, %d) 返回错误: %v", tt.value, tt.decimals, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseUnits(%q, %d) = %s, 期望 %s", tt.value, tt.decimals, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFormatRoundTrip(t *testing.T) {
+	for _, decimals := range []int{18, 8, 6} {
+		for _, amount := range []string{"1", "999999", "100000000", "340282366920938463463374607431768211455"} {
+			value := mustBig(t, amount)
+			parsed, err := ParseUnits(FormatUnits(value, decimals), decimals)
+			if err != nil {
+				t.Fatalf("ParseUnits(FormatUnits(%s, %d)) 返回错误: %v", amount, decimals, err)
+			}
+			if parsed.Cmp(value) != 0 {
+				t.Errorf("%d 位精度往返 %s → %s", decimals, amount, parsed)
+			}
+		}
+	}
+}
+
+func TestEtherConversions(t *testing.T) {
+	if got := WeiToEther(mustBig(t, "1234500000000000000")); got != "1.2345" {
+		t.Errorf("WeiToEther = %q, 期望 \"1.2345\"", got)
+	}
+
+	wei, err := EtherToWei("0.05")
+	if err != nil {
+		t.Fatalf("EtherToWei 返回错误: %v", err)
+	}
+	if wei.String() != "50000000000000000" {
+		t.Errorf("EtherToWei(\"0.05\") = %s, 期望 50000000000000000", wei)
+	}
+
+	if _, err := EtherToWei("0.0000000000000000001"); err == nil {
+		t.Error("EtherToWei 超过 18 位小数时应返回错误")
+	}
+}
+
+func TestGweiConversions(t *testing.T) {
+	tests := []struct {
+		wei  string
+		want string
+	}{
+		{wei: "23417562313", want: "23.417562313"},
+		{wei: "1000000000", want: "1"},
+		{wei: "1", want: "0.000000001"},
+	}
+	for _, tt := range tests {
+		if got := WeiToGwei(mustBig(t, tt.wei)); got != tt.want {
+			t.Errorf("WeiToGwei(%s) = %q, 期望 %q", tt.wei, got, tt.want)
+		}
+	}
+
+	wei, err := GweiToWei("1.5")
+	if err != nil {
+		t.Fatalf("GweiToWei 返回错误: %v", err)
+	}
+	if wei.String() != "1500000000" {
+		t.Errorf("GweiToWei(\"1.5\") = %s, 期望 1500000000", wei)
+	}
+}
+
+func TestFormatUnitsFixed(t *testing.T) {
+	tests := []struct {
+		amount    string
+		decimals  int
+		precision int
+		want      string
+	}{
+		{amount: "23417562313", decimals: 9, precision: 2, want: "23.42"},
+		{amount: "1999999", decimals: 6, precision: 2, want: "2.00"},
+		{amount: "12345678", decimals: 8, precision: 4, want: "0.1235"},
+	}
+	for _, tt := range tests {
+		if got := FormatUnitsFixed(mustBig(t, tt.amount), tt.decimals, tt.precision); got != tt.want {
+			t.Errorf("FormatUnitsFixed(%s, %d, %d) = %q, 期望 %q", tt.amount, tt.decimals, tt.precision, got, tt.want)
+		}
+	}
+}
+
+func TestPrice(t *testing.T) {
+	// 1 WETH（18 位）换 2500.5 USDC（6 位）
+	got := Price(mustBig(t, "1000000000000000000"), mustBig(t, "2500500000"), 18, 6)
+	if got != "2500.5" {
+		t.Errorf("Price = %q, 期望 \"2500.5\"", got)
+	}
+
+	// 0.5 WBTC（8 位）换 10 WETH（18 位）
+	got = Price(mustBig(t, "50000000"), mustBig(t, "10000000000000000000"), 8, 18)
+	if got != "20" {
+		t.Errorf("Price = %q, 期望 \"20\"", got)
+	}
+
+	if got := Price(big.NewInt(0), big.NewInt(1), 18, 6); got != "0" {
+		t.Errorf("amountIn 为 0 时 Price = %q, 期望 \"0\"", got)
+	}
+}
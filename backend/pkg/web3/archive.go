@@ -0,0 +1,29 @@
+package web3
+
+import (
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// SetArchivePool 设置归档 / 重查询节点池
+// 配置后 Quoter 模拟、固定区块读取（AtBlock）和历史事件查询走归档节点，
+// 区块号、储备量等轻量轮询仍使用主节点；需要在创建副本（WithContext / AtBlock）之前调用
+func (c *Client) SetArchivePool(pool *ClientPool) {
+	c.archive = pool
+}
+
+// archiveConn 重查询使用的连接：配置了归档节点池时轮询取一个归档节点，否则使用主节点
+func (c *Client) archiveConn() *connection {
+	if c.archive == nil {
+		return c.conn
+	}
+	node := c.archive.GetClient()
+	if node == nil {
+		return c.conn
+	}
+	return node.conn
+}
+
+// heavyEth 重查询使用的 ethclient（Quoter 模拟、历史事件等）
+func (c *Client) heavyEth() *ethclient.Client {
+	return c.archiveConn().get()
+}
@@ -0,0 +1,115 @@
+package web3
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BatchCall 在一个 HTTP 请求中发送多个 JSON-RPC 调用
+// 返回的 error 只表示整个批量请求失败，单个调用的错误记录在各自的 BatchElem.Error 中
+func (c *Client) BatchCall(ctx context.Context, reqs []rpc.BatchElem) error {
+	if len(reqs) == 0 {
+		return nil
+	}
+	return c.eth().Client().BatchCallContext(ctx, reqs)
+}
+
+// contractCall 批量合约调用中的单个只读调用
+type contractCall struct {
+	method string
+	args   []interface{}
+	out    []interface{} // 解码后的返回值
+}
+
+// batchContractCalls 将同一合约的多个只读调用合并为一个批量请求，并按 ABI 解码结果
+func (c *Client) batchContractCalls(address string, parsedABI abi.ABI, calls []*contractCall) error {
+	to := common.HexToAddress(address)
+
+	reqs := make([]rpc.BatchElem, len(calls))
+	results := make([]hexutil.Bytes, len(calls))
+	for i, call := range calls {
+		data, err := parsedABI.Pack(call.method, call.args...)
+		if err != nil {
+			return fmt.Errorf("编码 %s 调用失败: %w", call.method, err)
+		}
+
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{
+				map[string]interface{}{"to": to, "data": hexutil.Bytes(data)},
+				c.blockTag(),
+			},
+			Result: &results[i],
+		}
+	}
+
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	if err := c.BatchCall(ctx, reqs); err != nil {
+		return fmt.Errorf("批量调用失败: %w", err)
+	}
+
+	for i, call := range calls {
+		if reqs[i].Error != nil {
+			return fmt.Errorf("调用 %s 失败: %w", call.method, reqs[i].Error)
+		}
+
+		out, err := parsedABI.Unpack(call.method, results[i])
+		if err != nil {
+			return fmt.Errorf("解码 %s 返回值失败: %w", call.method, err)
+		}
+		call.out = out
+	}
+
+	return nil
+}
+
+// V3PoolState V3 Pool 初始化所需的全部状态
+type V3PoolState struct {
+	Token0       string
+	Token1       string
+	Fee          uint32
+	TickSpacing  int32
+	SqrtPriceX96 *big.Int
+	Tick         int32
+	Liquidity    *big.Int
+}
+
+// GetV3PoolState 批量读取 V3 Pool 的 token0、token1、fee、tickSpacing、slot0、liquidity
+// 6 个 eth_call 合并为一个 HTTP 请求，用于发现/初始化池子
+func (c *Client) GetV3PoolState(poolAddress string) (*V3PoolState, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(UniswapV3PoolABI))
+	if err != nil {
+		return nil, err
+	}
+
+	token0 := &contractCall{method: "token0"}
+	token1 := &contractCall{method: "token1"}
+	fee := &contractCall{method: "fee"}
+	tickSpacing := &contractCall{method: "tickSpacing"}
+	slot0 := &contractCall{method: "slot0"}
+	liquidity := &contractCall{method: "liquidity"}
+
+	calls := []*contractCall{token0, token1, fee, tickSpacing, slot0, liquidity}
+	if err := c.batchContractCalls(poolAddress, parsedABI, calls); err != nil {
+		return nil, err
+	}
+
+	return &V3PoolState{
+		Token0:       token0.out[0].(common.Address).Hex(),
+		Token1:       token1.out[0].(common.Address).Hex(),
+		Fee:          uint32(fee.out[0].(*big.Int).Uint64()),
+		TickSpacing:  int32(tickSpacing.out[0].(*big.Int).Int64()),
+		SqrtPriceX96: slot0.out[0].(*big.Int),
+		Tick:         int32(slot0.out[1].(*big.Int).Int64()),
+		Liquidity:    liquidity.out[0].(*big.Int),
+	}, nil
+}
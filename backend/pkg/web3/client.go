@@ -5,26 +5,58 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 // Client Web3 客户端
 type Client struct {
-	client  *ethclient.Client
-	chainID *big.Int
-	timeout time.Duration
+	conn        *connection // 底层连接（重连时替换，WithContext 副本共享）
+	chainID     *big.Int
+	callTimeout time.Duration   // 单次调用超时
+	ctx         context.Context // 调用方传入的上下文（默认 context.Background）
+	blockNumber *big.Int        // 只读调用固定读取的区块（为空表示最新区块）
+	signer      Signer          // 交易签名器（只读客户端为空）
+	stats       *rpcStats       // RPC 调用统计（非 HTTP 节点为空）
+	quotes      *quoteCache     // Quoter 查询缓存（未启用时为空）
+	archive     *ClientPool     // 归档 / 重查询节点池（未配置时为空，所有调用走主节点）
 }
 
-// NewClient 创建新的 Web3 客户端
+// ClientOptions 客户端选项
+type ClientOptions struct {
+	DialTimeout time.Duration // 连接超时（包含连接后的 ChainID 校验）
+	CallTimeout time.Duration // 单次 RPC 调用超时
+	MaxRPS      float64       // 每秒最大请求数（0 表示不限流，仅对 HTTP(S) 节点生效）
+
+	KeepaliveInterval      time.Duration // 连接保活检查间隔（0 表示不检查）
+	ReconnectAfterFailures int           // 保活检查连续失败多少次后重新连接（默认 3）
+
+	QuoteCacheTTL time.Duration // Quoter 查询结果缓存时长（0 表示不缓存）
+}
+
+// NewClient 创建新的 Web3 客户端（连接和调用使用相同的超时时间，单位秒）
 func NewClient(rpcURL string, chainID int64, timeout int) (*Client, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	return NewClientWithOptions(rpcURL, chainID, ClientOptions{
+		DialTimeout: time.Duration(timeout) * time.Second,
+		CallTimeout: time.Duration(timeout) * time.Second,
+	})
+}
+
+// NewClientWithOptions 使用独立的连接超时和调用超时创建 Web3 客户端
+func NewClientWithOptions(rpcURL string, chainID int64, opts ClientOptions) (*Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.DialTimeout)
 	defer cancel()
 
-	client, err := ethclient.DialContext(ctx, rpcURL)
+	stats := newRPCStats()
+	client, err := dialClient(ctx, rpcURL, opts.MaxRPS, stats)
 	if err != nil {
 		return nil, fmt.Errorf("连接 RPC 失败: %w", err)
 	}
@@ -37,16 +69,100 @@ func NewClient(rpcURL string, chainID int64, timeout int) (*Client, error) {
 
 	log.Printf("Web3 客户端连接成功: %s (ChainID: %d)", rpcURL, chainID)
 
-	return &Client{
-		client:  client,
-		chainID: big.NewInt(chainID),
-		timeout: time.Duration(timeout) * time.Second,
-	}, nil
+	c := &Client{
+		conn:        newConnection(client, rpcURL, opts),
+		chainID:     big.NewInt(chainID),
+		callTimeout: opts.CallTimeout,
+		ctx:         context.Background(),
+		stats:       stats,
+	}
+	if opts.QuoteCacheTTL > 0 {
+		c.quotes = newQuoteCache(opts.QuoteCacheTTL)
+	}
+
+	if opts.KeepaliveInterval > 0 {
+		go c.keepalive(opts.KeepaliveInterval, opts.ReconnectAfterFailures)
+	}
+
+	return c, nil
+}
+
+// dialClient 连接 RPC 节点
+// HTTP(S) 节点的请求经过调用统计，配置了 maxRPS 时在统计外层再加上限流，
+// 记录的延迟只包含实际的网络往返，不包含等待限流令牌的时间
+func dialClient(ctx context.Context, rpcURL string, maxRPS float64, stats *rpcStats) (*ethclient.Client, error) {
+	if !strings.HasPrefix(rpcURL, "http://") && !strings.HasPrefix(rpcURL, "https://") {
+		if maxRPS > 0 {
+			log.Printf("⚠️  RPC 限流只支持 HTTP(S) 节点，%s 将不限流", rpcURL)
+		}
+		return ethclient.DialContext(ctx, rpcURL)
+	}
+
+	var transport http.RoundTripper = &statsTransport{base: http.DefaultTransport, stats: stats}
+	if maxRPS > 0 {
+		transport = newRateLimitedTransport(transport, maxRPS)
+		log.Printf("RPC 限流: %s 最多 %.1f 次/秒", rpcURL, maxRPS)
+	}
+
+	httpClient := &http.Client{Transport: transport}
+	rpcClient, err := rpc.DialOptions(ctx, rpcURL, rpc.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, err
+	}
+
+	return ethclient.NewClient(rpcClient), nil
+}
+
+// WithContext 返回使用指定上下文的客户端副本（共享底层连接）
+// 若 ctx 带有截止时间，则以其为准，不再叠加默认的调用超时
+func (c *Client) WithContext(ctx context.Context) *Client {
+	copied := *c
+	copied.ctx = ctx
+	return &copied
+}
+
+// AtBlock 返回在指定区块状态上执行只读调用的客户端副本
+// 用于把多个池子的读取固定在同一区块，得到一致的快照；节点需要保留该区块的状态。
+// 配置了归档节点池时副本固定使用其中一个归档节点，否则共享主节点连接
+func (c *Client) AtBlock(block uint64) *Client {
+	copied := *c
+	copied.conn = c.archiveConn()
+	copied.archive = nil // 同一快照的所有读取（包括 Quoter）都走这个节点
+	copied.blockNumber = new(big.Int).SetUint64(block)
+	return &copied
+}
+
+// blockTag 只读调用的区块参数（JSON-RPC 格式）
+func (c *Client) blockTag() string {
+	if c.blockNumber == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(c.blockNumber)
+}
+
+// callContext 为单次调用创建上下文
+func (c *Client) callContext() (context.Context, context.CancelFunc) {
+	return c.withCallTimeout(c.ctx)
+}
+
+// withCallTimeout 为调用方传入的上下文加上默认调用超时（已有截止时间时以其为准）
+func (c *Client) withCallTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.callTimeout)
+}
+
+// callOpts 为单次合约调用创建调用选项
+func (c *Client) callOpts() (*bind.CallOpts, context.CancelFunc) {
+	ctx, cancel := c.callContext()
+	return &bind.CallOpts{Context: ctx, BlockNumber: c.blockNumber}, cancel
 }
 
 // GetClient 获取原始客户端
+// 重连后会返回新的连接，调用方不要长期持有
 func (c *Client) GetClient() *ethclient.Client {
-	return c.client
+	return c.eth()
 }
 
 // GetChainID 获取链 ID
@@ -56,10 +172,10 @@ func (c *Client) GetChainID() *big.Int {
 
 // GetBlockNumber 获取当前区块号
 func (c *Client) GetBlockNumber() (uint64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	ctx, cancel := c.callContext()
 	defer cancel()
 
-	blockNumber, err := c.client.BlockNumber(ctx)
+	blockNumber, err := c.eth().BlockNumber(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("获取区块号失败: %w", err)
 	}
@@ -67,18 +183,43 @@ func (c *Client) GetBlockNumber() (uint64, error) {
 	return blockNumber, nil
 }
 
+// GetLatestHeader 获取最新区块头（区块号、哈希、时间戳一次取回）
+func (c *Client) GetLatestHeader() (*types.Header, error) {
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	header, err := c.eth().HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块头失败: %w", err)
+	}
+
+	return header, nil
+}
+
+// GetBlockHash 获取指定区块号在当前规范链上的区块哈希
+func (c *Client) GetBlockHash(blockNumber uint64) (common.Hash, error) {
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	header, err := c.eth().HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("获取区块 %d 的区块头失败: %w", blockNumber, err)
+	}
+
+	return header.Hash(), nil
+}
+
 // GetCallOpts 获取调用选项
 func (c *Client) GetCallOpts() *bind.CallOpts {
 	return &bind.CallOpts{
-		Context: context.Background(),
+		Context:     c.ctx,
+		BlockNumber: c.blockNumber,
 	}
 }
 
-// Close 关闭客户端
+// Close 关闭客户端（同时停止保活检查）
 func (c *Client) Close() {
-	if c.client != nil {
-		c.client.Close()
-	}
+	c.conn.close()
 }
 
 // IsValidAddress 检查地址是否有效
@@ -2,12 +2,16 @@ package web3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 )
 
+// ErrNoAvailableClient RPC 池中没有可用的客户端
+var ErrNoAvailableClient = errors.New("没有可用的 RPC 客户端")
+
 // ClientPool RPC 客户端池
 // 支持多个 RPC 节点的负载均衡和故障转移
 type ClientPool struct {
@@ -17,15 +21,28 @@ type ClientPool struct {
 	healthCheck bool // 是否启用健康检查
 	checkTicker *time.Ticker
 	stopCh      chan struct{}
+
+	reconnectAfter time.Duration         // 节点持续不健康超过该时长后重新连接
+	unhealthySince map[*Client]time.Time // 节点开始不健康的时间（只在健康检查协程中访问）
+
+	maxBlockLag uint64           // 落后池内最高区块超过该值的节点视为不健康（0 表示不检查）
+	excluded    map[*Client]bool // 最近一次健康检查判定不可用的节点（GetClient 跳过）
 }
 
 // ClientPoolConfig 客户端池配置
 type ClientPoolConfig struct {
-	RPCURLs       []string      // RPC 节点列表
-	ChainID       int64         // 链 ID
-	Timeout       int           // 超时时间（秒）
-	HealthCheck   bool          // 是否启用健康检查
-	CheckInterval time.Duration // 健康检查间隔
+	RPCURLs       []string           // RPC 节点列表
+	ChainID       int64              // 链 ID
+	Timeout       int                // 超时时间（秒）
+	DialTimeout   time.Duration      // 连接超时（为 0 时使用 Timeout）
+	CallTimeout   time.Duration      // 单次调用超时（为 0 时使用 Timeout）
+	MaxRPS        float64            // 每个节点默认的每秒最大请求数（0 表示不限流）
+	EndpointRPS   map[string]float64 // 按节点覆盖的限流值（key 为 RPC URL），便于混用付费节点和免费节点
+	HealthCheck   bool               // 是否启用健康检查
+	CheckInterval time.Duration      // 健康检查间隔
+
+	ReconnectAfter time.Duration // 节点持续不健康超过该时长后重新连接（为 0 时使用 3 个检查周期）
+	MaxBlockLag    uint64        // 区块高度落后池内最高节点超过该值时视为不健康（0 表示不检查，需启用健康检查）
 }
 
 // NewClientPool 创建客户端池
@@ -39,11 +56,37 @@ func NewClientPool(config *ClientPoolConfig) (*ClientPool, error) {
 		currentIdx:  0,
 		healthCheck: config.HealthCheck,
 		stopCh:      make(chan struct{}),
+
+		reconnectAfter: config.ReconnectAfter,
+		unhealthySince: make(map[*Client]time.Time),
+
+		maxBlockLag: config.MaxBlockLag,
+		excluded:    make(map[*Client]bool),
+	}
+	if pool.reconnectAfter <= 0 {
+		pool.reconnectAfter = 3 * config.CheckInterval
+	}
+
+	opts := ClientOptions{
+		DialTimeout: config.DialTimeout,
+		CallTimeout: config.CallTimeout,
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = time.Duration(config.Timeout) * time.Second
+	}
+	if opts.CallTimeout <= 0 {
+		opts.CallTimeout = time.Duration(config.Timeout) * time.Second
 	}
 
 	// 创建所有客户端
 	for i, rpcURL := range config.RPCURLs {
-		client, err := NewClient(rpcURL, config.ChainID, config.Timeout)
+		endpointOpts := opts
+		endpointOpts.MaxRPS = config.MaxRPS
+		if rps, ok := config.EndpointRPS[rpcURL]; ok {
+			endpointOpts.MaxRPS = rps
+		}
+
+		client, err := NewClientWithOptions(rpcURL, config.ChainID, endpointOpts)
 		if err != nil {
 			log.Printf("⚠️  创建客户端失败 [%d/%d] %s: %v", i+1, len(config.RPCURLs), rpcURL, err)
 			continue
@@ -53,7 +96,7 @@ func NewClientPool(config *ClientPoolConfig) (*ClientPool, error) {
 	}
 
 	if len(pool.clients) == 0 {
-		return nil, fmt.Errorf("没有可用的 RPC 客户端")
+		return nil, ErrNoAvailableClient
 	}
 
 	// 启动健康检查
@@ -67,6 +110,7 @@ func NewClientPool(config *ClientPoolConfig) (*ClientPool, error) {
 }
 
 // GetClient 获取一个可用的客户端（轮询）
+// 跳过最近一次健康检查判定为不可用（无响应或区块落后）的节点；全部不可用时仍按轮询返回
 func (p *ClientPool) GetClient() *Client {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -76,9 +120,16 @@ func (p *ClientPool) GetClient() *Client {
 	}
 
 	// 轮询获取下一个客户端
+	for i := 0; i < len(p.clients); i++ {
+		client := p.clients[p.currentIdx]
+		p.currentIdx = (p.currentIdx + 1) % len(p.clients)
+		if !p.excluded[client] {
+			return client
+		}
+	}
+
 	client := p.clients[p.currentIdx]
 	p.currentIdx = (p.currentIdx + 1) % len(p.clients)
-
 	return client
 }
 
@@ -90,7 +141,7 @@ func (p *ClientPool) GetClientWithRetry(ctx context.Context, maxRetries int) (*C
 	p.mu.RUnlock()
 
 	if clientCount == 0 {
-		return nil, fmt.Errorf("没有可用的 RPC 客户端")
+		return nil, ErrNoAvailableClient
 	}
 
 	// 限制重试次数不超过客户端数量
@@ -102,7 +153,7 @@ func (p *ClientPool) GetClientWithRetry(ctx context.Context, maxRetries int) (*C
 	for i := 0; i < maxRetries; i++ {
 		client := p.GetClient()
 		if client == nil {
-			return nil, fmt.Errorf("无法获取客户端")
+			return nil, ErrNoAvailableClient
 		}
 
 		// 测试客户端是否可用（获取区块号）
@@ -125,7 +176,7 @@ func (p *ClientPool) ExecuteWithRetry(ctx context.Context, operation func(*Clien
 	p.mu.RUnlock()
 
 	if clientCount == 0 {
-		return fmt.Errorf("没有可用的 RPC 客户端")
+		return ErrNoAvailableClient
 	}
 
 	// 限制重试次数
@@ -143,7 +194,7 @@ func (p *ClientPool) ExecuteWithRetry(ctx context.Context, operation func(*Clien
 
 		client := p.GetClient()
 		if client == nil {
-			return fmt.Errorf("无法获取客户端")
+			return ErrNoAvailableClient
 		}
 
 		err := operation(client)
@@ -175,22 +226,67 @@ func (p *ClientPool) startHealthCheck() {
 }
 
 // checkHealth 检查所有客户端的健康状态
+// 持续不健康超过 reconnectAfter 的节点会重新连接，而不是一直报错；
+// 配置了 maxBlockLag 时，能响应但区块高度落后池内最高节点过多的节点（同步滞后或分叉）也会被排除
 func (p *ClientPool) checkHealth() {
 	p.mu.RLock()
 	clients := make([]*Client, len(p.clients))
 	copy(clients, p.clients)
 	p.mu.RUnlock()
 
+	excluded := make(map[*Client]bool)
+	heights := make(map[*Client]uint64, len(clients))
+	var maxHeight uint64
+
 	healthyCount := 0
 	for i, client := range clients {
-		_, err := client.GetBlockNumber()
+		height, err := client.GetBlockNumber()
 		if err == nil {
 			healthyCount++
-		} else {
-			log.Printf("⚠️  RPC 节点 [%d/%d] 不健康: %v", i+1, len(clients), err)
+			delete(p.unhealthySince, client)
+			heights[client] = height
+			maxHeight = max(maxHeight, height)
+			continue
+		}
+
+		log.Printf("⚠️  RPC 节点 [%d/%d] 不健康: %v", i+1, len(clients), err)
+		excluded[client] = true
+
+		since, ok := p.unhealthySince[client]
+		if !ok {
+			p.unhealthySince[client] = time.Now()
+			continue
+		}
+		if time.Since(since) < p.reconnectAfter {
+			continue
+		}
+
+		if err := client.Reconnect(); err != nil {
+			log.Printf("❌ RPC 节点 [%d/%d] %v", i+1, len(clients), err)
+			continue
+		}
+		delete(p.unhealthySince, client)
+		delete(excluded, client)
+		healthyCount++
+	}
+
+	if p.maxBlockLag > 0 {
+		for i, client := range clients {
+			height, ok := heights[client]
+			if !ok || maxHeight-height <= p.maxBlockLag {
+				continue
+			}
+			log.Printf("⚠️  RPC 节点 [%d/%d] 区块 %d 落后最高区块 %d 共 %d 个，暂不使用",
+				i+1, len(clients), height, maxHeight, maxHeight-height)
+			excluded[client] = true
+			healthyCount--
 		}
 	}
 
+	p.mu.Lock()
+	p.excluded = excluded
+	p.mu.Unlock()
+
 	if healthyCount == 0 {
 		log.Println("❌ 所有 RPC 节点都不可用！")
 	} else {
@@ -1,7 +1,6 @@
 package web3
 
 import (
-	"context"
 	"fmt"
 	"math/big"
 	"strings"
@@ -40,6 +39,22 @@ const UniswapV2PairABI = `[
 		"stateMutability": "view",
 		"type": "function"
 	},
+	{
+		"constant": true,
+		"inputs": [],
+		"name": "reserve0",
+		"outputs": [{"name": "", "type": "uint256"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"constant": true,
+		"inputs": [],
+		"name": "reserve1",
+		"outputs": [{"name": "", "type": "uint256"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
 	{
 		"constant": true,
 		"inputs": [],
@@ -77,7 +92,7 @@ func (c *Client) GetPairFromFactory(factoryAddress, token0Address, token1Address
 	}
 
 	// 调用合约
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	ctx, cancel := c.callContext()
 	defer cancel()
 
 	msg := ethereum.CallMsg{
@@ -85,7 +100,7 @@ func (c *Client) GetPairFromFactory(factoryAddress, token0Address, token1Address
 		Data: data,
 	}
 
-	result, err := c.client.CallContract(ctx, msg, nil)
+	result, err := c.eth().CallContract(ctx, msg, c.blockNumber)
 	if err != nil {
 		return "", fmt.Errorf("调用 Factory.getPair 失败: %w", err)
 	}
@@ -127,7 +142,7 @@ func (c *Client) GetPairReservesFromContract(pairAddress string) (*PairReserves,
 	}
 
 	// 调用合约
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	ctx, cancel := c.callContext()
 	defer cancel()
 
 	msg := ethereum.CallMsg{
@@ -135,7 +150,7 @@ func (c *Client) GetPairReservesFromContract(pairAddress string) (*PairReserves,
 		Data: data,
 	}
 
-	result, err := c.client.CallContract(ctx, msg, nil)
+	result, err := c.eth().CallContract(ctx, msg, c.blockNumber)
 	if err != nil {
 		return nil, fmt.Errorf("调用 Pair.getReserves 失败: %w", err)
 	}
@@ -159,6 +174,49 @@ func (c *Client) GetPairReservesFromContract(pairAddress string) (*PairReserves,
 	}, nil
 }
 
+// GetPairReservesSeparate 通过 reserve0() / reserve1() 分别读取储备量
+// 用于不提供 getReserves 或返回格式不同的 V2 分叉（如部分 BSC DEX）
+func (c *Client) GetPairReservesSeparate(pairAddress string) (*PairReserves, error) {
+	// 解析 ABI
+	parsedABI, err := abi.JSON(strings.NewReader(UniswapV2PairABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析 Pair ABI 失败: %w", err)
+	}
+
+	reserves := make([]*big.Int, 2)
+	for i, method := range []string{"reserve0", "reserve1"} {
+		// 打包调用数据
+		data, err := parsedABI.Pack(method)
+		if err != nil {
+			return nil, fmt.Errorf("打包调用数据失败: %w", err)
+		}
+
+		// 调用合约
+		ctx, cancel := c.callContext()
+		msg := ethereum.CallMsg{
+			To:   &[]common.Address{common.HexToAddress(pairAddress)}[0],
+			Data: data,
+		}
+		result, err := c.eth().CallContract(ctx, msg, c.blockNumber)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("调用 Pair.%s 失败: %w", method, err)
+		}
+
+		// 解析返回值
+		var reserve *big.Int
+		if err := parsedABI.UnpackIntoInterface(&reserve, method, result); err != nil {
+			return nil, fmt.Errorf("解析储备量失败: %w", err)
+		}
+		reserves[i] = reserve
+	}
+
+	return &PairReserves{
+		Reserve0: reserves[0],
+		Reserve1: reserves[1],
+	}, nil
+}
+
 // GetTokenFromPair 从 Pair 合约获取 token0 或 token1 地址
 func (c *Client) GetTokenFromPair(pairAddress string, tokenIndex int) (string, error) {
 	// 解析 ABI
@@ -180,7 +238,7 @@ func (c *Client) GetTokenFromPair(pairAddress string, tokenIndex int) (string, e
 	}
 
 	// 调用合约
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	ctx, cancel := c.callContext()
 	defer cancel()
 
 	msg := ethereum.CallMsg{
@@ -188,7 +246,7 @@ func (c *Client) GetTokenFromPair(pairAddress string, tokenIndex int) (string, e
 		Data: data,
 	}
 
-	result, err := c.client.CallContract(ctx, msg, nil)
+	result, err := c.eth().CallContract(ctx, msg, c.blockNumber)
 	if err != nil {
 		return "", fmt.Errorf("调用 Pair.%s 失败: %w", method, err)
 	}
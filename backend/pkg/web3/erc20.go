@@ -0,0 +1,117 @@
+package web3
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ERC20 ABI（精简版，只包含元数据和总供应量方法）
+const ERC20ABI = `[
+	{
+		"inputs": [],
+		"name": "symbol",
+		"outputs": [{"name": "", "type": "string"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "name",
+		"outputs": [{"name": "", "type": "string"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "decimals",
+		"outputs": [{"name": "", "type": "uint8"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "totalSupply",
+		"outputs": [{"name": "", "type": "uint256"}],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// TokenMetadata ERC20 代币元数据
+type TokenMetadata struct {
+	Address  string
+	Symbol   string
+	Name     string
+	Decimals int
+}
+
+// GetTokenMetadata 从 ERC20 合约读取 symbol / name / decimals
+func (c *Client) GetTokenMetadata(tokenAddress string) (*TokenMetadata, error) {
+	tokenAddr := common.HexToAddress(tokenAddress)
+
+	// 解析 ABI
+	parsedABI, err := abi.JSON(strings.NewReader(ERC20ABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析 ERC20 ABI 失败: %w", err)
+	}
+
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	// 创建绑定
+	contract := bind.NewBoundContract(tokenAddr, parsedABI, c.eth(), nil, nil)
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: c.blockNumber}
+
+	// decimals 是必需的，失败直接返回
+	var outDecimals []interface{}
+	if err := contract.Call(opts, &outDecimals, "decimals"); err != nil {
+		return nil, fmt.Errorf("调用 ERC20.decimals 失败: %w", err)
+	}
+
+	metadata := &TokenMetadata{
+		Address:  tokenAddr.Hex(),
+		Decimals: int(outDecimals[0].(uint8)),
+	}
+
+	// symbol / name 不是 ERC20 强制要求（部分老代币返回 bytes32），失败时留空
+	var outSymbol []interface{}
+	if err := contract.Call(opts, &outSymbol, "symbol"); err == nil {
+		metadata.Symbol = outSymbol[0].(string)
+	}
+
+	var outName []interface{}
+	if err := contract.Call(opts, &outName, "name"); err == nil {
+		metadata.Name = outName[0].(string)
+	}
+
+	return metadata, nil
+}
+
+// GetTokenTotalSupply 读取 ERC20 代币的总供应量（最小单位）
+func (c *Client) GetTokenTotalSupply(tokenAddress string) (*big.Int, error) {
+	tokenAddr := common.HexToAddress(tokenAddress)
+
+	// 解析 ABI
+	parsedABI, err := abi.JSON(strings.NewReader(ERC20ABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析 ERC20 ABI 失败: %w", err)
+	}
+
+	// 创建绑定
+	contract := bind.NewBoundContract(tokenAddr, parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	var out []interface{}
+	if err := contract.Call(opts, &out, "totalSupply"); err != nil {
+		return nil, fmt.Errorf("调用 ERC20.totalSupply 失败: %w", err)
+	}
+
+	return out[0].(*big.Int), nil
+}
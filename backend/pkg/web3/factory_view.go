@@ -0,0 +1,42 @@
+package web3
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FactoryViewABI 只包含 factory() 的 ABI
+// Uniswap V2/V3 Router、V3 Quoter、Algebra Quoter、Kyber Router 等周边合约都通过该方法暴露所属的 Factory
+const FactoryViewABI = `[
+	{
+		"inputs": [],
+		"name": "factory",
+		"outputs": [{"name": "", "type": "address"}],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// GetFactoryOf 读取周边合约（Router / Quoter）绑定的 Factory 地址
+func (c *Client) GetFactoryOf(contractAddress string) (string, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(FactoryViewABI))
+	if err != nil {
+		return "", fmt.Errorf("解析 factory() ABI 失败: %w", err)
+	}
+
+	contract := bind.NewBoundContract(common.HexToAddress(contractAddress), parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	var out []interface{}
+	if err := contract.Call(opts, &out, "factory"); err != nil {
+		return "", fmt.Errorf("调用 %s.factory() 失败: %w", contractAddress, err)
+	}
+
+	return out[0].(common.Address).Hex(), nil
+}
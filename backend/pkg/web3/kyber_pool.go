@@ -0,0 +1,269 @@
+package web3
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// KyberSwap Classic (DMM) Factory ABI
+// 同一代币对可以有多个不同放大系数（amp）的池子
+const KyberClassicFactoryABI = `[
+	{
+		"inputs": [
+			{"name": "token0", "type": "address"},
+			{"name": "token1", "type": "address"}
+		],
+		"name": "getPools",
+		"outputs": [{"name": "_tokenPools", "type": "address[]"}],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// KyberSwap Classic (DMM) Pool ABI
+const KyberClassicPoolABI = `[
+	{
+		"inputs": [],
+		"name": "getTradeInfo",
+		"outputs": [
+			{"name": "_reserve0", "type": "uint112"},
+			{"name": "_reserve1", "type": "uint112"},
+			{"name": "_vReserve0", "type": "uint112"},
+			{"name": "_vReserve1", "type": "uint112"},
+			{"name": "feeInPrecision", "type": "uint256"}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// KyberSwap Elastic Factory ABI
+const KyberElasticFactoryABI = `[
+	{
+		"inputs": [
+			{"name": "tokenA", "type": "address"},
+			{"name": "tokenB", "type": "address"},
+			{"name": "swapFeeUnits", "type": "uint24"}
+		],
+		"name": "getPool",
+		"outputs": [{"name": "pool", "type": "address"}],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// KyberSwap Elastic Pool ABI
+const KyberElasticPoolABI = `[
+	{
+		"inputs": [],
+		"name": "getPoolState",
+		"outputs": [
+			{"name": "sqrtP", "type": "uint160"},
+			{"name": "currentTick", "type": "int24"},
+			{"name": "nearestCurrentTick", "type": "int24"},
+			{"name": "locked", "type": "bool"}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "getLiquidityState",
+		"outputs": [
+			{"name": "baseL", "type": "uint128"},
+			{"name": "reinvestL", "type": "uint128"},
+			{"name": "reinvestLLast", "type": "uint128"}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "tickDistance",
+		"outputs": [{"name": "", "type": "int24"}],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// KyberTradeInfo Kyber Classic 池子的交易参数
+type KyberTradeInfo struct {
+	Reserve0       *big.Int // 实际储备量
+	Reserve1       *big.Int
+	VReserve0      *big.Int // 放大后的虚拟储备量（未放大的池子为 0）
+	VReserve1      *big.Int
+	FeeInPrecision *big.Int // 动态费率（精度 1e18）
+}
+
+// KyberElasticPoolState Kyber Elastic 池子的价格状态
+type KyberElasticPoolState struct {
+	SqrtP       *big.Int // sqrtPriceX96
+	CurrentTick int32
+	Locked      bool
+}
+
+// GetKyberClassicPools 从 Kyber Classic Factory 获取代币对的所有池子
+func (c *Client) GetKyberClassicPools(factoryAddress, token0, token1 string) ([]string, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(KyberClassicFactoryABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析 Kyber Factory ABI 失败: %w", err)
+	}
+
+	contract := bind.NewBoundContract(common.HexToAddress(factoryAddress), parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	var out []interface{}
+	err = contract.Call(opts, &out, "getPools", common.HexToAddress(token0), common.HexToAddress(token1))
+	if err != nil {
+		return nil, fmt.Errorf("调用 Factory.getPools 失败: %w", err)
+	}
+
+	addresses := out[0].([]common.Address)
+	pools := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		pools = append(pools, addr.Hex())
+	}
+	return pools, nil
+}
+
+// GetKyberTradeInfo 读取 Kyber Classic 池子的储备量、虚拟储备量和动态费率
+func (c *Client) GetKyberTradeInfo(poolAddress string) (*KyberTradeInfo, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(KyberClassicPoolABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析 Kyber Pool ABI 失败: %w", err)
+	}
+
+	contract := bind.NewBoundContract(common.HexToAddress(poolAddress), parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	var out []interface{}
+	if err := contract.Call(opts, &out, "getTradeInfo"); err != nil {
+		return nil, fmt.Errorf("调用 Pool.getTradeInfo 失败: %w", err)
+	}
+
+	return &KyberTradeInfo{
+		Reserve0:       out[0].(*big.Int),
+		Reserve1:       out[1].(*big.Int),
+		VReserve0:      out[2].(*big.Int),
+		VReserve1:      out[3].(*big.Int),
+		FeeInPrecision: out[4].(*big.Int),
+	}, nil
+}
+
+// GetKyberElasticPool 从 Kyber Elastic Factory 获取池子地址（不存在时返回空字符串）
+// swapFeeUnits 的精度为 1e5（如 40 表示 0.04%）
+func (c *Client) GetKyberElasticPool(factoryAddress, token0, token1 string, swapFeeUnits uint32) (string, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(KyberElasticFactoryABI))
+	if err != nil {
+		return "", fmt.Errorf("解析 Kyber Elastic Factory ABI 失败: %w", err)
+	}
+
+	contract := bind.NewBoundContract(common.HexToAddress(factoryAddress), parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	var out []interface{}
+	err = contract.Call(opts, &out, "getPool",
+		common.HexToAddress(token0), common.HexToAddress(token1), big.NewInt(int64(swapFeeUnits)))
+	if err != nil {
+		return "", fmt.Errorf("调用 Factory.getPool 失败: %w", err)
+	}
+
+	poolAddr := out[0].(common.Address)
+	if poolAddr == (common.Address{}) {
+		return "", nil
+	}
+	return poolAddr.Hex(), nil
+}
+
+// GetKyberElasticPoolState 读取 Kyber Elastic 池子的 sqrtP 和当前 tick
+func (c *Client) GetKyberElasticPoolState(poolAddress string) (*KyberElasticPoolState, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(KyberElasticPoolABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析 Kyber Elastic Pool ABI 失败: %w", err)
+	}
+
+	contract := bind.NewBoundContract(common.HexToAddress(poolAddress), parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	var out []interface{}
+	if err := contract.Call(opts, &out, "getPoolState"); err != nil {
+		return nil, fmt.Errorf("调用 Pool.getPoolState 失败: %w", err)
+	}
+
+	tick, err := toInt32(out[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &KyberElasticPoolState{
+		SqrtP:       out[0].(*big.Int),
+		CurrentTick: tick,
+		Locked:      out[3].(bool),
+	}, nil
+}
+
+// GetKyberElasticLiquidity 读取 Kyber Elastic 池子的当前流动性（baseL + reinvestL）
+func (c *Client) GetKyberElasticLiquidity(poolAddress string) (*big.Int, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(KyberElasticPoolABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析 Kyber Elastic Pool ABI 失败: %w", err)
+	}
+
+	contract := bind.NewBoundContract(common.HexToAddress(poolAddress), parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	var out []interface{}
+	if err := contract.Call(opts, &out, "getLiquidityState"); err != nil {
+		return nil, fmt.Errorf("调用 Pool.getLiquidityState 失败: %w", err)
+	}
+
+	// 复投的手续费（reinvestL）同样参与交换
+	return new(big.Int).Add(out[0].(*big.Int), out[1].(*big.Int)), nil
+}
+
+// GetKyberElasticTickDistance 读取 Kyber Elastic 池子的 tick 间距
+func (c *Client) GetKyberElasticTickDistance(poolAddress string) (int32, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(KyberElasticPoolABI))
+	if err != nil {
+		return 0, fmt.Errorf("解析 Kyber Elastic Pool ABI 失败: %w", err)
+	}
+
+	contract := bind.NewBoundContract(common.HexToAddress(poolAddress), parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	var out []interface{}
+	if err := contract.Call(opts, &out, "tickDistance"); err != nil {
+		return 0, fmt.Errorf("调用 Pool.tickDistance 失败: %w", err)
+	}
+
+	return toInt32(out[0])
+}
+
+// toInt32 将 ABI 解码出的 int24 转换为 int32
+func toInt32(v interface{}) (int32, error) {
+	switch value := v.(type) {
+	case int32:
+		return value, nil
+	case *big.Int:
+		return int32(value.Int64()), nil
+	default:
+		return 0, fmt.Errorf("unexpected int24 type: %T", v)
+	}
+}
@@ -0,0 +1,51 @@
+package web3
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OffchainOracleABI 1inch OffchainOracle 的 getRate ABI
+// getRate 汇总多个 DEX 的现货价格，返回按流动性加权的汇率：
+// 1 个 srcToken 最小单位可换的 dstToken 最小单位数 × 1e18
+const OffchainOracleABI = `[
+	{
+		"inputs": [
+			{"name": "srcToken", "type": "address"},
+			{"name": "dstToken", "type": "address"},
+			{"name": "useWrappers", "type": "bool"}
+		],
+		"name": "getRate",
+		"outputs": [{"name": "weightedRate", "type": "uint256"}],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// GetOffchainOracleRate 调用 1inch OffchainOracle.getRate 读取链上现货汇率（精度 1e18，未做代币精度换算）
+// useWrappers 为 true 时允许经过包装代币（如 WETH、aToken）的连接器计算
+func (c *Client) GetOffchainOracleRate(oracleAddress, srcToken, dstToken string, useWrappers bool) (*big.Int, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(OffchainOracleABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析 OffchainOracle ABI 失败: %w", err)
+	}
+
+	contract := bind.NewBoundContract(common.HexToAddress(oracleAddress), parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	var out []interface{}
+	err = contract.Call(opts, &out, "getRate",
+		common.HexToAddress(srcToken), common.HexToAddress(dstToken), useWrappers)
+	if err != nil {
+		return nil, fmt.Errorf("调用 OffchainOracle.getRate 失败: %w", err)
+	}
+
+	return out[0].(*big.Int), nil
+}
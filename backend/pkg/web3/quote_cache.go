@@ -0,0 +1,86 @@
+package web3
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// quoteCache Quoter 查询结果的短期缓存（并发安全）
+// Quoter 的 quoteExactInputSingle 是非 view 的 eth_call，开销比普通读取大；
+// 同一个采集 / 分析周期内相同池子、相同金额的查询只需要发一次
+type quoteCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[quoteKey]quoteEntry
+	lastSweep time.Time
+}
+
+// quoteKey 缓存键：固定区块读取时包含区块号，区块变化后自然失效
+type quoteKey struct {
+	quoter   string
+	tokenIn  string
+	tokenOut string
+	fee      uint32
+	amount   string
+	block    uint64 // 0 表示最新区块（只按 TTL 失效）
+}
+
+type quoteEntry struct {
+	result    QuoteResult
+	expiresAt time.Time
+}
+
+func newQuoteCache(ttl time.Duration) *quoteCache {
+	return &quoteCache{
+		ttl:     ttl,
+		entries: make(map[quoteKey]quoteEntry),
+	}
+}
+
+// get 读取未过期的缓存结果
+func (q *quoteCache) get(key quoteKey) (*QuoteResult, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, ok := q.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	result := entry.result
+	return &result, true
+}
+
+// set 写入缓存，并定期清理过期条目
+func (q *quoteCache) set(key quoteKey, result *QuoteResult) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	q.entries[key] = quoteEntry{result: *result, expiresAt: now.Add(q.ttl)}
+
+	if now.Sub(q.lastSweep) < q.ttl {
+		return
+	}
+	for k, entry := range q.entries {
+		if now.After(entry.expiresAt) {
+			delete(q.entries, k)
+		}
+	}
+	q.lastSweep = now
+}
+
+// quoteCacheKey 构造缓存键（地址统一小写）
+func (c *Client) quoteCacheKey(quoterAddress, tokenIn, tokenOut string, fee uint32, amountIn string) quoteKey {
+	key := quoteKey{
+		quoter:   strings.ToLower(quoterAddress),
+		tokenIn:  strings.ToLower(tokenIn),
+		tokenOut: strings.ToLower(tokenOut),
+		fee:      fee,
+		amount:   amountIn,
+	}
+	if c.blockNumber != nil {
+		key.block = c.blockNumber.Uint64()
+	}
+	return key
+}
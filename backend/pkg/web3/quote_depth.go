@@ -0,0 +1,60 @@
+package web3
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// QuoteDepthCurve 按金额从小到大查询一个方向的深度曲线
+// 金额过大时 Quoter 会 revert，但费率层级错误、Quoter ABI 不匹配、池子未初始化同样会 revert：
+// 只有更小的金额已经报价成功时，才把 revert 视为超出池子可用流动性，追加一个 ExceedsLiquidity 边界点并停止；
+// 第一个成功之前的 revert 返回错误（包装 ErrQuoteReverted），不会被当作流动性耗尽。
+// 瞬时错误重试后仍失败时返回已得到的结果和错误
+func (c *Client) QuoteDepthCurve(
+	quoterType string,
+	quoterAddress string,
+	tokenIn string,
+	tokenOut string,
+	fee uint32,
+	amounts []*big.Int,
+) ([]*QuoteResult, error) {
+	return quoteDepthCurve(amounts, func(amountIn *big.Int) (*QuoteResult, error) {
+		return c.QuoteDepthPoint(quoterType, quoterAddress, tokenIn, tokenOut, amountIn, fee)
+	})
+}
+
+// quoteDepthCurve QuoteDepthCurve 的实现，quote 为单点报价（已包含重试）
+func quoteDepthCurve(amounts []*big.Int, quote func(amountIn *big.Int) (*QuoteResult, error)) ([]*QuoteResult, error) {
+	sorted := make([]*big.Int, len(amounts))
+	copy(sorted, amounts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+
+	results := make([]*QuoteResult, 0, len(sorted))
+	succeeded := false
+
+	for _, amount := range sorted {
+		result, err := quote(amount)
+		if err == nil {
+			succeeded = true
+			results = append(results, result)
+			continue
+		}
+
+		if !IsRevert(err) {
+			return results, fmt.Errorf("查询金额 %s 失败: %w", amount, err)
+		}
+		if !succeeded {
+			return nil, fmt.Errorf("查询金额 %s 失败（更小的金额没有成功报价，不能视为流动性耗尽）: %w", amount, err)
+		}
+
+		results = append(results, &QuoteResult{
+			AmountIn:         amount,
+			AmountOut:        big.NewInt(0),
+			ExceedsLiquidity: true,
+		})
+		break
+	}
+
+	return results, nil
+}
@@ -0,0 +1,188 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testRPCError 模拟节点返回的 JSON-RPC 错误
+type testRPCError struct {
+	code int
+	msg  string
+}
+
+func (e *testRPCError) Error() string  { return e.msg }
+func (e *testRPCError) ErrorCode() int { return e.code }
+
+var (
+	errTestRevert    = &testRPCError{code: executionRevertedCode, msg: "execution reverted"}
+	errTestTransient = errors.New("429 Too Many Requests")
+)
+
+func TestIsRevert(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "JSON-RPC 错误码 3", err: &testRPCError{code: 3, msg: "reverted: SPL"}, want: true},
+		{name: "包装后的 revert", err: fmt.Errorf("调用失败: %w", errTestRevert), want: true},
+		{name: "错误信息包含 execution reverted", err: errors.New("Execution Reverted: AS"), want: true},
+		{name: "ErrQuoteReverted", err: fmt.Errorf("%w: x", ErrQuoteReverted), want: true},
+		{name: "其他 JSON-RPC 错误", err: &testRPCError{code: -32000, msg: "header not found"}, want: false},
+		{name: "网络错误", err: errTestTransient, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRevert(tt.err); got != tt.want {
+				t.Errorf("IsRevert(%v) = %v, 期望 %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteWithRetry(t *testing.T) {
+	saved := quoteRetryDelay
+	quoteRetryDelay = time.Millisecond
+	t.Cleanup(func() { quoteRetryDelay = saved })
+
+	ok := &QuoteResult{AmountOut: big.NewInt(1)}
+
+	tests := []struct {
+		name      string
+		errs      []error // 依次返回的错误，用完后返回成功
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "直接成功", errs: nil, wantCalls: 1},
+		{name: "瞬时错误后成功", errs: []error{errTestTransient, errTestTransient}, wantCalls: 3},
+		{name: "瞬时错误超过重试次数", errs: []error{errTestTransient, errTestTransient, errTestTransient}, wantErr: errTestTransient, wantCalls: 3},
+		{name: "revert 不重试", errs: []error{errTestRevert}, wantErr: ErrQuoteReverted, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			result, err := quoteWithRetry(context.Background(), func() (*QuoteResult, error) {
+				calls++
+				if calls <= len(tt.errs) {
+					return nil, tt.errs[calls-1]
+				}
+				return ok, nil
+			})
+
+			if calls != tt.wantCalls {
+				t.Errorf("调用次数 = %d, 期望 %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("错误 = %v, 期望包装 %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || result != ok {
+				t.Errorf("结果 = %v, %v, 期望成功", result, err)
+			}
+		})
+	}
+}
+
+func TestQuoteWithRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := quoteWithRetry(ctx, func() (*QuoteResult, error) { return nil, errTestTransient })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("错误 = %v, 期望 context.Canceled", err)
+	}
+}
+
+func TestQuoteDepthCurve(t *testing.T) {
+	amounts := []*big.Int{big.NewInt(100), big.NewInt(1), big.NewInt(10), big.NewInt(1000)} // 乱序输入
+
+	tests := []struct {
+		name        string
+		fail        map[int64]error // 指定金额返回的错误
+		wantAmounts []int64         // 期望结果的金额（升序）
+		wantBound   int64           // 期望的边界点金额（0 表示没有）
+		wantErr     error
+	}{
+		{
+			name:        "全部成功",
+			wantAmounts: []int64{1, 10, 100, 1000},
+		},
+		{
+			name:        "更小金额成功后 revert 视为流动性耗尽",
+			fail:        map[int64]error{100: errTestRevert, 1000: errTestRevert},
+			wantAmounts: []int64{1, 10, 100},
+			wantBound:   100,
+		},
+		{
+			// 费率层级错误、Quoter ABI 不匹配、池子未初始化：最小金额就 revert
+			name:    "最小金额 revert 返回错误",
+			fail:    map[int64]error{1: errTestRevert, 10: errTestRevert, 100: errTestRevert, 1000: errTestRevert},
+			wantErr: ErrQuoteReverted,
+		},
+		{
+			name:        "瞬时错误返回已成功的点和错误",
+			fail:        map[int64]error{10: errTestTransient},
+			wantAmounts: []int64{1},
+			wantErr:     errTestTransient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queried []int64
+			results, err := quoteDepthCurve(amounts, func(amountIn *big.Int) (*QuoteResult, error) {
+				queried = append(queried, amountIn.Int64())
+				if err := tt.fail[amountIn.Int64()]; err != nil {
+					if IsRevert(err) {
+						return nil, fmt.Errorf("%w: %w", ErrQuoteReverted, err)
+					}
+					return nil, err
+				}
+				return &QuoteResult{AmountIn: amountIn, AmountOut: new(big.Int).Mul(amountIn, big.NewInt(2))}, nil
+			})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("错误 = %v, 期望包装 %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("意外错误: %v", err)
+			}
+
+			if len(results) != len(tt.wantAmounts) {
+				t.Fatalf("结果数 = %d, 期望 %d", len(results), len(tt.wantAmounts))
+			}
+			for i, result := range results {
+				if result.AmountIn.Int64() != tt.wantAmounts[i] {
+					t.Errorf("结果 %d 金额 = %s, 期望 %d", i, result.AmountIn, tt.wantAmounts[i])
+				}
+				isBound := result.AmountIn.Int64() == tt.wantBound
+				if result.ExceedsLiquidity != isBound {
+					t.Errorf("金额 %s ExceedsLiquidity = %v, 期望 %v", result.AmountIn, result.ExceedsLiquidity, isBound)
+				}
+				if isBound && result.AmountOut.Sign() != 0 {
+					t.Errorf("边界点 AmountOut 应为 0，实际为 %s", result.AmountOut)
+				}
+			}
+
+			// 边界点之后不再查询更大的金额
+			if tt.wantBound != 0 {
+				for _, amount := range queried {
+					if amount > tt.wantBound {
+						t.Errorf("边界 %d 之后仍查询了金额 %d", tt.wantBound, amount)
+					}
+				}
+			}
+		})
+	}
+}
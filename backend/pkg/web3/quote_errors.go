@@ -0,0 +1,89 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrQuoteReverted Quoter 调用 revert（金额超出流动性、费率层级错误、Quoter ABI 不匹配、池子未初始化等）
+var ErrQuoteReverted = errors.New("Quoter 报价 revert")
+
+// executionRevertedCode 节点对 eth_call 合约 revert 返回的 JSON-RPC 错误码
+const executionRevertedCode = 3
+
+// quoteRetries 深度报价遇到瞬时错误（超时、限流、连接断开）时的重试次数
+const quoteRetries = 2
+
+// quoteRetryDelay 深度报价首次重试前的等待时间（之后翻倍）
+var quoteRetryDelay = 200 * time.Millisecond
+
+// IsRevert 判断错误是否为合约 revert（而不是 RPC / 网络错误），重试不会成功
+func IsRevert(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrQuoteReverted) {
+		return true
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == executionRevertedCode {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "execution reverted")
+}
+
+// QuoteDepthPoint 深度采集使用的单点报价
+// Quoter revert 不重试，返回包装了 ErrQuoteReverted 的错误（是否代表流动性耗尽由 QuoteDepthCurve 判断）；
+// 其他错误按瞬时错误重试，重试用完仍失败时返回错误
+func (c *Client) QuoteDepthPoint(
+	quoterType string,
+	quoterAddress string,
+	tokenIn string,
+	tokenOut string,
+	amountIn *big.Int,
+	fee uint32,
+) (*QuoteResult, error) {
+	return quoteWithRetry(c.ctx, func() (*QuoteResult, error) {
+		return c.QuoteExactInputSingleWithType(quoterType, quoterAddress, tokenIn, tokenOut, amountIn, fee)
+	})
+}
+
+// quoteWithRetry 执行报价，瞬时错误按退避重试，revert 立即返回
+func quoteWithRetry(ctx context.Context, quote func() (*QuoteResult, error)) (*QuoteResult, error) {
+	delay := quoteRetryDelay
+
+	var lastErr error
+	for attempt := 0; attempt <= quoteRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		result, err := quote()
+		if err == nil {
+			return result, nil
+		}
+		if IsRevert(err) {
+			if errors.Is(err, ErrQuoteReverted) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %w", ErrQuoteReverted, err)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("报价重试 %d 次后仍失败: %w", quoteRetries, lastErr)
+}
@@ -0,0 +1,182 @@
+package web3
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Quoter 类型（不同 V3 分叉的 Quoter 合约 ABI 不同）
+const (
+	QuoterTypeUniswapV2 = "uniswap_v2quoter" // Uniswap QuoterV2：参数为 tuple，返回 4 个值（默认）
+	QuoterTypeUniswapV1 = "uniswap_v1quoter" // Uniswap Quoter（V1）：位置参数，只返回 amountOut
+	QuoterTypePancakeV3 = "pancake_v3"       // PancakeSwap V3 QuoterV2：与 Uniswap QuoterV2 ABI 相同
+	QuoterTypeAlgebra   = "algebra"          // Algebra Quoter：无 fee 参数（动态费率），返回 amountOut 和 fee
+)
+
+// QuoterV1ABI Uniswap Quoter（V1）ABI
+const QuoterV1ABI = `[
+	{
+		"inputs": [
+			{"name": "tokenIn", "type": "address"},
+			{"name": "tokenOut", "type": "address"},
+			{"name": "fee", "type": "uint24"},
+			{"name": "amountIn", "type": "uint256"},
+			{"name": "sqrtPriceLimitX96", "type": "uint160"}
+		],
+		"name": "quoteExactInputSingle",
+		"outputs": [
+			{"name": "amountOut", "type": "uint256"}
+		],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	}
+]`
+
+// AlgebraQuoterABI Algebra Quoter ABI
+const AlgebraQuoterABI = `[
+	{
+		"inputs": [
+			{"name": "tokenIn", "type": "address"},
+			{"name": "tokenOut", "type": "address"},
+			{"name": "amountIn", "type": "uint256"},
+			{"name": "limitSqrtPrice", "type": "uint160"}
+		],
+		"name": "quoteExactInputSingle",
+		"outputs": [
+			{"name": "amountOut", "type": "uint256"},
+			{"name": "fee", "type": "uint16"}
+		],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	}
+]`
+
+// ValidateQuoterType 校验 Quoter 类型（空字符串视为默认的 uniswap_v2quoter）
+func ValidateQuoterType(quoterType string) error {
+	switch quoterType {
+	case "", QuoterTypeUniswapV2, QuoterTypeUniswapV1, QuoterTypePancakeV3, QuoterTypeAlgebra:
+		return nil
+	default:
+		return fmt.Errorf("未知的 Quoter 类型: %s（支持: %s, %s, %s, %s）", quoterType,
+			QuoterTypeUniswapV2, QuoterTypeUniswapV1, QuoterTypePancakeV3, QuoterTypeAlgebra)
+	}
+}
+
+// QuoteExactInputSingleWithType 按 Quoter 类型选择 ABI 模拟单跳交换
+// V1 / Algebra Quoter 不返回交换后价格，结果中 SqrtPriceX96After 为 nil；
+// 启用了 Quoter 缓存时，相同 (quoter, tokenIn, tokenOut, fee, amountIn, 区块) 的查询在缓存时长内直接返回缓存结果
+func (c *Client) QuoteExactInputSingleWithType(
+	quoterType string,
+	quoterAddress string,
+	tokenIn string,
+	tokenOut string,
+	amountIn *big.Int,
+	fee uint32,
+) (*QuoteResult, error) {
+	if err := ValidateQuoterType(quoterType); err != nil {
+		return nil, err
+	}
+
+	var key quoteKey
+	if c.quotes != nil {
+		key = c.quoteCacheKey(quoterAddress, tokenIn, tokenOut, fee, amountIn.String())
+		if cached, ok := c.quotes.get(key); ok {
+			return cached, nil
+		}
+	}
+
+	var result *QuoteResult
+	var err error
+	switch quoterType {
+	case QuoterTypeUniswapV1:
+		result, err = c.quoteExactInputSingleV1(quoterAddress, tokenIn, tokenOut, amountIn, fee)
+	case QuoterTypeAlgebra:
+		result, err = c.quoteExactInputSingleAlgebra(quoterAddress, tokenIn, tokenOut, amountIn)
+	default:
+		// uniswap_v2quoter / pancake_v3 使用相同的 tuple 参数 ABI
+		result, err = c.QuoteExactInputSingle(quoterAddress, tokenIn, tokenOut, amountIn, fee)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.quotes != nil {
+		c.quotes.set(key, result)
+	}
+	return result, nil
+}
+
+// quoteExactInputSingleV1 使用 Uniswap Quoter（V1）查询
+func (c *Client) quoteExactInputSingleV1(
+	quoterAddress string,
+	tokenIn string,
+	tokenOut string,
+	amountIn *big.Int,
+	fee uint32,
+) (*QuoteResult, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(QuoterV1ABI))
+	if err != nil {
+		return nil, err
+	}
+
+	contract := bind.NewBoundContract(common.HexToAddress(quoterAddress), parsedABI, c.heavyEth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	var out []interface{}
+	err = contract.Call(opts, &out, "quoteExactInputSingle",
+		common.HexToAddress(tokenIn),
+		common.HexToAddress(tokenOut),
+		big.NewInt(int64(fee)),
+		amountIn,
+		big.NewInt(0), // 0 = 不限制价格
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QuoteResult{
+		AmountIn:  amountIn,
+		AmountOut: out[0].(*big.Int),
+	}, nil
+}
+
+// quoteExactInputSingleAlgebra 使用 Algebra Quoter 查询（费率由池子动态决定）
+func (c *Client) quoteExactInputSingleAlgebra(
+	quoterAddress string,
+	tokenIn string,
+	tokenOut string,
+	amountIn *big.Int,
+) (*QuoteResult, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(AlgebraQuoterABI))
+	if err != nil {
+		return nil, err
+	}
+
+	contract := bind.NewBoundContract(common.HexToAddress(quoterAddress), parsedABI, c.heavyEth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	var out []interface{}
+	err = contract.Call(opts, &out, "quoteExactInputSingle",
+		common.HexToAddress(tokenIn),
+		common.HexToAddress(tokenOut),
+		amountIn,
+		big.NewInt(0), // 0 = 不限制价格
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QuoteResult{
+		AmountIn:  amountIn,
+		AmountOut: out[0].(*big.Int),
+	}, nil
+}
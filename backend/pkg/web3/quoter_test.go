@@ -0,0 +1,219 @@
+package web3
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newMockRPC 启动只响应 eth_chainId / eth_call 的 JSON-RPC 节点
+// call 收到 eth_call 的 calldata，返回 ABI 编码的结果；返回 nil 时节点以 execution reverted 回滚
+func newMockRPC(t *testing.T, call func(input []byte) []byte) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "eth_chainId":
+			resp["result"] = "0x1"
+		case "eth_call":
+			var msg struct {
+				Input hexutil.Bytes `json:"input"`
+				Data  hexutil.Bytes `json:"data"`
+			}
+			_ = json.Unmarshal(req.Params[0], &msg)
+			input := msg.Input
+			if len(input) == 0 {
+				input = msg.Data
+			}
+			if out := call(input); out != nil {
+				resp["result"] = hexutil.Encode(out)
+			} else {
+				resp["error"] = map[string]interface{}{"code": executionRevertedCode, "message": "execution reverted"}
+			}
+		default:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL, 1, 5)
+	if err != nil {
+		t.Fatalf("连接模拟节点失败: %v", err)
+	}
+	return client
+}
+
+// abiWords 按 ABI 规则把静态参数编码为 32 字节字
+func abiWords(values ...interface{}) []byte {
+	var out []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case common.Address:
+			out = append(out, common.LeftPadBytes(v.Bytes(), 32)...)
+		case int64:
+			out = append(out, common.LeftPadBytes(big.NewInt(v).Bytes(), 32)...)
+		case *big.Int:
+			out = append(out, common.LeftPadBytes(v.Bytes(), 32)...)
+		}
+	}
+	return out
+}
+
+func TestQuoteExactInputSingleWithTypePacking(t *testing.T) {
+	const (
+		quoter   = "0x00000000000000000000000000000000000000aa"
+		tokenIn  = "0x00000000000000000000000000000000000000b1"
+		tokenOut = "0x00000000000000000000000000000000000000b2"
+		fee      = uint32(500)
+	)
+	in, out := common.HexToAddress(tokenIn), common.HexToAddress(tokenOut)
+	amountIn := big.NewInt(1e18)
+	sqrtAfter := new(big.Int).Lsh(big.NewInt(1), 96)
+
+	tests := []struct {
+		name       string
+		quoterType string
+		signature  string // 期望调用的函数签名
+		args       []byte // 期望的参数编码
+		result     []byte // 模拟 Quoter 的返回值
+		wantOut    int64
+		wantSqrt   bool // 结果是否包含 SqrtPriceX96After
+	}{
+		{
+			// QuoterV2 的参数是静态 tuple，编码与按字段顺序的位置参数相同
+			name:       "uniswap_v2quoter",
+			quoterType: QuoterTypeUniswapV2,
+			signature:  "quoteExactInputSingle((address,address,uint256,uint24,uint160))",
+			args:       abiWords(in, out, amountIn, int64(fee), int64(0)),
+			result:     abiWords(int64(1000), sqrtAfter, int64(2), int64(90000)),
+			wantOut:    1000,
+			wantSqrt:   true,
+		},
+		{
+			name:       "默认类型为 uniswap_v2quoter",
+			quoterType: "",
+			signature:  "quoteExactInputSingle((address,address,uint256,uint24,uint160))",
+			args:       abiWords(in, out, amountIn, int64(fee), int64(0)),
+			result:     abiWords(int64(1001), sqrtAfter, int64(2), int64(90000)),
+			wantOut:    1001,
+			wantSqrt:   true,
+		},
+		{
+			name:       "pancake_v3",
+			quoterType: QuoterTypePancakeV3,
+			signature:  "quoteExactInputSingle((address,address,uint256,uint24,uint160))",
+			args:       abiWords(in, out, amountIn, int64(fee), int64(0)),
+			result:     abiWords(int64(1002), sqrtAfter, int64(1), int64(80000)),
+			wantOut:    1002,
+			wantSqrt:   true,
+		},
+		{
+			// V1 为位置参数，fee 在 amountIn 之前
+			name:       "uniswap_v1quoter",
+			quoterType: QuoterTypeUniswapV1,
+			signature:  "quoteExactInputSingle(address,address,uint24,uint256,uint160)",
+			args:       abiWords(in, out, int64(fee), amountIn, int64(0)),
+			result:     abiWords(int64(1003)),
+			wantOut:    1003,
+		},
+		{
+			// Algebra 没有 fee 参数
+			name:       "algebra",
+			quoterType: QuoterTypeAlgebra,
+			signature:  "quoteExactInputSingle(address,address,uint256,uint160)",
+			args:       abiWords(in, out, amountIn, int64(0)),
+			result:     abiWords(int64(1004), int64(100)),
+			wantOut:    1004,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := append(crypto.Keccak256([]byte(tt.signature))[:4], tt.args...)
+
+			var mu sync.Mutex
+			var got []byte
+			client := newMockRPC(t, func(input []byte) []byte {
+				mu.Lock()
+				got = input
+				mu.Unlock()
+				if !bytes.Equal(input, want) {
+					return nil
+				}
+				return tt.result
+			})
+
+			result, err := client.QuoteExactInputSingleWithType(tt.quoterType, quoter, tokenIn, tokenOut, amountIn, fee)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !bytes.Equal(got, want) {
+				t.Fatalf("calldata = %x, 期望 %x", got, want)
+			}
+			if err != nil {
+				t.Fatalf("QuoteExactInputSingleWithType 返回错误: %v", err)
+			}
+			if result.AmountOut.Int64() != tt.wantOut {
+				t.Errorf("AmountOut = %s, 期望 %d", result.AmountOut, tt.wantOut)
+			}
+			if result.AmountIn.Cmp(amountIn) != 0 {
+				t.Errorf("AmountIn = %s, 期望 %s", result.AmountIn, amountIn)
+			}
+			if tt.wantSqrt && (result.SqrtPriceX96After == nil || result.SqrtPriceX96After.Cmp(sqrtAfter) != 0) {
+				t.Errorf("SqrtPriceX96After = %v, 期望 %s", result.SqrtPriceX96After, sqrtAfter)
+			}
+			if !tt.wantSqrt && result.SqrtPriceX96After != nil {
+				t.Errorf("SqrtPriceX96After = %s, 期望 nil", result.SqrtPriceX96After)
+			}
+		})
+	}
+}
+
+func TestQuoteExactInputSingleWithUnknownType(t *testing.T) {
+	calls := 0
+	client := newMockRPC(t, func(input []byte) []byte {
+		calls++
+		return nil
+	})
+
+	_, err := client.QuoteExactInputSingleWithType("sushi_v3", "0x01", "0x02", "0x03", big.NewInt(1), 3000)
+	if err == nil {
+		t.Fatal("未知 Quoter 类型应返回错误")
+	}
+	if calls != 0 {
+		t.Errorf("未知 Quoter 类型不应发起调用，实际调用 %d 次", calls)
+	}
+}
+
+func TestValidateQuoterType(t *testing.T) {
+	for _, quoterType := range []string{"", QuoterTypeUniswapV2, QuoterTypeUniswapV1, QuoterTypePancakeV3, QuoterTypeAlgebra} {
+		if err := ValidateQuoterType(quoterType); err != nil {
+			t.Errorf("ValidateQuoterType(%q) 返回错误: %v", quoterType, err)
+		}
+	}
+	if err := ValidateQuoterType("uniswap_v3"); err == nil {
+		t.Error("ValidateQuoterType(\"uniswap_v3\") 应返回错误")
+	}
+}
@@ -34,15 +34,41 @@ const QuoterV2ABI = `[
 		],
 		"stateMutability": "nonpayable",
 		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"components": [
+					{"name": "tokenIn", "type": "address"},
+					{"name": "tokenOut", "type": "address"},
+					{"name": "amount", "type": "uint256"},
+					{"name": "fee", "type": "uint24"},
+					{"name": "sqrtPriceLimitX96", "type": "uint160"}
+				],
+				"name": "params",
+				"type": "tuple"
+			}
+		],
+		"name": "quoteExactOutputSingle",
+		"outputs": [
+			{"name": "amountIn", "type": "uint256"},
+			{"name": "sqrtPriceX96After", "type": "uint160"},
+			{"name": "initializedTicksCrossed", "type": "uint32"},
+			{"name": "gasEstimate", "type": "uint256"}
+		],
+		"stateMutability": "nonpayable",
+		"type": "function"
 	}
 ]`
 
 // QuoteResult Quoter 查询结果
 type QuoteResult struct {
-	AmountOut               *big.Int
+	AmountIn                *big.Int // 输入金额：exact input 查询为传入的金额，exact output 查询为 Quoter 算出的所需输入
+	AmountOut               *big.Int // 输出金额：exact input 查询为 Quoter 算出的输出，exact output 查询为传入的目标输出
 	SqrtPriceX96After       *big.Int
 	InitializedTicksCrossed uint32
 	GasEstimate             uint64
+	ExceedsLiquidity        bool // 金额超出池子可用流动性（更小金额成功后 Quoter revert，AmountOut 为 0，仅 QuoteDepthCurve / BatchQuote 返回）
 }
 
 // QuoteExactInputSingle 使用 QuoterV2 模拟单跳交换
@@ -65,7 +91,7 @@ func (c *Client) QuoteExactInputSingle(
 	}
 
 	// 创建绑定
-	contract := bind.NewBoundContract(quoterAddr, parsedABI, c.client, nil, nil)
+	contract := bind.NewBoundContract(quoterAddr, parsedABI, c.heavyEth(), nil, nil)
 
 	// 构造参数（使用 struct）
 	params := struct {
@@ -82,14 +108,18 @@ func (c *Client) QuoteExactInputSingle(
 		SqrtPriceLimitX96: big.NewInt(0), // 0 = 不限制价格
 	}
 
+	opts, cancel := c.callOpts()
+	defer cancel()
+
 	// 调用 quoteExactInputSingle
 	var out []interface{}
-	err = contract.Call(nil, &out, "quoteExactInputSingle", params)
+	err = contract.Call(opts, &out, "quoteExactInputSingle", params)
 	if err != nil {
 		return nil, err
 	}
 
 	return &QuoteResult{
+		AmountIn:                amountIn,
 		AmountOut:               out[0].(*big.Int),
 		SqrtPriceX96After:       out[1].(*big.Int),
 		InitializedTicksCrossed: out[2].(uint32),
@@ -97,8 +127,65 @@ func (c *Client) QuoteExactInputSingle(
 	}, nil
 }
 
+// QuoteExactOutputSingle 使用 QuoterV2 计算得到指定输出所需的输入金额
+// 用于按目标输出确定交易规模（如偿还固定金额的闪电贷）
+func (c *Client) QuoteExactOutputSingle(
+	quoterAddress string,
+	tokenIn string,
+	tokenOut string,
+	amountOut *big.Int,
+	fee uint32,
+) (*QuoteResult, error) {
+	quoterAddr := common.HexToAddress(quoterAddress)
+	tokenInAddr := common.HexToAddress(tokenIn)
+	tokenOutAddr := common.HexToAddress(tokenOut)
+
+	// 解析 ABI
+	parsedABI, err := abi.JSON(strings.NewReader(QuoterV2ABI))
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建绑定
+	contract := bind.NewBoundContract(quoterAddr, parsedABI, c.heavyEth(), nil, nil)
+
+	// 构造参数（使用 struct，amount 表示期望输出）
+	params := struct {
+		TokenIn           common.Address
+		TokenOut          common.Address
+		Amount            *big.Int
+		Fee               *big.Int
+		SqrtPriceLimitX96 *big.Int
+	}{
+		TokenIn:           tokenInAddr,
+		TokenOut:          tokenOutAddr,
+		Amount:            amountOut,
+		Fee:               big.NewInt(int64(fee)),
+		SqrtPriceLimitX96: big.NewInt(0), // 0 = 不限制价格
+	}
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	// 调用 quoteExactOutputSingle
+	var out []interface{}
+	err = contract.Call(opts, &out, "quoteExactOutputSingle", params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QuoteResult{
+		AmountIn:                out[0].(*big.Int),
+		AmountOut:               amountOut,
+		SqrtPriceX96After:       out[1].(*big.Int),
+		InitializedTicksCrossed: out[2].(uint32),
+		GasEstimate:             out[3].(*big.Int).Uint64(),
+	}, nil
+}
+
 // BatchQuote 批量查询多个金额的输出（用于深度采集）
-// 这是业界推荐的深度数据采集方式
+// 结果按金额升序，超出池子流动性的第一个金额返回 ExceedsLiquidity 为 true 的边界点，更大的金额不再查询；
+// 最小金额就 revert 或瞬时错误重试后仍失败时返回错误（不会静默丢掉测试点）
 func (c *Client) BatchQuote(
 	quoterAddress string,
 	tokenIn string,
@@ -106,17 +193,10 @@ func (c *Client) BatchQuote(
 	fee uint32,
 	amounts []*big.Int,
 ) ([]*QuoteResult, error) {
-	results := make([]*QuoteResult, 0, len(amounts))
-
-	for _, amount := range amounts {
-		result, err := c.QuoteExactInputSingle(quoterAddress, tokenIn, tokenOut, amount, fee)
-		if err != nil {
-			// 跳过失败的查询（可能金额过大）
-			continue
-		}
-		results = append(results, result)
+	results, err := c.QuoteDepthCurve(QuoterTypeUniswapV2, quoterAddress, tokenIn, tokenOut, fee, amounts)
+	if err != nil {
+		return nil, err
 	}
-
 	return results, nil
 }
 
@@ -125,7 +205,8 @@ func (c *Client) CalculatePriceImpact(
 	sqrtPriceBefore *big.Int,
 	sqrtPriceAfter *big.Int,
 ) float64 {
-	if sqrtPriceBefore.Sign() == 0 {
+	// V1 / Algebra Quoter 不返回交换后价格，无法计算
+	if sqrtPriceBefore == nil || sqrtPriceAfter == nil || sqrtPriceBefore.Sign() == 0 {
 		return 0
 	}
 
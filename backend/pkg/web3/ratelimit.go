@@ -0,0 +1,39 @@
+package web3
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimitedTransport 对每个 HTTP RPC 请求做令牌桶限流
+// 在传输层限流可以覆盖所有调用（包括 bind 合约调用和批量请求），
+// 并且 Wait 会随请求上下文一起取消
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+// RoundTrip 获取令牌后再发送请求
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, fmt.Errorf("等待 RPC 限流令牌失败: %w", err)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// newRateLimitedTransport 在 base 外层加上限流
+// 桶容量为每秒请求数（至少为 1），允许短时间内的小突发
+func newRateLimitedTransport(base http.RoundTripper, maxRPS float64) *rateLimitedTransport {
+	burst := int(math.Ceil(maxRPS))
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimitedTransport{
+		base:    base,
+		limiter: rate.NewLimiter(rate.Limit(maxRPS), burst),
+	}
+}
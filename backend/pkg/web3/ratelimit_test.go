@@ -0,0 +1,37 @@
+package web3
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimitWaitExcludedFromStats(t *testing.T) {
+	const maxRPS = 10 // 桶容量 10，超出后每个请求等待约 100ms
+	client := newMockRPC(t, func([]byte) []byte { return nil })
+
+	stats := newRPCStats()
+	eth, err := dialClient(context.Background(), client.conn.rpcURL, maxRPS, stats)
+	if err != nil {
+		t.Fatalf("连接模拟节点失败: %v", err)
+	}
+
+	start := time.Now()
+	for i := 0; i < maxRPS+3; i++ {
+		if _, err := eth.ChainID(context.Background()); err != nil {
+			t.Fatalf("ChainID 返回错误: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("%d 个请求耗时 %v, 限流未生效", maxRPS+3, elapsed)
+	}
+
+	snapshot := stats.snapshot()
+	if len(snapshot) != 1 || snapshot[0].Method != "eth_chainId" || snapshot[0].Calls != maxRPS+3 {
+		t.Fatalf("统计 = %+v, 期望 eth_chainId 共 %d 次", snapshot, maxRPS+3)
+	}
+	// 等待令牌的时间不计入延迟，本地节点的往返远小于 100ms
+	if snapshot[0].MaxLatencyMs >= 50 {
+		t.Errorf("最大延迟 = %.1fms, 包含了限流等待时间", snapshot[0].MaxLatencyMs)
+	}
+}
@@ -0,0 +1,141 @@
+package web3
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// defaultReconnectAfterFailures 保活检查连续失败多少次后重新连接
+const defaultReconnectAfterFailures = 3
+
+// connection 可替换的底层 RPC 连接
+// 节点断开连接后（WebSocket 断线、负载均衡器回收连接等）原连接会一直报错，
+// 重新连接时在锁内替换 ethclient，进行中的调用继续使用旧连接直到完成
+type connection struct {
+	mu     sync.RWMutex
+	client *ethclient.Client
+
+	rpcURL      string
+	maxRPS      float64
+	dialTimeout time.Duration
+	callTimeout time.Duration
+
+	reconnectMu sync.Mutex // 避免保活检查和 RPC 池同时重连
+	stopCh      chan struct{}
+	stopOnce    sync.Once
+}
+
+func newConnection(client *ethclient.Client, rpcURL string, opts ClientOptions) *connection {
+	return &connection{
+		client:      client,
+		rpcURL:      rpcURL,
+		maxRPS:      opts.MaxRPS,
+		dialTimeout: opts.DialTimeout,
+		callTimeout: opts.CallTimeout,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// get 获取当前连接
+func (cn *connection) get() *ethclient.Client {
+	cn.mu.RLock()
+	defer cn.mu.RUnlock()
+	return cn.client
+}
+
+// swap 替换连接，返回旧连接
+func (cn *connection) swap(client *ethclient.Client) *ethclient.Client {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+	old := cn.client
+	cn.client = client
+	return old
+}
+
+// close 停止保活检查并关闭当前连接
+func (cn *connection) close() {
+	cn.stopOnce.Do(func() { close(cn.stopCh) })
+	if client := cn.get(); client != nil {
+		client.Close()
+	}
+}
+
+// eth 获取当前的底层 ethclient
+func (c *Client) eth() *ethclient.Client {
+	return c.conn.get()
+}
+
+// Reconnect 重新连接 RPC 节点并替换底层 ethclient
+// 新连接通过 ChainID 校验后才替换；旧连接延迟一个调用超时再关闭，不打断进行中的调用
+func (c *Client) Reconnect() error {
+	cn := c.conn
+	cn.reconnectMu.Lock()
+	defer cn.reconnectMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cn.dialTimeout)
+	defer cancel()
+
+	client, err := dialClient(ctx, cn.rpcURL, cn.maxRPS, c.stats)
+	if err != nil {
+		return fmt.Errorf("重新连接 RPC 失败: %w", err)
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		client.Close()
+		return fmt.Errorf("重新连接后获取 ChainID 失败: %w", err)
+	}
+	if chainID.Cmp(c.chainID) != 0 {
+		client.Close()
+		return fmt.Errorf("重新连接后 ChainID 不一致: 期望 %s, 实际 %s", c.chainID, chainID)
+	}
+
+	old := cn.swap(client)
+	if old != nil {
+		time.AfterFunc(cn.callTimeout, old.Close)
+	}
+
+	log.Printf("✅ RPC 重新连接成功: %s", cn.rpcURL)
+	return nil
+}
+
+// keepalive 定期检查连接，连续失败达到阈值后重新连接
+func (c *Client) keepalive(interval time.Duration, reconnectAfter int) {
+	if reconnectAfter <= 0 {
+		reconnectAfter = defaultReconnectAfterFailures
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.conn.stopCh:
+			return
+		}
+
+		_, err := c.GetBlockNumber()
+		if err == nil {
+			failures = 0
+			continue
+		}
+
+		failures++
+		log.Printf("⚠️  RPC 保活检查失败 [%d/%d] %s: %v", failures, reconnectAfter, c.conn.rpcURL, err)
+		if failures < reconnectAfter {
+			continue
+		}
+		if err := c.Reconnect(); err != nil {
+			log.Printf("❌ %v", err)
+			continue
+		}
+		failures = 0
+	}
+}
@@ -0,0 +1,182 @@
+package web3
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 签名器类型
+const (
+	SignerTypeKeystore = "keystore" // 本地加密 keystore 文件
+	SignerTypeExternal = "external" // 外部签名服务（Clef 兼容的 HTTP 接口）
+	SignerTypeAWSKMS   = "aws_kms"  // AWS KMS（开发中）
+)
+
+// ErrSignerNotImplemented 签名器类型尚未实现
+var ErrSignerNotImplemented = errors.New("签名器尚未实现")
+
+// Signer 交易签名器
+// 发送交易的代码只依赖该接口，私钥托管方式（本地 keystore / KMS / HSM / 外部签名服务）可替换，
+// 配置和内存中都不需要出现明文私钥
+type Signer interface {
+	// Address 签名账户地址
+	Address() common.Address
+	// SignTx 对交易签名
+	SignTx(tx *types.Transaction) (*types.Transaction, error)
+}
+
+// SignerConfig 签名器配置
+type SignerConfig struct {
+	Type    string   // 签名器类型：keystore, external, aws_kms
+	ChainID *big.Int // 链 ID（EIP-155 签名需要）
+
+	// === keystore ===
+	KeystorePath string // keystore JSON 文件路径
+	Password     string // keystore 密码（建议通过环境变量传入）
+
+	// === external ===
+	Endpoint string         // 外部签名服务地址，如 http://127.0.0.1:8550
+	Account  common.Address // 使用的账户（为空时取签名服务的第一个账户）
+
+	// === aws_kms ===
+	KMSKeyID string // KMS 密钥 ID
+	Region   string // AWS 区域
+}
+
+// NewSigner 根据配置创建签名器
+func NewSigner(cfg *SignerConfig) (Signer, error) {
+	if cfg.ChainID == nil {
+		return nil, fmt.Errorf("签名器需要指定 ChainID")
+	}
+
+	switch cfg.Type {
+	case SignerTypeKeystore:
+		return NewKeystoreSigner(cfg.KeystorePath, cfg.Password, cfg.ChainID)
+	case SignerTypeExternal:
+		return NewExternalSigner(cfg.Endpoint, cfg.Account, cfg.ChainID)
+	case SignerTypeAWSKMS:
+		return NewAWSKMSSigner(cfg.KMSKeyID, cfg.Region, cfg.ChainID)
+	default:
+		return nil, fmt.Errorf("未知的签名器类型: %s", cfg.Type)
+	}
+}
+
+// KeystoreSigner 本地 keystore 签名器
+type KeystoreSigner struct {
+	address    common.Address
+	privateKey *ecdsa.PrivateKey
+	signer     types.Signer
+}
+
+// NewKeystoreSigner 从加密的 keystore 文件创建签名器
+func NewKeystoreSigner(path, password string, chainID *big.Int) (*KeystoreSigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 keystore 文件失败: %w", err)
+	}
+
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return nil, fmt.Errorf("解密 keystore 失败: %w", err)
+	}
+
+	return &KeystoreSigner{
+		address:    key.Address,
+		privateKey: key.PrivateKey,
+		signer:     types.LatestSignerForChainID(chainID),
+	}, nil
+}
+
+// Address 签名账户地址
+func (s *KeystoreSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx 对交易签名
+func (s *KeystoreSigner) SignTx(tx *types.Transaction) (*types.Transaction, error) {
+	signedTx, err := types.SignTx(tx, s.signer, s.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("交易签名失败: %w", err)
+	}
+	return signedTx, nil
+}
+
+// ExternalSigner 外部签名服务签名器（Clef 兼容接口，私钥不进入本进程）
+type ExternalSigner struct {
+	client  *external.ExternalSigner
+	account accounts.Account
+	chainID *big.Int
+}
+
+// NewExternalSigner 连接外部签名服务
+func NewExternalSigner(endpoint string, account common.Address, chainID *big.Int) (*ExternalSigner, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("外部签名服务地址不能为空")
+	}
+
+	client, err := external.NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("连接外部签名服务失败: %w", err)
+	}
+
+	// 未指定账户时使用签名服务的第一个账户
+	if account == (common.Address{}) {
+		available := client.Accounts()
+		if len(available) == 0 {
+			return nil, fmt.Errorf("外部签名服务没有可用账户")
+		}
+		account = available[0].Address
+	}
+
+	return &ExternalSigner{
+		client:  client,
+		account: accounts.Account{Address: account},
+		chainID: chainID,
+	}, nil
+}
+
+// Address 签名账户地址
+func (s *ExternalSigner) Address() common.Address {
+	return s.account.Address
+}
+
+// SignTx 请求外部签名服务对交易签名
+func (s *ExternalSigner) SignTx(tx *types.Transaction) (*types.Transaction, error) {
+	signedTx, err := s.client.SignTx(s.account, tx, s.chainID)
+	if err != nil {
+		return nil, fmt.Errorf("外部签名服务签名失败: %w", err)
+	}
+	return signedTx, nil
+}
+
+// AWSKMSSigner AWS KMS 签名器（开发中）
+// TODO: 接入 AWS SDK，使用 KMS 的 ECC_SECG_P256K1 密钥签名并转换为以太坊签名格式
+type AWSKMSSigner struct {
+	keyID   string
+	region  string
+	chainID *big.Int
+}
+
+// NewAWSKMSSigner 创建 AWS KMS 签名器
+func NewAWSKMSSigner(keyID, region string, chainID *big.Int) (*AWSKMSSigner, error) {
+	return nil, fmt.Errorf("%w: %s", ErrSignerNotImplemented, SignerTypeAWSKMS)
+}
+
+// Address 签名账户地址
+func (s *AWSKMSSigner) Address() common.Address {
+	return common.Address{}
+}
+
+// SignTx 对交易签名
+func (s *AWSKMSSigner) SignTx(tx *types.Transaction) (*types.Transaction, error) {
+	return nil, fmt.Errorf("%w: %s", ErrSignerNotImplemented, SignerTypeAWSKMS)
+}
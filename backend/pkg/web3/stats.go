@@ -0,0 +1,271 @@
+package web3
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// MethodStat 单个 RPC 方法的调用统计
+// eth_call 按合约方法名统计（如 getReserves、slot0、quoteExactInputSingle），其余按 JSON-RPC 方法名统计
+type MethodStat struct {
+	Method       string  `json:"method"`
+	Calls        uint64  `json:"calls"`
+	Errors       uint64  `json:"errors"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	MaxLatencyMs float64 `json:"max_latency_ms"`
+}
+
+// methodCounter 单个方法的累计数据
+type methodCounter struct {
+	calls        uint64
+	errors       uint64
+	totalLatency time.Duration
+	maxLatency   time.Duration
+}
+
+// rpcStats RPC 调用统计（并发安全）
+type rpcStats struct {
+	mu      sync.Mutex
+	methods map[string]*methodCounter
+}
+
+func newRPCStats() *rpcStats {
+	return &rpcStats{methods: make(map[string]*methodCounter)}
+}
+
+// record 记录一次调用
+func (s *rpcStats) record(method string, latency time.Duration, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counter, ok := s.methods[method]
+	if !ok {
+		counter = &methodCounter{}
+		s.methods[method] = counter
+	}
+	counter.calls++
+	if failed {
+		counter.errors++
+	}
+	counter.totalLatency += latency
+	if latency > counter.maxLatency {
+		counter.maxLatency = latency
+	}
+}
+
+// snapshot 导出统计快照（按调用次数降序）
+func (s *rpcStats) snapshot() []MethodStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]MethodStat, 0, len(s.methods))
+	for method, counter := range s.methods {
+		result = append(result, MethodStat{
+			Method:       method,
+			Calls:        counter.calls,
+			Errors:       counter.errors,
+			AvgLatencyMs: float64(counter.totalLatency.Microseconds()) / float64(counter.calls) / 1000,
+			MaxLatencyMs: float64(counter.maxLatency.Microseconds()) / 1000,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Calls != result[j].Calls {
+			return result[i].Calls > result[j].Calls
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
+
+// Stats 返回客户端创建以来各 RPC 方法的调用次数和延迟（只统计 HTTP(S) 节点）
+func (c *Client) Stats() []MethodStat {
+	if c.stats == nil {
+		return nil
+	}
+	return c.stats.snapshot()
+}
+
+// statsTransport 在传输层统计每个 JSON-RPC 请求
+// 和限流一样放在传输层，可以覆盖 bind 合约调用、直接 CallContract 和批量请求
+type statsTransport struct {
+	base  http.RoundTripper
+	stats *rpcStats
+}
+
+// jsonRPCRequest 只解析统计需要的字段
+type jsonRPCRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// jsonRPCResponse 只解析统计需要的字段
+type jsonRPCResponse struct {
+	ID    json.RawMessage `json:"id"`
+	Error json.RawMessage `json:"error"`
+}
+
+// RoundTrip 发送请求并按方法记录调用次数、延迟和错误
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	methods := t.requestMethods(req)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	latency := time.Since(start)
+
+	if err != nil || resp.StatusCode != http.StatusOK {
+		for _, call := range methods {
+			t.stats.record(call.name, latency, true)
+		}
+		return resp, err
+	}
+
+	// 读取响应，按 id 找出返回 error 的调用（如合约 revert）
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	failed := responseErrors(body)
+
+	for _, call := range methods {
+		t.stats.record(call.name, latency, readErr != nil || failed[string(call.id)])
+	}
+	return resp, nil
+}
+
+// rpcCall 请求中的单个调用
+type rpcCall struct {
+	id   json.RawMessage
+	name string
+}
+
+// requestMethods 解析请求体中的调用（单个请求或批量请求）
+func (t *statsTransport) requestMethods(req *http.Request) []rpcCall {
+	if req.Body == nil || req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil
+	}
+
+	var requests []jsonRPCRequest
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &requests); err != nil {
+			return nil
+		}
+	} else {
+		var single jsonRPCRequest
+		if err := json.Unmarshal(data, &single); err != nil {
+			return nil
+		}
+		requests = []jsonRPCRequest{single}
+	}
+
+	calls := make([]rpcCall, 0, len(requests))
+	for _, r := range requests {
+		calls = append(calls, rpcCall{id: r.ID, name: callName(r)})
+	}
+	return calls
+}
+
+// responseErrors 返回响应中带 error 的调用 id
+func responseErrors(body []byte) map[string]bool {
+	var responses []jsonRPCResponse
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &responses); err != nil {
+			return nil
+		}
+	} else {
+		var single jsonRPCResponse
+		if err := json.Unmarshal(body, &single); err != nil {
+			return nil
+		}
+		responses = []jsonRPCResponse{single}
+	}
+
+	failed := make(map[string]bool)
+	for _, r := range responses {
+		if len(r.Error) > 0 && string(r.Error) != "null" {
+			failed[string(r.ID)] = true
+		}
+	}
+	return failed
+}
+
+// callName 获取调用的统计名称：eth_call 解析函数选择器为合约方法名
+func callName(req jsonRPCRequest) string {
+	if req.Method != "eth_call" || len(req.Params) == 0 {
+		return req.Method
+	}
+
+	var msg struct {
+		Data  hexutil.Bytes `json:"data"`
+		Input hexutil.Bytes `json:"input"`
+	}
+	if err := json.Unmarshal(req.Params[0], &msg); err != nil {
+		return req.Method
+	}
+
+	data := msg.Input
+	if len(data) == 0 {
+		data = msg.Data
+	}
+	if len(data) < 4 {
+		return req.Method
+	}
+
+	if name, ok := knownSelectors()[string(data[:4])]; ok {
+		return name
+	}
+	return "eth_call:" + hexutil.Encode(data[:4])
+}
+
+var (
+	selectorsOnce sync.Once
+	selectors     map[string]string
+)
+
+// knownSelectors 从本包的合约 ABI 构建 函数选择器 → 方法名 的映射
+func knownSelectors() map[string]string {
+	selectorsOnce.Do(func() {
+		selectors = make(map[string]string)
+		abis := []string{
+			ERC20ABI,
+			UniswapV2FactoryABI, UniswapV2PairABI,
+			UniswapV3PoolABI, UniswapV3FactoryABI,
+			UniswapV4PoolManagerABI,
+			QuoterV1ABI, QuoterV2ABI, AlgebraQuoterABI,
+			KyberClassicFactoryABI, KyberClassicPoolABI,
+			KyberElasticFactoryABI, KyberElasticPoolABI,
+			FactoryViewABI,
+		}
+		for _, raw := range abis {
+			parsed, err := abi.JSON(strings.NewReader(raw))
+			if err != nil {
+				continue
+			}
+			for _, method := range parsed.Methods {
+				selectors[string(method.ID)] = method.RawName
+			}
+		}
+	})
+	return selectors
+}
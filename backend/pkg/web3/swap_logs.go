@@ -0,0 +1,103 @@
+package web3
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// V2 Swap(address indexed sender, uint amount0In, uint amount1In, uint amount0Out, uint amount1Out, address indexed to)
+	uniswapV2SwapTopic = crypto.Keccak256Hash([]byte("Swap(address,uint256,uint256,uint256,uint256,address)"))
+	// V3 Swap(address indexed sender, address indexed recipient, int256 amount0, int256 amount1, uint160 sqrtPriceX96, uint128 liquidity, int24 tick)
+	uniswapV3SwapTopic = crypto.Keccak256Hash([]byte("Swap(address,address,int256,int256,uint160,uint128,int24)"))
+	// Kyber Classic (DMM) Swap(address indexed sender, uint amount0In, uint amount1In, uint amount0Out, uint amount1Out, address indexed to, uint feeInPrecision)
+	// 比 V2 多一个 feeInPrecision，事件签名不同；Kyber Elastic 的 Swap 签名与 V3 相同
+	kyberClassicSwapTopic = crypto.Keccak256Hash([]byte("Swap(address,uint256,uint256,uint256,uint256,address,uint256)"))
+)
+
+// SwapLog 单笔 Swap 的成交量（两个代币方向的绝对值，链上最小单位）
+type SwapLog struct {
+	PairAddress string
+	BlockNumber uint64
+	Amount0     *big.Int
+	Amount1     *big.Int
+}
+
+// GetSwapLogs 获取一组池子在区块范围内的 Swap 事件（同时支持 V2、V3 和 Kyber Classic 事件格式）
+// 调用方需要自行控制区块范围，避免超过 RPC 节点的 eth_getLogs 限制
+func (c *Client) GetSwapLogs(pairAddresses []string, fromBlock, toBlock uint64) ([]SwapLog, error) {
+	addresses := make([]common.Address, len(pairAddresses))
+	for i, addr := range pairAddresses {
+		addresses[i] = common.HexToAddress(addr)
+	}
+
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: addresses,
+		Topics:    [][]common.Hash{{uniswapV2SwapTopic, uniswapV3SwapTopic, kyberClassicSwapTopic}},
+	}
+
+	ctx, cancel := c.callContext()
+	defer cancel()
+
+	logs, err := c.heavyEth().FilterLogs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("获取 Swap 事件失败 (区块 %d-%d): %w", fromBlock, toBlock, err)
+	}
+
+	swaps := make([]SwapLog, 0, len(logs))
+	for _, l := range logs {
+		if l.Removed || len(l.Topics) == 0 {
+			continue
+		}
+
+		swap := SwapLog{
+			PairAddress: l.Address.Hex(),
+			BlockNumber: l.BlockNumber,
+		}
+
+		switch l.Topics[0] {
+		case uniswapV2SwapTopic, kyberClassicSwapTopic:
+			// data: amount0In, amount1In, amount0Out, amount1Out（Kyber Classic 之后还有 feeInPrecision）
+			if len(l.Data) < 128 {
+				continue
+			}
+			amount0In := new(big.Int).SetBytes(l.Data[0:32])
+			amount1In := new(big.Int).SetBytes(l.Data[32:64])
+			amount0Out := new(big.Int).SetBytes(l.Data[64:96])
+			amount1Out := new(big.Int).SetBytes(l.Data[96:128])
+			swap.Amount0 = new(big.Int).Add(amount0In, amount0Out)
+			swap.Amount1 = new(big.Int).Add(amount1In, amount1Out)
+
+		case uniswapV3SwapTopic:
+			// data: amount0 (int256), amount1 (int256), sqrtPriceX96, liquidity, tick
+			if len(l.Data) < 64 {
+				continue
+			}
+			swap.Amount0 = absInt256(l.Data[0:32])
+			swap.Amount1 = absInt256(l.Data[32:64])
+
+		default:
+			continue
+		}
+
+		swaps = append(swaps, swap)
+	}
+
+	return swaps, nil
+}
+
+// absInt256 解析 ABI 编码的 int256 并取绝对值
+func absInt256(word []byte) *big.Int {
+	value := new(big.Int).SetBytes(word)
+	if len(word) == 32 && word[0]&0x80 != 0 {
+		// 补码转负数
+		value.Sub(value, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return value.Abs(value)
+}
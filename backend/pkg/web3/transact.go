@@ -0,0 +1,140 @@
+package web3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrNoSigner 客户端未配置签名器，不能发送交易
+var ErrNoSigner = errors.New("未配置交易签名器")
+
+// SetSigner 设置交易签名器（发送交易前必须设置）
+func (c *Client) SetSigner(signer Signer) {
+	c.signer = signer
+}
+
+// Signer 获取交易签名器（未设置时为 nil）
+func (c *Client) Signer() Signer {
+	return c.signer
+}
+
+// SuggestGasPrice 获取建议的 Gas 价格（Legacy）
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	gasPrice, err := c.eth().SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取 Gas 价格失败: %w", err)
+	}
+	return gasPrice, nil
+}
+
+// SuggestGasTipCap 获取建议的优先费（EIP-1559）
+func (c *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	tipCap, err := c.eth().SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取优先费失败: %w", err)
+	}
+	return tipCap, nil
+}
+
+// EstimateGas 估算交易的 Gas 消耗
+func (c *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	gas, err := c.eth().EstimateGas(ctx, msg)
+	if err != nil {
+		return 0, fmt.Errorf("估算 Gas 失败: %w", err)
+	}
+	return gas, nil
+}
+
+// EstimateGasForCall 估算合约调用的 Gas 消耗
+func (c *Client) EstimateGasForCall(ctx context.Context, from, to common.Address, value *big.Int, data []byte) (uint64, error) {
+	return c.EstimateGas(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: value,
+		Data:  data,
+	})
+}
+
+// PendingNonceAt 获取账户的下一个可用 nonce（包含待处理交易）
+func (c *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	nonce, err := c.eth().PendingNonceAt(ctx, account)
+	if err != nil {
+		return 0, fmt.Errorf("获取 nonce 失败: %w", err)
+	}
+	return nonce, nil
+}
+
+// CallContract 执行只读合约调用（blockNumber 为 nil 时使用最新区块）
+func (c *Client) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	result, err := c.eth().CallContract(ctx, msg, blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("合约调用失败: %w", err)
+	}
+	return result, nil
+}
+
+// SendTransaction 广播已签名的交易
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	if err := c.eth().SendTransaction(ctx, tx); err != nil {
+		return fmt.Errorf("发送交易 %s 失败: %w", tx.Hash().Hex(), err)
+	}
+	return nil
+}
+
+// TransactionReceipt 获取交易回执
+// 交易尚未上链时返回的错误包装了 ethereum.NotFound，可用 errors.Is 判断
+func (c *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	receipt, err := c.eth().TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易回执 %s 失败: %w", txHash.Hex(), err)
+	}
+	return receipt, nil
+}
+
+// GetTransactOpts 使用签名器构造交易选项
+// 私钥由 Signer 托管，这里只把签名委托给它；nonce / gas 留空时由 bind 自动填充
+func (c *Client) GetTransactOpts(ctx context.Context) (*bind.TransactOpts, error) {
+	if c.signer == nil {
+		return nil, ErrNoSigner
+	}
+
+	from := c.signer.Address()
+	return &bind.TransactOpts{
+		From:    from,
+		Context: ctx,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return c.signer.SignTx(tx)
+		},
+	}, nil
+}
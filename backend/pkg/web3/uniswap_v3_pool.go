@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"strings"
 
+	"github.com/defi-bot/backend/pkg/amm"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -47,6 +48,30 @@ const UniswapV3PoolABI = `[
 		"outputs": [{"name": "", "type": "address"}],
 		"stateMutability": "view",
 		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "tickSpacing",
+		"outputs": [{"name": "", "type": "int24"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "fee",
+		"outputs": [{"name": "", "type": "uint24"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"name": "secondsAgos", "type": "uint32[]"}],
+		"name": "observe",
+		"outputs": [
+			{"name": "tickCumulatives", "type": "int56[]"},
+			{"name": "secondsPerLiquidityCumulativeX128s", "type": "uint160[]"}
+		],
+		"stateMutability": "view",
+		"type": "function"
 	}
 ]`
 
@@ -82,11 +107,14 @@ func (c *Client) GetV3PoolSlot0(poolAddress string) (*V3Slot0, error) {
 	}
 
 	// 创建绑定
-	contract := bind.NewBoundContract(poolAddr, parsedABI, c.client, nil, nil)
+	contract := bind.NewBoundContract(poolAddr, parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
 
 	// 调用 slot0
 	var out []interface{}
-	err = contract.Call(nil, &out, "slot0")
+	err = contract.Call(opts, &out, "slot0")
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +136,46 @@ func (c *Client) GetV3PoolSlot0(poolAddress string) (*V3Slot0, error) {
 	}, nil
 }
 
+// GetV3TWAPTick 读取 V3 Pool 最近 secondsAgo 秒的时间加权平均 tick
+// 调用 observe([secondsAgo, 0])，池子的观察记录容量（observationCardinality）不足以覆盖该窗口时合约会 revert（"OLD"）
+func (c *Client) GetV3TWAPTick(poolAddress string, secondsAgo uint32) (int32, error) {
+	if secondsAgo == 0 {
+		return 0, amm.ErrInvalidPeriod
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(UniswapV3PoolABI))
+	if err != nil {
+		return 0, err
+	}
+
+	contract := bind.NewBoundContract(common.HexToAddress(poolAddress), parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	var out []interface{}
+	if err := contract.Call(opts, &out, "observe", []uint32{secondsAgo, 0}); err != nil {
+		return 0, fmt.Errorf("调用 %s.observe 失败: %w", poolAddress, err)
+	}
+
+	tickCumulatives, ok := out[0].([]*big.Int)
+	if !ok || len(tickCumulatives) != 2 {
+		return 0, fmt.Errorf("unexpected observe result: %T", out[0])
+	}
+
+	return amm.MeanTick(tickCumulatives[0], tickCumulatives[1], secondsAgo)
+}
+
+// GetV3TWAP 读取 V3 Pool 最近 secondsAgo 秒的 TWAP，返回平均 tick 对应的 sqrtPriceX96
+// 现货 sqrtPriceX96 可以在单个区块内被操纵，TWAP 用于对照：两者偏离过大（amm.SqrtPriceDivergence）说明价格可能被操纵
+func (c *Client) GetV3TWAP(poolAddress string, secondsAgo uint32) (*big.Int, error) {
+	tick, err := c.GetV3TWAPTick(poolAddress, secondsAgo)
+	if err != nil {
+		return nil, err
+	}
+	return amm.GetSqrtRatioAtTick(tick)
+}
+
 // GetV3PoolLiquidity 获取 V3 Pool 的流动性
 func (c *Client) GetV3PoolLiquidity(poolAddress string) (*big.Int, error) {
 	poolAddr := common.HexToAddress(poolAddress)
@@ -119,11 +187,14 @@ func (c *Client) GetV3PoolLiquidity(poolAddress string) (*big.Int, error) {
 	}
 
 	// 创建绑定
-	contract := bind.NewBoundContract(poolAddr, parsedABI, c.client, nil, nil)
+	contract := bind.NewBoundContract(poolAddr, parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
 
 	// 调用 liquidity
 	var out []interface{}
-	err = contract.Call(nil, &out, "liquidity")
+	err = contract.Call(opts, &out, "liquidity")
 	if err != nil {
 		return nil, err
 	}
@@ -144,11 +215,14 @@ func (c *Client) GetV3Pool(factoryAddress, token0, token1 string, fee uint32) (s
 	}
 
 	// 创建绑定
-	contract := bind.NewBoundContract(factoryAddr, parsedABI, c.client, nil, nil)
+	contract := bind.NewBoundContract(factoryAddr, parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
 
 	// 调用 getPool
 	var out []interface{}
-	err = contract.Call(nil, &out, "getPool", token0Addr, token1Addr, big.NewInt(int64(fee)))
+	err = contract.Call(opts, &out, "getPool", token0Addr, token1Addr, big.NewInt(int64(fee)))
 	if err != nil {
 		return "", err
 	}
@@ -163,6 +237,48 @@ func (c *Client) GetV3Pool(factoryAddress, token0, token1 string, fee uint32) (s
 	return poolAddr.Hex(), nil
 }
 
+// GetV3PoolTickSpacing 获取 V3 Pool 的 tick 间距
+func (c *Client) GetV3PoolTickSpacing(poolAddress string) (int32, error) {
+	poolAddr := common.HexToAddress(poolAddress)
+
+	// 解析 ABI
+	parsedABI, err := abi.JSON(strings.NewReader(UniswapV3PoolABI))
+	if err != nil {
+		return 0, err
+	}
+
+	// 创建绑定
+	contract := bind.NewBoundContract(poolAddr, parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	// 调用 tickSpacing
+	var out []interface{}
+	err = contract.Call(opts, &out, "tickSpacing")
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(out[0].(*big.Int).Int64()), nil
+}
+
+// DefaultTickSpacing 返回 Uniswap V3 标准费率层级对应的 tick 间距（未知费率返回 0）
+func DefaultTickSpacing(fee uint32) int32 {
+	switch fee {
+	case 100:
+		return 1
+	case 500:
+		return 10
+	case 3000:
+		return 60
+	case 10000:
+		return 200
+	default:
+		return 0
+	}
+}
+
 // GetV3PoolTokens 获取 V3 Pool 的代币地址
 func (c *Client) GetV3PoolTokens(poolAddress string) (token0, token1 string, err error) {
 	poolAddr := common.HexToAddress(poolAddress)
@@ -174,18 +290,21 @@ func (c *Client) GetV3PoolTokens(poolAddress string) (token0, token1 string, err
 	}
 
 	// 创建绑定
-	contract := bind.NewBoundContract(poolAddr, parsedABI, c.client, nil, nil)
+	contract := bind.NewBoundContract(poolAddr, parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
 
 	// 调用 token0
 	var out0 []interface{}
-	err = contract.Call(nil, &out0, "token0")
+	err = contract.Call(opts, &out0, "token0")
 	if err != nil {
 		return "", "", err
 	}
 
 	// 调用 token1
 	var out1 []interface{}
-	err = contract.Call(nil, &out1, "token1")
+	err = contract.Call(opts, &out1, "token1")
 	if err != nil {
 		return "", "", err
 	}
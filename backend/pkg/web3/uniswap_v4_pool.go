@@ -0,0 +1,161 @@
+package web3
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Uniswap V4 PoolManager ABI（只需要 extsload 读取存储槽）
+const UniswapV4PoolManagerABI = `[
+	{
+		"inputs": [{"name": "slot", "type": "bytes32"}],
+		"name": "extsload",
+		"outputs": [{"name": "", "type": "bytes32"}],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// V4 PoolManager 存储布局（参考 v4-core StateLibrary）
+const (
+	v4PoolsSlot       = 6 // PoolManager._pools mapping 所在槽位
+	v4LiquidityOffset = 3 // Pool.State 中 liquidity 相对 slot0 的偏移
+)
+
+// V4PoolKey V4 池子的标识（所有池子都在 PoolManager 单例中，由 PoolKey 的哈希区分）
+type V4PoolKey struct {
+	Currency0   common.Address // 地址较小的代币（原生 ETH 为零地址）
+	Currency1   common.Address // 地址较大的代币
+	Fee         uint32         // 费率（uint24）
+	TickSpacing int32          // tick 间距（int24）
+	Hooks       common.Address // hooks 合约地址（无 hooks 时为零地址）
+}
+
+// NewV4PoolKey 创建 PoolKey，代币按地址排序
+func NewV4PoolKey(tokenA, tokenB string, fee uint32, tickSpacing int32, hooks string) V4PoolKey {
+	currency0 := common.HexToAddress(tokenA)
+	currency1 := common.HexToAddress(tokenB)
+	if bytes.Compare(currency0.Bytes(), currency1.Bytes()) > 0 {
+		currency0, currency1 = currency1, currency0
+	}
+
+	return V4PoolKey{
+		Currency0:   currency0,
+		Currency1:   currency1,
+		Fee:         fee,
+		TickSpacing: tickSpacing,
+		Hooks:       common.HexToAddress(hooks),
+	}
+}
+
+// ID 计算 poolId = keccak256(abi.encode(poolKey))
+func (k V4PoolKey) ID() [32]byte {
+	encoded := make([]byte, 0, 32*5)
+	encoded = append(encoded, common.LeftPadBytes(k.Currency0.Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(k.Currency1.Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(new(big.Int).SetUint64(uint64(k.Fee)).Bytes(), 32)...)
+
+	// int24 按 int256 补码编码（负数高位补 0xff）
+	tickSpacing := twosComplement256(big.NewInt(int64(k.TickSpacing)))
+	encoded = append(encoded, common.LeftPadBytes(tickSpacing.Bytes(), 32)...)
+	encoded = append(encoded, common.LeftPadBytes(k.Hooks.Bytes(), 32)...)
+
+	return crypto.Keccak256Hash(encoded)
+}
+
+// twosComplement256 将有符号整数转换为 256 位补码表示
+func twosComplement256(v *big.Int) *big.Int {
+	if v.Sign() >= 0 {
+		return v
+	}
+	return new(big.Int).Add(v, new(big.Int).Lsh(big.NewInt(1), 256))
+}
+
+// V4Slot0 V4 池子的 slot0 数据
+type V4Slot0 struct {
+	SqrtPriceX96 *big.Int
+	Tick         int32
+	ProtocolFee  uint32
+	LPFee        uint32
+}
+
+// GetV4Slot0 通过 PoolManager.extsload 读取 V4 池子的 slot0
+// slot0 打包在一个存储槽中：sqrtPriceX96(160) | tick(24) | protocolFee(24) | lpFee(24)
+func (c *Client) GetV4Slot0(poolManager string, poolId [32]byte) (*V4Slot0, error) {
+	data, err := c.extsload(poolManager, v4PoolStateSlot(poolId))
+	if err != nil {
+		return nil, err
+	}
+
+	word := new(big.Int).SetBytes(data[:])
+	mask24 := big.NewInt(1<<24 - 1)
+
+	sqrtPriceX96 := new(big.Int).And(word, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1)))
+	rawTick := new(big.Int).And(new(big.Int).Rsh(word, 160), mask24).Int64()
+	protocolFee := new(big.Int).And(new(big.Int).Rsh(word, 184), mask24).Uint64()
+	lpFee := new(big.Int).And(new(big.Int).Rsh(word, 208), mask24).Uint64()
+
+	// int24 符号扩展
+	if rawTick >= 1<<23 {
+		rawTick -= 1 << 24
+	}
+
+	return &V4Slot0{
+		SqrtPriceX96: sqrtPriceX96,
+		Tick:         int32(rawTick),
+		ProtocolFee:  uint32(protocolFee),
+		LPFee:        uint32(lpFee),
+	}, nil
+}
+
+// GetV4Liquidity 通过 PoolManager.extsload 读取 V4 池子的流动性
+func (c *Client) GetV4Liquidity(poolManager string, poolId [32]byte) (*big.Int, error) {
+	stateSlot := new(big.Int).SetBytes(v4PoolStateSlot(poolId).Bytes())
+	liquiditySlot := common.BigToHash(new(big.Int).Add(stateSlot, big.NewInt(v4LiquidityOffset)))
+
+	data, err := c.extsload(poolManager, liquiditySlot)
+	if err != nil {
+		return nil, err
+	}
+
+	// liquidity 为 uint128，位于槽的低 128 位
+	return new(big.Int).SetBytes(data[16:]), nil
+}
+
+// v4PoolStateSlot 计算池子状态的存储槽：keccak256(abi.encodePacked(poolId, POOLS_SLOT))
+func v4PoolStateSlot(poolId [32]byte) common.Hash {
+	return crypto.Keccak256Hash(poolId[:], common.BigToHash(big.NewInt(v4PoolsSlot)).Bytes())
+}
+
+// extsload 读取 PoolManager 的存储槽
+func (c *Client) extsload(poolManager string, slot common.Hash) ([32]byte, error) {
+	managerAddr := common.HexToAddress(poolManager)
+
+	// 解析 ABI
+	parsedABI, err := abi.JSON(strings.NewReader(UniswapV4PoolManagerABI))
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	// 创建绑定
+	contract := bind.NewBoundContract(managerAddr, parsedABI, c.eth(), nil, nil)
+
+	opts, cancel := c.callOpts()
+	defer cancel()
+
+	// 调用 extsload
+	var out []interface{}
+	err = contract.Call(opts, &out, "extsload", [32]byte(slot))
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("extsload 调用失败: %w", err)
+	}
+
+	return out[0].([32]byte), nil
+}